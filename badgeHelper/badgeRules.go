@@ -0,0 +1,239 @@
+// Package badgeHelper contains the rules of the German sports badge (Deutsches Sportabzeichen).
+// A badge is earned with at least a bronze medal in each of the four disciplines and a valid swim certificate within a year.
+// The level of the badge depends on the sum of the medal points of the disciplines (bronze 1, silver 2, gold 3).
+package badgeHelper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	GoldLevel   = "gold"
+	SilverLevel = "silver"
+	BronzeLevel = "bronze"
+)
+
+// Disciplines are the disciplines in which a medal is required for the badge
+var Disciplines = []string{"Ausdauer", "Kraft", "Schnelligkeit", "Koordination"}
+
+// Minimum sum of the medal points of the disciplines for each level
+const (
+	goldPoints   = 11
+	silverPoints = 8
+	bronzePoints = 4
+)
+
+const (
+	adultAge                     = 18 // Age from which the swim certificate expires
+	swimCertificateValidityYears = 5  // Years in which the swim certificate of an adult is valid
+)
+
+// Result is the badge evaluation of an athlete for a year
+type Result struct {
+	Level                  string            // Level of the badge, empty if it has not been earned
+	Points                 int               // Sum of the medal points of the disciplines
+	Medals                 map[string]string // Best medal per discipline
+	MissingDisciplines     []string          // Disciplines without a medal
+	MissingSwimCertificate bool
+}
+
+// Earned checks if the badge has been earned
+func (result Result) Earned() bool {
+	return result.Level != ""
+}
+
+// MedalPoints returns the points of a medal (gold 3, silver 2, bronze 1, otherwise 0)
+func MedalPoints(medal string) int {
+	switch medal {
+	case GoldLevel:
+		return 3
+	case SilverLevel:
+		return 2
+	case BronzeLevel:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// MedalOfPoints returns the medal of the given points (3 gold, 2 silver, 1 bronze, otherwise none)
+func MedalOfPoints(points int) string {
+	switch points {
+	case 3:
+		return GoldLevel
+	case 2:
+		return SilverLevel
+	case 1:
+		return BronzeLevel
+	default:
+		return ""
+	}
+}
+
+// LevelOfPoints returns the badge level of the sum of the medal points of all disciplines
+func LevelOfPoints(points int) string {
+	switch {
+	case points >= goldPoints:
+		return GoldLevel
+	case points >= silverPoints:
+		return SilverLevel
+	case points >= bronzePoints:
+		return BronzeLevel
+	default:
+		return ""
+	}
+}
+
+// Evaluate checks if the badge has been earned with the best medals per discipline and the swim certificate
+func Evaluate(medals map[string]string, hasSwimCertificate bool) Result {
+	result := Result{
+		Medals:                 make(map[string]string, len(Disciplines)),
+		MissingSwimCertificate: !hasSwimCertificate,
+	}
+	for _, discipline := range Disciplines {
+		points := MedalPoints(medals[discipline])
+		if points == 0 {
+			result.MissingDisciplines = append(result.MissingDisciplines, discipline)
+			continue
+		}
+		result.Medals[discipline] = medals[discipline]
+		result.Points += points
+	}
+
+	if len(result.MissingDisciplines) == 0 && hasSwimCertificate {
+		result.Level = LevelOfPoints(result.Points)
+	}
+	return result
+}
+
+// AgeInYear returns the age an athlete reaches in the given year, which decides the age class of the badge
+func AgeInYear(birthDate string, year int) (int, error) {
+	if len(birthDate) < 10 {
+		return -1, errors.New("Invalid birth date: " + birthDate)
+	}
+	birthDay, err := time.Parse(time.DateOnly, birthDate[:10])
+	if err != nil {
+		return -1, errors.Wrap(err, "Failed to parse the birth date")
+	}
+	return year - birthDay.Year(), nil
+}
+
+// AgeOnDate returns the age that decides the age class of a performance on the given date (YYYY-MM-DD), see AgeInYear
+func AgeOnDate(birthDate string, date string) (int, error) {
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return -1, errors.Wrap(err, "Failed to parse the date")
+	}
+	return AgeInYear(birthDate, day.Year())
+}
+
+// IsSwimCertificateValid checks if a swim certificate of the given date is valid for the badge of the given year.
+// Children and teenagers only need a certificate once, for adults it is valid for five years.
+func IsSwimCertificateValid(certificateDate time.Time, birthDate string, year int) bool {
+	if certificateDate.Year() > year {
+		return false
+	}
+	age, err := AgeInYear(birthDate, year)
+	if err != nil {
+		return false
+	}
+	return age < adultAge || year-certificateDate.Year() < swimCertificateValidityYears
+}
+
+// SwimCertificateExpiryYear returns the first year for whose badge a swim certificate of the given date is no longer valid.
+// A certificate of a minor stays valid until the athlete is an adult and the five years are over.
+func SwimCertificateExpiryYear(certificateDate time.Time, birthDate string) (int, error) {
+	age, err := AgeInYear(birthDate, certificateDate.Year())
+	if err != nil {
+		return -1, err
+	}
+	birthYear := certificateDate.Year() - age
+	return max(birthYear+adultAge, certificateDate.Year()+swimCertificateValidityYears), nil
+}
+
+// AgeBand is an age class of the badge
+type AgeBand struct {
+	FromAge uint
+	ToAge   uint
+}
+
+const (
+	openEndAge      = 150 // Upper age of the last standard age class
+	openEndLabelAge = 99  // Upper ages from which the age class is labeled as open, e.g. 90+
+)
+
+// StandardAgeBands are the official age classes, used if the rulesets of a year do not define any
+var StandardAgeBands = func() []AgeBand {
+	bands := []AgeBand{{6, 7}, {8, 9}, {10, 11}, {12, 13}, {14, 15}, {16, 17}, {18, 19}}
+	for fromAge := uint(20); fromAge < 90; fromAge += 5 {
+		bands = append(bands, AgeBand{fromAge, fromAge + 4})
+	}
+	return append(bands, AgeBand{90, openEndAge})
+}()
+
+// Label returns the age class as text, e.g. 10-11 or 90+
+func (band AgeBand) Label() string {
+	if band.ToAge >= openEndLabelAge {
+		return fmt.Sprintf("%d+", band.FromAge)
+	}
+	return fmt.Sprintf("%d-%d", band.FromAge, band.ToAge)
+}
+
+// Contains checks if the age is in the age class
+func (band AgeBand) Contains(age int) bool {
+	return age >= 0 && uint(age) >= band.FromAge && uint(age) <= band.ToAge
+}
+
+// Categories of the badge, the federation issues different badges and certificates for each of them
+const (
+	ChildrenCategory = "children" // Up to 11 years (Kinder)
+	YouthCategory    = "youth"    // 12 to 17 years (Jugendliche)
+	AdultsCategory   = "adults"   // From 18 years (Erwachsene)
+)
+
+// Categories are the badge categories in ascending age
+var Categories = []string{ChildrenCategory, YouthCategory, AdultsCategory}
+
+const youthAge = 12 // Age from which the youth badge is issued
+
+// CategoryOfAge returns the badge category of the age an athlete reaches in the year
+func CategoryOfAge(age int) string {
+	switch {
+	case age >= adultAge:
+		return AdultsCategory
+	case age >= youthAge:
+		return YouthCategory
+	default:
+		return ChildrenCategory
+	}
+}
+
+const anniversaryInterval = 5 // Every fifth badge is an anniversary badge
+
+// IsAnniversary checks if the repeat count of a badge is an anniversary number (5th, 10th, 15th, ... badge)
+func IsAnniversary(repeatCount int) bool {
+	return repeatCount > 0 && repeatCount%anniversaryInterval == 0
+}
+
+// IsLevel checks if the level is bronze, silver or gold
+func IsLevel(level string) bool {
+	return level == BronzeLevel || level == SilverLevel || level == GoldLevel
+}
+
+// NextLevel returns the next higher level of the sum of the medal points and the points that are missing for it.
+// Returns an empty level for the points of a gold badge.
+func NextLevel(points int) (string, int) {
+	switch {
+	case points < bronzePoints:
+		return BronzeLevel, bronzePoints - points
+	case points < silverPoints:
+		return SilverLevel, silverPoints - points
+	case points < goldPoints:
+		return GoldLevel, goldPoints - points
+	default:
+		return "", 0
+	}
+}
@@ -0,0 +1,185 @@
+package csvHelper
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// maxSampleLines is the number of lines used to detect the delimiter
+const maxSampleLines = 10
+
+var (
+	EmptyFileError  = errors.New("The file is empty")
+	InvalidCsvError = errors.New("The file is not a valid csv file")
+
+	// possibleDelimiters in the order of their priority if several delimiters fit equally well
+	possibleDelimiters = []rune{';', ',', '\t', '|'}
+)
+
+// Options configure how a csv file is read
+type Options struct {
+	Delimiter    rune     // Delimiter of the columns, zero detects the delimiter
+	DetectHeader bool     // Removes the first row if it is detected as header
+	HeaderNames  []string // Known column names, which improve the header detection
+	Sheet        string   // Name of the sheet of xlsx workbooks, empty selects the first sheet
+}
+
+// File is a decoded csv file
+type File struct {
+	Records   [][]string
+	Header    []string // Removed header row, nil if no header was detected
+	Format    string   // CsvFormat or XlsxFormat
+	Encoding  string
+	Delimiter rune
+}
+
+// Read decodes the csv file (UTF-8 with or without BOM, Windows-1252 or ISO-8859-1), detects the delimiter and,
+// if requested, the header row. The rows can have different numbers of columns, so that they can be validated one by one.
+// Throws: EmptyFileError, InvalidCsvError and other
+func Read(reader io.Reader, options Options) (*File, error) {
+	content, err1 := io.ReadAll(reader)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to read the file")
+		return nil, err1
+	}
+
+	text, encoding, err2 := decode(content)
+	if err2 != nil {
+		return nil, err2
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, EmptyFileError
+	}
+
+	delimiter := options.Delimiter
+	if delimiter == 0 {
+		delimiter = detectDelimiter(text)
+	}
+
+	csvReader := csv.NewReader(strings.NewReader(text))
+	csvReader.Comma = delimiter
+	csvReader.FieldsPerRecord = -1
+	records, err3 := csvReader.ReadAll()
+	if err3 != nil {
+		err3 = errors.Wrap(InvalidCsvError, err3.Error())
+		return nil, err3
+	}
+	if len(records) == 0 {
+		return nil, EmptyFileError
+	}
+
+	file := File{
+		Records:   records,
+		Format:    CsvFormat,
+		Encoding:  encoding,
+		Delimiter: delimiter,
+	}
+	removeHeader(&file, options)
+
+	return &file, nil
+}
+
+// removeHeader moves the first row to the header if it is detected as header
+func removeHeader(file *File, options Options) {
+	if options.DetectHeader && isHeader(file.Records, options.HeaderNames) {
+		file.Header = file.Records[0]
+		file.Records = file.Records[1:]
+	}
+}
+
+// detectDelimiter returns the delimiter that splits the first lines into the same number of columns most consistently
+func detectDelimiter(text string) rune {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+		if len(lines) == maxSampleLines {
+			break
+		}
+	}
+
+	bestDelimiter, bestConsistency, bestCount := possibleDelimiters[0], 0, 0
+	for _, delimiter := range possibleDelimiters {
+		count := countDelimiter(lines[0], delimiter)
+		if count == 0 {
+			continue
+		}
+
+		consistency := 0
+		for _, line := range lines {
+			if countDelimiter(line, delimiter) == count {
+				consistency++
+			}
+		}
+
+		if consistency > bestConsistency || (consistency == bestConsistency && count > bestCount) {
+			bestDelimiter, bestConsistency, bestCount = delimiter, consistency, count
+		}
+	}
+
+	return bestDelimiter
+}
+
+// countDelimiter counts the delimiters of the line outside of quoted fields
+func countDelimiter(line string, delimiter rune) int {
+	count := 0
+	quoted := false
+	for _, char := range line {
+		if char == '"' {
+			quoted = !quoted
+		} else if char == delimiter && !quoted {
+			count++
+		}
+	}
+	return count
+}
+
+// isHeader checks if the first row contains known column names or
+// if it contains no digits while the following row does (e.g. dates or results)
+func isHeader(records [][]string, headerNames []string) bool {
+	firstRow := records[0]
+
+	knownNames := make([]string, len(headerNames))
+	for idx, name := range headerNames {
+		knownNames[idx] = normalizeColumnName(name)
+	}
+	matches, cells := 0, 0
+	for _, cell := range firstRow {
+		name := normalizeColumnName(cell)
+		if name == "" {
+			continue
+		}
+		cells++
+		if slices.Contains(knownNames, name) {
+			matches++
+		}
+	}
+	if matches > 0 && matches*2 >= cells {
+		return true
+	}
+
+	if len(records) < 2 || slices.ContainsFunc(firstRow, containsDigit) {
+		return false
+	}
+	return slices.ContainsFunc(records[1], containsDigit)
+}
+
+// normalizeColumnName lowercases the name and removes spaces, underscores and hyphens
+func normalizeColumnName(name string) string {
+	return strings.Map(func(char rune) rune {
+		if unicode.IsSpace(char) || char == '_' || char == '-' {
+			return -1
+		}
+		return unicode.ToLower(char)
+	}, name)
+}
+
+func containsDigit(value string) bool {
+	return strings.ContainsFunc(value, unicode.IsDigit)
+}
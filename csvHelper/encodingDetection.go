@@ -0,0 +1,58 @@
+package csvHelper
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	UTF8Encoding        = "UTF-8"
+	UTF8BOMEncoding     = "UTF-8-BOM"
+	Windows1252Encoding = "Windows-1252"
+	ISO88591Encoding    = "ISO-8859-1"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decode detects the encoding of the content and converts it to UTF-8.
+// Content that is not valid UTF-8 is treated as Windows-1252 if it uses the printable characters of the
+// range 0x80-0x9F (e.g. € or „), and as ISO-8859-1 otherwise.
+func decode(content []byte) (string, string, error) {
+	if bytes.HasPrefix(content, utf8BOM) {
+		return string(content[len(utf8BOM):]), UTF8BOMEncoding, nil
+	}
+	if utf8.Valid(content) {
+		return string(content), UTF8Encoding, nil
+	}
+
+	encoding, decoder := ISO88591Encoding, charmap.ISO8859_1.NewDecoder()
+	if usesWindows1252Characters(content) {
+		encoding, decoder = Windows1252Encoding, charmap.Windows1252.NewDecoder()
+	}
+
+	decoded, err := decoder.Bytes(content)
+	if err != nil {
+		err = errors.Wrap(err, "Failed to decode the content as "+encoding)
+		return "", "", err
+	}
+	return string(decoded), encoding, nil
+}
+
+// usesWindows1252Characters checks if the content contains bytes that are printable characters in Windows-1252,
+// but control characters in ISO-8859-1
+func usesWindows1252Characters(content []byte) bool {
+	for _, b := range content {
+		if b < 0x80 || b > 0x9F {
+			continue
+		}
+
+		// These bytes are undefined in Windows-1252
+		if b != 0x81 && b != 0x8D && b != 0x8F && b != 0x90 && b != 0x9D {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package csvHelper
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"github.com/Team-Reissdorf/Backend/xlsxHelper"
+	"github.com/pkg/errors"
+)
+
+const (
+	CsvFormat  = "csv"
+	XlsxFormat = "xlsx"
+)
+
+var UnsupportedFileTypeError = errors.New("Invalid file type, only CSV and XLSX files are allowed")
+
+// zipSignature is the start of every zip archive and therefore of every xlsx workbook
+var zipSignature = []byte("PK\x03\x04")
+
+// CheckFileType checks if the uploaded file is a csv file or a xlsx workbook by its MIME type or extension
+// Throws: UnsupportedFileTypeError
+func CheckFileType(fileHeader *multipart.FileHeader) error {
+	contentType := fileHeader.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "text/csv") ||
+		strings.HasPrefix(contentType, "application/vnd.ms-excel") ||
+		strings.HasPrefix(contentType, xlsxHelper.ContentType) {
+		return nil
+	}
+
+	extension := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	if extension == ".csv" || extension == ".xlsx" {
+		return nil
+	}
+	return UnsupportedFileTypeError
+}
+
+// ReadUpload reads the uploaded csv file or the selected sheet of the uploaded xlsx workbook.
+// Workbooks are recognized by their content, so that the MIME type sent by the browser does not matter.
+// Throws: EmptyFileError, InvalidCsvError, xlsxHelper.InvalidWorkbookError, xlsxHelper.SheetNotFoundError and other
+func ReadUpload(fileHeader *multipart.FileHeader, options Options) (*File, error) {
+	fileContent, err1 := fileHeader.Open()
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to open the file")
+		return nil, err1
+	}
+	defer func(fileContent multipart.File) {
+		_ = fileContent.Close()
+	}(fileContent)
+
+	signature := make([]byte, len(zipSignature))
+	if _, err2 := fileContent.ReadAt(signature, 0); err2 != nil && !errors.Is(err2, io.EOF) {
+		err2 = errors.Wrap(err2, "Failed to read the file")
+		return nil, err2
+	}
+	if !bytes.Equal(signature, zipSignature) {
+		return Read(fileContent, options)
+	}
+
+	records, err3 := xlsxHelper.ReadSheet(fileContent, fileHeader.Size, options.Sheet)
+	if err3 != nil {
+		return nil, err3
+	}
+	if len(records) == 0 {
+		return nil, EmptyFileError
+	}
+
+	file := File{
+		Records: records,
+		Format:  XlsxFormat,
+	}
+	removeHeader(&file, options)
+
+	return &file, nil
+}
@@ -10,13 +10,15 @@ type Athlete struct {
 	UpdatedAt time.Time
 	DeletedAt *time.Time `gorm:"index"`
 
-	FirstName string `json:"first_name" gorm:"uniqueIndex:unique_combination_athletes"`
-	LastName  string `json:"last_name"`
-	BirthDate string `json:"birth_date" gorm:"type:date;uniqueIndex:unique_combination_athletes"`
+	FirstName string `json:"first_name" gorm:"uniqueIndex:unique_athlete_per_trainer"`
+	LastName  string `json:"last_name" gorm:"uniqueIndex:unique_athlete_per_trainer"`
+	BirthDate string `json:"birth_date" gorm:"type:date;uniqueIndex:unique_athlete_per_trainer"`
 	Sex       string `json:"sex" gorm:"type:char(1);not null"`
-	Email     string `json:"email" gorm:"uniqueIndex:unique_combination_athletes"`
+	Email     string `json:"email"` // Optional, since most children do not have their own address
 
-	TrainerEmail string `json:"trainer_email" gorm:"index"`
+	AnonymizedAt *time.Time `json:"-"` // Set once all personal data got removed and only birth year and sex are left
+
+	TrainerEmail string `json:"trainer_email" gorm:"index;uniqueIndex:unique_athlete_per_trainer"`
 	// BelongsTo Trainer (FK: TrainerEmail -> Trainer.Email)
 	Trainer Trainer `json:"-" gorm:"foreignKey:TrainerEmail;references:Email;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
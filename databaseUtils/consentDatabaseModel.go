@@ -0,0 +1,22 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+type Consent struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	Type      string     `json:"type"`  // How the consent was given: written, digital or verbal
+	Scope     string     `json:"scope"` // What the consent covers, e.g. store_documents or publish_results
+	GivenBy   string     `json:"given_by"`
+	GivenAt   string     `json:"given_at" gorm:"type:date"`
+	RevokedAt *time.Time `json:"revoked_at"`
+
+	AthleteId uint `gorm:"index"`
+	// BelongsTo Athlete (FK: AthleteId -> Athlete.Id)
+	Athlete Athlete `json:"-" gorm:"foreignKey:AthleteId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
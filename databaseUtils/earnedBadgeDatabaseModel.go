@@ -0,0 +1,22 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+// EarnedBadge records a badge that an athlete earned with performances. It is kept when the swim certificate
+// that the badge was earned with is deleted, e.g. by the retention policies or the anonymization.
+type EarnedBadge struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	Year   int    `json:"year" gorm:"uniqueIndex:unique_earned_badge_per_year"`
+	Level  string `json:"level"` // bronze, silver or gold
+	Points int    `json:"points"`
+
+	AthleteId uint `gorm:"uniqueIndex:unique_earned_badge_per_year"`
+	// BelongsTo Athlete (FK: AthleteId -> Athlete.Id)
+	Athlete Athlete `json:"-" gorm:"foreignKey:AthleteId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,21 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+type Guardian struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	Name     string `json:"name" gorm:"not null"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Relation string `json:"relation"` // e.g. mother, father, legal guardian
+
+	AthleteId uint `gorm:"index"`
+	// BelongsTo Athlete (FK: AthleteId -> Athlete.Id)
+	Athlete Athlete `json:"-" gorm:"foreignKey:AthleteId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
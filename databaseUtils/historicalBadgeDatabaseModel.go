@@ -0,0 +1,21 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+// HistoricalBadge is a badge that was earned without performances in the system, e.g. before it was used
+type HistoricalBadge struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	Year  int    `json:"year" gorm:"uniqueIndex:unique_historical_badge_per_year"`
+	Level string `json:"level"` // bronze, silver, gold or empty if unknown
+	Note  string `json:"note"`
+
+	AthleteId uint `gorm:"uniqueIndex:unique_historical_badge_per_year"`
+	// BelongsTo Athlete (FK: AthleteId -> Athlete.Id)
+	Athlete Athlete `json:"-" gorm:"foreignKey:AthleteId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
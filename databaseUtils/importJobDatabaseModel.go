@@ -0,0 +1,54 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+type ImportJob struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	Type            string     `json:"type" gorm:"not null"` // athletes, performances or rulesets
+	FileName        string     `json:"file_name"`
+	Status          string     `json:"status" gorm:"index;not null"`
+	TotalRows       int        `json:"total_rows"`
+	ProcessedRows   int        `json:"processed_rows"`
+	CreatedRows     int        `json:"created_rows"`
+	SkippedRows     int        `json:"skipped_rows"`
+	FailedRows      int        `json:"failed_rows"`
+	ValidRows       int        `json:"valid_rows"`
+	CancelRequested bool       `json:"cancel_requested"`
+	Error           string     `json:"error"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+
+	// A dry run only validates the rows. Its records are kept, so that the preview can be committed later.
+	DryRun         bool       `json:"dry_run"`
+	Records        string     `json:"-" gorm:"type:text"` // JSON encoded records of a dry run
+	CommittedAt    *time.Time `json:"committed_at"`
+	CommittedJobId *uint      `json:"committed_job_id"` // Import job that committed the preview
+
+	TrainerEmail string `json:"trainer_email" gorm:"index"`
+	// BelongsTo Trainer (FK: TrainerEmail -> Trainer.Email)
+	Trainer Trainer `json:"-" gorm:"foreignKey:TrainerEmail;references:Email;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type ImportJobRow struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	Row      int    `json:"row"`
+	Status   string `json:"status" gorm:"not null"`
+	Reason   string `json:"reason"`
+	EntityId uint   `json:"entity_id"`
+	Note     string `json:"note"`                     // Side effects of creating the entry (e.g. a created athlete)
+	Preview  string `json:"preview" gorm:"type:text"` // JSON encoded entry that a dry run would create
+
+	ImportJobId uint `gorm:"index"`
+	// BelongsTo ImportJob (FK: ImportJobId -> ImportJob.Id)
+	ImportJob ImportJob `json:"-" gorm:"foreignKey:ImportJobId;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,24 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+type ImportProfile struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	Name       string            `json:"name" gorm:"not null;uniqueIndex:unique_combination_import_profile"`
+	Type       string            `json:"type" gorm:"not null"` // Import type whose upload accepts profiles: athletes or performances
+	Delimiter  string            `json:"delimiter" gorm:"not null"`
+	SkipRows   int               `json:"skip_rows"`                         // Rows before the data, the last one is used as header
+	DateFormat string            `json:"date_format"`                       // e.g. DD.MM.YYYY
+	Columns    map[string]string `json:"columns" gorm:"serializer:json"`    // Field -> column name or 1-based column index
+	SexValues  map[string]string `json:"sex_values" gorm:"serializer:json"` // Value in the file -> <m|f|d>
+
+	TrainerEmail string `json:"trainer_email" gorm:"uniqueIndex:unique_combination_import_profile"`
+	// BelongsTo Trainer (FK: TrainerEmail -> Trainer.Email)
+	Trainer Trainer `json:"-" gorm:"foreignKey:TrainerEmail;references:Email;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
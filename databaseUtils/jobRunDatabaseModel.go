@@ -0,0 +1,20 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+type JobRun struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	JobName    string     `json:"job_name" gorm:"index;not null"`
+	Trigger    string     `json:"trigger" gorm:"not null"` // schedule, startup or manual
+	Status     string     `json:"status" gorm:"index;not null"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Result     string     `json:"result"`
+	Error      string     `json:"error"`
+}
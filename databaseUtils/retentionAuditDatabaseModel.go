@@ -0,0 +1,22 @@
+package databaseUtils
+
+import (
+	"time"
+)
+
+// RetentionAudit records a single entry that was removed or anonymized by a retention policy.
+// It intentionally contains no personal data and no foreign keys, since the referenced entries are gone.
+type RetentionAudit struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time `gorm:"index"`
+
+	RunId      string `json:"run_id" gorm:"index;not null"`
+	Rule       string `json:"rule" gorm:"not null"`
+	Action     string `json:"action" gorm:"not null"`
+	EntityType string `json:"entity_type" gorm:"not null"`
+	EntityId   uint   `json:"entity_id"`
+	AthleteId  uint   `json:"athlete_id" gorm:"index"`
+	Reason     string `json:"reason"`
+}
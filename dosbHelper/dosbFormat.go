@@ -0,0 +1,341 @@
+// Package dosbHelper implements the csv layout of result lists exchanged with the DOSB Sportabzeichen portal.
+//
+// A file starts with the header row followed by one row per achieved performance. Columns are separated by
+// semicolons and lines end with CRLF:
+//
+//	Name;Vorname;Geschlecht;Geburtsjahr;Geburtsdatum;Übung;Kategorie;Datum;Ergebnis;Punkte
+//
+// Geschlecht is m, w or d. Dates are written as DD.MM.YYYY, the import also accepts D.M.YYYY, DD.MM.YY and
+// YYYY-MM-DD. Ergebnis depends on the unit of the exercise: seconds with up to three decimals after a comma (12,4), minutes as M:SS with
+// optional decimals of the seconds (3:05 or 3:05,4),
+// meters with two decimals (4,50), centimeters and points as integers and ja/nein for exercises without a measure.
+// Punkte is the medal code: 1 bronze, 2 silver and 3 gold.
+package dosbHelper
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	Delimiter   = ';'
+	ColumnCount = 10
+
+	dateLayout = "02.01.2006"
+)
+
+// Header contains the column names of the format
+var Header = []string{"Name", "Vorname", "Geschlecht", "Geburtsjahr", "Geburtsdatum", "Übung", "Kategorie", "Datum", "Ergebnis", "Punkte"}
+
+var (
+	InvalidRecordError = errors.New("The row does not match the DOSB format")
+	InvalidResultError = errors.New("Invalid result for the unit of the exercise")
+	NoMedalError       = errors.New("Only performances with a medal can be exchanged")
+)
+
+// Entry is a performance in the units of the database
+type Entry struct {
+	LastName   string
+	FirstName  string
+	Sex        string // m, f or d
+	BirthDate  string // YYYY-MM-DD
+	Exercise   string
+	Discipline string
+	Date       string // YYYY-MM-DD
+	Result     string // Raw result, which is converted with ParseResult once the unit of the exercise is known
+	Medal      string // gold, silver or bronze
+}
+
+// ParseRecord validates a row of the format and converts it to an entry
+// Throws: InvalidRecordError, NoMedalError
+func ParseRecord(record []string) (*Entry, error) {
+	if len(record) != ColumnCount {
+		return nil, errors.Wrapf(InvalidRecordError, "Expected %d columns, got %d", ColumnCount, len(record))
+	}
+	for idx := range record {
+		record[idx] = strings.TrimSpace(record[idx])
+	}
+
+	for _, column := range []int{0, 1, 5, 6, 8} {
+		if record[column] == "" {
+			return nil, errors.Wrap(InvalidRecordError, Header[column]+" is empty")
+		}
+	}
+
+	sex, err1 := parseSex(record[2])
+	if err1 != nil {
+		return nil, err1
+	}
+
+	birthDate, err2 := ParseDate(record[4])
+	if err2 != nil {
+		return nil, errors.Wrap(err2, "Geburtsdatum")
+	}
+	if record[3] != "" && record[3] != birthDate[:4] {
+		return nil, errors.Wrap(InvalidRecordError, "Geburtsjahr does not match Geburtsdatum")
+	}
+
+	date, err3 := ParseDate(record[7])
+	if err3 != nil {
+		return nil, errors.Wrap(err3, "Datum")
+	}
+
+	medal, err4 := MedalFromCode(record[9])
+	if err4 != nil {
+		return nil, err4
+	}
+
+	return &Entry{
+		LastName:   record[0],
+		FirstName:  record[1],
+		Sex:        sex,
+		BirthDate:  birthDate,
+		Exercise:   record[5],
+		Discipline: record[6],
+		Date:       date,
+		Result:     record[8],
+		Medal:      medal,
+	}, nil
+}
+
+// FormatRecord converts a performance to a row of the format. The points are in the unit of the exercise.
+// Throws: InvalidRecordError, InvalidResultError, NoMedalError
+func FormatRecord(entry Entry, points uint64, unit string) ([]string, error) {
+	birthDate, err1 := time.Parse(time.DateOnly, entry.BirthDate)
+	if err1 != nil {
+		return nil, errors.Wrap(InvalidRecordError, "Invalid birth date "+entry.BirthDate)
+	}
+	date, err2 := time.Parse(time.DateOnly, entry.Date)
+	if err2 != nil {
+		return nil, errors.Wrap(InvalidRecordError, "Invalid date "+entry.Date)
+	}
+
+	result, err3 := FormatResult(points, unit)
+	if err3 != nil {
+		return nil, err3
+	}
+	medalCode, err4 := MedalCode(entry.Medal)
+	if err4 != nil {
+		return nil, err4
+	}
+
+	sex := entry.Sex
+	if sex == "f" {
+		sex = "w"
+	}
+
+	record := []string{
+		entry.LastName,
+		entry.FirstName,
+		sex,
+		strconv.Itoa(birthDate.Year()),
+		birthDate.Format(dateLayout),
+		entry.Exercise,
+		entry.Discipline,
+		date.Format(dateLayout),
+		result,
+		medalCode,
+	}
+
+	// Validate the record against the format, so that the portal accepts the file
+	if _, err5 := ParseRecord(append([]string(nil), record...)); err5 != nil {
+		return nil, err5
+	}
+	if _, err6 := ParseResult(result, unit); err6 != nil {
+		return nil, err6
+	}
+	return record, nil
+}
+
+// ParseDate converts a German date (DD.MM.YYYY, D.M.YYYY or DD.MM.YY) or an ISO date to YYYY-MM-DD.
+// Two-digit years are completed to the last hundred years.
+// Throws: InvalidRecordError
+func ParseDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date.Format(time.DateOnly), nil
+	}
+
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return "", errors.Wrap(InvalidRecordError, "Invalid date "+value)
+	}
+	day, err1 := strconv.Atoi(parts[0])
+	month, err2 := strconv.Atoi(parts[1])
+	year, err3 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || err3 != nil || (len(parts[2]) != 2 && len(parts[2]) != 4) {
+		return "", errors.Wrap(InvalidRecordError, "Invalid date "+value)
+	}
+
+	if len(parts[2]) == 2 {
+		currentYear := time.Now().Year()
+		year += currentYear / 100 * 100
+		if year > currentYear {
+			year -= 100
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || int(date.Month()) != month {
+		return "", errors.Wrap(InvalidRecordError, "Invalid date "+value)
+	}
+	return date.Format(time.DateOnly), nil
+}
+
+// ParseResult converts a result of the format to the points stored for the unit
+// (milliseconds for times, centimeters for distances).
+// Throws: InvalidResultError
+func ParseResult(value string, unit string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	invalid := errors.Wrapf(InvalidResultError, "%s (%s)", value, unit)
+
+	switch unit {
+	case "second":
+		seconds, err := parseDecimal(value, 3)
+		if err != nil {
+			return 0, invalid
+		}
+		return uint64(math.Round(seconds * 1000)), nil
+	case "minute":
+		parts := strings.Split(value, ":")
+		if len(parts) != 2 {
+			return 0, invalid
+		}
+		if wholeSeconds, _, _ := strings.Cut(strings.Replace(parts[1], ",", ".", 1), "."); len(wholeSeconds) != 2 {
+			return 0, invalid
+		}
+		minutes, err1 := strconv.ParseUint(parts[0], 10, 32)
+		seconds, err2 := parseDecimal(parts[1], 3)
+		if err1 != nil || err2 != nil || seconds >= 60 {
+			return 0, invalid
+		}
+		return minutes*60_000 + uint64(math.Round(seconds*1000)), nil
+	case "meter":
+		meters, err := parseDecimal(value, 2)
+		if err != nil {
+			return 0, invalid
+		}
+		return uint64(math.Round(meters * 100)), nil
+	case "centimeter", "point":
+		points, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, invalid
+		}
+		return points, nil
+	case "bool":
+		switch strings.ToLower(value) {
+		case "ja":
+			return 1, nil
+		case "nein":
+			return 0, nil
+		}
+		return 0, invalid
+	default:
+		return 0, errors.Wrap(InvalidResultError, "Unknown unit "+unit)
+	}
+}
+
+// FormatResult converts the stored points of the unit to a result of the format
+// Throws: InvalidResultError
+func FormatResult(points uint64, unit string) (string, error) {
+	switch unit {
+	case "second":
+		return formatSeconds(points, 1), nil
+	case "minute":
+		seconds := formatSeconds(points%60_000, 0)
+		if points%60_000 < 10_000 {
+			seconds = "0" + seconds
+		}
+		return fmt.Sprintf("%d:%s", points/60_000, seconds), nil
+	case "meter":
+		return strings.Replace(strconv.FormatFloat(float64(points)/100, 'f', 2, 64), ".", ",", 1), nil
+	case "centimeter", "point":
+		return strconv.FormatUint(points, 10), nil
+	case "bool":
+		if points > 0 {
+			return "ja", nil
+		}
+		return "nein", nil
+	default:
+		return "", errors.Wrap(InvalidResultError, "Unknown unit "+unit)
+	}
+}
+
+// MedalCode returns the code of the medal (bronze 1, silver 2, gold 3)
+// Throws: NoMedalError
+func MedalCode(medal string) (string, error) {
+	switch medal {
+	case "bronze":
+		return "1", nil
+	case "silver":
+		return "2", nil
+	case "gold":
+		return "3", nil
+	default:
+		return "", NoMedalError
+	}
+}
+
+// MedalFromCode returns the medal of the code
+// Throws: NoMedalError, InvalidRecordError
+func MedalFromCode(code string) (string, error) {
+	switch strings.TrimSpace(code) {
+	case "1":
+		return "bronze", nil
+	case "2":
+		return "silver", nil
+	case "3":
+		return "gold", nil
+	case "", "0":
+		return "", NoMedalError
+	default:
+		return "", errors.Wrap(InvalidRecordError, "Invalid Punkte "+code)
+	}
+}
+
+// parseSex converts the sex of the format (m, w, d) to the sex of the database (m, f, d)
+func parseSex(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "m", "männlich":
+		return "m", nil
+	case "w", "weiblich", "f":
+		return "f", nil
+	case "d", "divers":
+		return "d", nil
+	default:
+		return "", errors.Wrap(InvalidRecordError, "Invalid Geschlecht "+value)
+	}
+}
+
+// formatSeconds converts milliseconds to seconds with a decimal comma and at least the given number of decimals.
+// Hundredths and thousandths are only written if needed, so that the stored result is not changed by an export and a re-import.
+func formatSeconds(milliseconds uint64, minDecimals int) string {
+	decimals := 3
+	if milliseconds%1000 == 0 {
+		decimals = 0
+	} else if milliseconds%100 == 0 {
+		decimals = 1
+	} else if milliseconds%10 == 0 {
+		decimals = 2
+	}
+	decimals = max(decimals, minDecimals)
+	return strings.Replace(strconv.FormatFloat(float64(milliseconds)/1000, 'f', decimals, 64), ".", ",", 1)
+}
+
+// parseDecimal parses a number with a decimal comma (or point) and at most the given number of decimals
+func parseDecimal(value string, maxDecimals int) (float64, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	if idx := strings.Index(value, "."); idx >= 0 && len(value)-idx-1 > maxDecimals {
+		return 0, errors.New("Too many decimals")
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, errors.New("Invalid number")
+	}
+	return number, nil
+}
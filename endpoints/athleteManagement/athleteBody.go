@@ -16,9 +16,26 @@ type AthleteBodyWithId struct {
 	BirthDate string `json:"birth_date" example:"YYYY-MM-DD"`
 	Sex       string `json:"sex" example:"<m|f|d>"`
 	SwimCert  bool   `json:"swim_cert"`
+
+	ConsentRequired bool     `json:"consent_required"` // True for minors, whose guardians need to consent
+	Consents        []string `json:"consents" example:"store_documents"`
+
+	Anonymized bool `json:"anonymized"` // Anonymized athletes only keep their birth year and sex
 }
 
 type SwimCertificateWithID struct {
 	ID        uint `json:"cert_id" example:"1"`
 	AthleteId uint `json:"athlete_id" example:"1"`
 }
+
+type MergeAthletesBody struct {
+	SurvivingAthleteId uint `json:"surviving_athlete_id" example:"1"`
+	DuplicateAthleteId uint `json:"duplicate_athlete_id" example:"2"`
+}
+
+type DuplicateAthletePair struct {
+	Athlete      AthleteBodyWithId `json:"athlete"`
+	Duplicate    AthleteBodyWithId `json:"duplicate"`
+	Reason       string            `json:"reason" example:"Same birth date and similar name"`
+	NameDistance int               `json:"name_distance" example:"1"` // Distance of the compared names, the full name or the first name depending on the reason
+}
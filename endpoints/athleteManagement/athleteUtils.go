@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/LucaSchmitz2003/FlowWatch"
@@ -40,8 +42,8 @@ func translateAthleteBodies(ctx context.Context, athleteBodies []AthleteBody, tr
 }
 
 // translateAthleteToResponse converts an athlete database object to response type
-func translateAthleteToResponse(ctx context.Context, athlete databaseUtils.Athlete, swimcert bool) (*AthleteBodyWithId, error) {
-	_, span := endpoints.Tracer.Start(ctx, "TranslateAthleteToResponse")
+func translateAthleteToResponse(ctx context.Context, athlete databaseUtils.Athlete, swimcert bool, consents []string) (*AthleteBodyWithId, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "TranslateAthleteToResponse")
 	defer span.End()
 
 	// Reformat the date to the correct format
@@ -50,6 +52,15 @@ func translateAthleteToResponse(ctx context.Context, athlete databaseUtils.Athle
 		return nil, err
 	}
 
+	// Check if the consent of a guardian is required
+	minor, err := IsMinor(ctx, athlete)
+	if err != nil {
+		return nil, err
+	}
+	if consents == nil {
+		consents = []string{}
+	}
+
 	athleteResponse := AthleteBodyWithId{
 		AthleteId: athlete.ID,
 		FirstName: athlete.FirstName,
@@ -58,11 +69,25 @@ func translateAthleteToResponse(ctx context.Context, athlete databaseUtils.Athle
 		BirthDate: birthDate,
 		Sex:       athlete.Sex,
 		SwimCert:  swimcert,
+
+		ConsentRequired: minor,
+		Consents:        consents,
+
+		Anonymized: athlete.AnonymizedAt != nil,
 	}
 
 	return &athleteResponse, nil
 }
 
+// capitalizeFirstLetter converts the first letter of the name to upper case, also if it is a multi-byte character (e.g. ä)
+func capitalizeFirstLetter(name string) string {
+	firstLetter, size := utf8.DecodeRuneInString(name)
+	if firstLetter == utf8.RuneError {
+		return name
+	}
+	return string(unicode.ToUpper(firstLetter)) + name[size:]
+}
+
 // validateAthlete checks if all values of an athlete are valid
 // Throws: Forwards errors of the formatHelper
 func validateAthlete(ctx context.Context, athlete *databaseUtils.Athlete) error {
@@ -78,15 +103,16 @@ func validateAthlete(ctx context.Context, athlete *databaseUtils.Athlete) error
 	}
 
 	// Capitalize the first letter of the name
-	athlete.FirstName = strings.ToUpper(string(athlete.FirstName[0])) + athlete.FirstName[1:]
-	athlete.LastName = strings.ToUpper(string(athlete.LastName[0])) + athlete.LastName[1:]
-
-	athlete.Email = strings.ToLower(athlete.Email)
-	if err := formatHelper.IsEmpty(athlete.Email); err != nil {
-		return errors.Wrap(err, "Email address")
-	} else if err = formatHelper.IsEmail(athlete.Email); err != nil {
-		err = errors.Wrap(err, "Invalid email address")
-		return err
+	athlete.FirstName = capitalizeFirstLetter(athlete.FirstName)
+	athlete.LastName = capitalizeFirstLetter(athlete.LastName)
+
+	// The email address is optional, but has to be valid if given
+	athlete.Email = strings.ToLower(strings.TrimSpace(athlete.Email))
+	if athlete.Email != "" {
+		if err := formatHelper.IsEmail(athlete.Email); err != nil {
+			err = errors.Wrap(err, "Invalid email address")
+			return err
+		}
 	}
 
 	if err := formatHelper.IsEmpty(athlete.BirthDate); err != nil {
@@ -153,13 +179,24 @@ func createNewAthletes(ctx context.Context, athletes []databaseUtils.Athlete) (e
 }
 
 // updateAthlete updates the given athlete in the database
+// Throws: AlreadyAnonymizedError if the athlete has been anonymized
 func updateAthlete(ctx context.Context, athlete databaseUtils.Athlete) error {
 	ctx, span := endpoints.Tracer.Start(ctx, "EditAthlete")
 	defer span.End()
 
 	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
-		err := tx.Model(databaseUtils.Athlete{}).Where("id = ?", athlete.ID).Updates(athlete).Error
-		return err
+		// Select all fields to allow clearing the email address
+		result := tx.Model(databaseUtils.Athlete{}).
+			Where("id = ? AND anonymized_at IS NULL", athlete.ID).
+			Select("first_name", "last_name", "email", "birth_date", "sex").
+			Updates(athlete)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return AlreadyAnonymizedError
+		}
+		return nil
 	})
 	err1 = databaseUtils.TranslatePostgresError(err1)
 	if err1 != nil {
@@ -168,27 +205,23 @@ func updateAthlete(ctx context.Context, athlete databaseUtils.Athlete) error {
 	return err1
 }
 
-// athleteExists checks if the given athlete is already in the database (email and birth_date combination).
+// athleteExists checks if the given athlete is already in the database
+// (first name, last name and birth_date combination of the same trainer).
 // Note: If the error is not nil, the bool is invalid.
 func athleteExists(ctx context.Context, athlete *databaseUtils.Athlete, checkWithId bool) (bool, error) {
 	ctx, span := endpoints.Tracer.Start(ctx, "CheckAthleteExists")
 	defer span.End()
 
-	// Check if the email and birth_date combo already exists
+	// Check if the name and birth_date combo already exists for the trainer
 	var athleteCount int64
 	err2 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
-		var err error
+		query := tx.Model(&databaseUtils.Athlete{}).
+			Where("first_name ILIKE ? AND last_name ILIKE ? AND birth_date = ? AND trainer_email = ?",
+				athlete.FirstName, athlete.LastName, athlete.BirthDate, strings.ToLower(athlete.TrainerEmail))
 		if checkWithId {
-			err = tx.Model(&databaseUtils.Athlete{}).
-				Where("email ILIKE ? AND birth_date = ? AND first_name ILIKE ? AND id != ?",
-					strings.ToLower(athlete.Email), athlete.BirthDate, athlete.FirstName, athlete.ID).
-				Count(&athleteCount).Error
-		} else {
-			err = tx.Model(&databaseUtils.Athlete{}).
-				Where("email ILIKE ? AND birth_date = ? AND first_name ILIKE ?",
-					strings.ToLower(athlete.Email), athlete.BirthDate, athlete.FirstName).
-				Count(&athleteCount).Error
+			query = query.Where("id != ?", athlete.ID)
 		}
+		err := query.Count(&athleteCount).Error
 		return err
 	})
 	if err2 != nil {
@@ -278,7 +311,8 @@ func GetAthleteFromPerformanceId(ctx context.Context, performanceId uint, traine
 	return &athlete, nil
 }
 
-// CalculateAge parses the birthDate string and returns the age
+// CalculateAge parses the birthDate string and returns the age.
+// The age classes of the badge use badgeHelper.AgeInYear instead, since they are decided by the age reached in the year.
 func CalculateAge(ctx context.Context, birthDate string) (int, error) {
 	_, span := endpoints.Tracer.Start(ctx, "CalculateAge")
 	defer span.End()
@@ -326,3 +360,24 @@ func GetAthleteByDetails(
 
 	return &athlete, nil
 }
+
+// GetAthletesByName returns all athletes of the trainer with the given first and last name (case-insensitive)
+func GetAthletesByName(ctx context.Context, firstName, lastName, trainerEmail string) ([]databaseUtils.Athlete, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetAthletesByName")
+	defer span.End()
+
+	var athletes []databaseUtils.Athlete
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.
+			Where("lower(first_name) = ? AND lower(last_name) = ? AND trainer_email = ?",
+				strings.ToLower(strings.TrimSpace(firstName)), strings.ToLower(strings.TrimSpace(lastName)), strings.ToLower(trainerEmail)).
+			Find(&athletes).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the athletes by name")
+		return nil, err1
+	}
+
+	return athletes, nil
+}
@@ -0,0 +1,150 @@
+package athleteManagement
+
+import (
+	"context"
+	"slices"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	// AgeOfMajority is the age from which on athletes can consent for themselves
+	AgeOfMajority = 18
+
+	ConsentScopeStoreData      = "store_data"
+	ConsentScopeStoreDocuments = "store_documents"
+	ConsentScopePublishResults = "publish_results"
+)
+
+var (
+	PossibleConsentScopes = []string{ConsentScopeStoreData, ConsentScopeStoreDocuments, ConsentScopePublishResults}
+	PossibleConsentTypes  = []string{"written", "digital", "verbal"}
+)
+
+// GetActiveConsentScopes returns the scopes of all not revoked consents for each of the given athletes
+func GetActiveConsentScopes(ctx context.Context, athleteIds []uint) (map[uint][]string, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetActiveConsentScopesFromDB")
+	defer span.End()
+
+	scopes := make(map[uint][]string)
+	if len(athleteIds) == 0 {
+		return scopes, nil
+	}
+
+	var consents []databaseUtils.Consent
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Consent{}).
+			Select("DISTINCT athlete_id, scope").
+			Where("athlete_id IN ? AND revoked_at IS NULL", athleteIds).
+			Order("scope ASC").
+			Find(&consents).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the consents")
+		return nil, err1
+	}
+
+	for _, consent := range consents {
+		scopes[consent.AthleteId] = append(scopes[consent.AthleteId], consent.Scope)
+	}
+	return scopes, nil
+}
+
+// HasActiveConsent checks if a not revoked consent with the given scope exists for the athlete
+func HasActiveConsent(ctx context.Context, athleteId uint, scope string) (bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "HasActiveConsent")
+	defer span.End()
+
+	var consentCount int64
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Consent{}).
+			Where("athlete_id = ? AND scope = ? AND revoked_at IS NULL", athleteId, scope).
+			Count(&consentCount).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to check the consent")
+		return false, err1
+	}
+
+	return consentCount > 0, nil
+}
+
+// IsMinor checks if the athlete is younger than the age of majority
+func IsMinor(ctx context.Context, athlete databaseUtils.Athlete) (bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "IsMinor")
+	defer span.End()
+
+	birthDate, err1 := formatHelper.FormatDate(athlete.BirthDate)
+	if err1 != nil {
+		return false, err1
+	}
+	age, err2 := CalculateAge(ctx, birthDate)
+	if err2 != nil {
+		return false, err2
+	}
+
+	return age < AgeOfMajority, nil
+}
+
+// IsConsentMissing checks if the athlete is a minor and no guardian consent with the given scope exists.
+// Adult athletes never require a guardian consent.
+func IsConsentMissing(ctx context.Context, athlete databaseUtils.Athlete, scope string) (bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "IsConsentMissing")
+	defer span.End()
+
+	minor, err1 := IsMinor(ctx, athlete)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to check the age of the athlete")
+		return false, err1
+	}
+	if !minor {
+		return false, nil
+	}
+
+	hasConsent, err2 := HasActiveConsent(ctx, athlete.ID, scope)
+	if err2 != nil {
+		return false, err2
+	}
+	return !hasConsent, nil
+}
+
+// GetAthletesWithoutConsent returns the set of ids of the minors among the given athletes without an active consent of the given scope.
+// Adult athletes never require a guardian consent.
+func GetAthletesWithoutConsent(ctx context.Context, athletes []databaseUtils.Athlete, scope string) (map[uint]bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetAthletesWithoutConsent")
+	defer span.End()
+
+	withoutConsent := make(map[uint]bool)
+	minorIds := make([]uint, 0, len(athletes))
+	for _, athlete := range athletes {
+		minor, err1 := IsMinor(ctx, athlete)
+		if err1 != nil {
+			err1 = errors.Wrap(err1, "Failed to check the age of the athlete")
+			return nil, err1
+		}
+		if minor {
+			minorIds = append(minorIds, athlete.ID)
+		}
+	}
+
+	consentScopes, err2 := GetActiveConsentScopes(ctx, minorIds)
+	if err2 != nil {
+		return nil, err2
+	}
+	for _, athleteId := range minorIds {
+		if !slices.Contains(consentScopes[athleteId], scope) {
+			withoutConsent[athleteId] = true
+		}
+	}
+
+	return withoutConsent, nil
+}
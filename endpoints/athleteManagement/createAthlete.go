@@ -11,7 +11,7 @@ import (
 
 // CreateAthlete creates a new athlete profile
 // @Summary Creates a new athlete profile
-// @Description Creates a new athlete profile with the given data. Duplicate email and birthdate combinations are not allowed.
+// @Description Creates a new athlete profile with the given data. The email address is optional. Duplicate name and birthdate combinations of the same trainer are not allowed.
 // @Tags Athlete Management
 // @Accept json
 // @Produce json
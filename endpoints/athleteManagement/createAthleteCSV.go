@@ -1,46 +1,88 @@
 package athleteManagement
 
 import (
-	"encoding/csv"
+	"context"
 	"fmt"
+	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/LucaSchmitz2003/FlowWatch"
-	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/csvHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/Team-Reissdorf/Backend/xlsxHelper"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
-type AlreadyExistingAthletesResponse struct {
-	Message                 string                  `json:"message" example:"Creation successful"`
-	AlreadyExistingAthletes []databaseUtils.Athlete `json:"already_existing_athletes"`
+var csvColumnCount = 5
+
+// athleteImporter imports athletes row by row in the background
+var athleteImporter = importJobs.Importer{
+	Type:            "athletes",
+	ValidateRow:     validateAthleteRow,
+	CreateEntries:   createAthleteEntries,
+	Fields:          []string{"first_name", "last_name", "email", "birth_date", "sex"},
+	RequiredFields:  []string{"first_name", "last_name", "birth_date", "sex"},
+	DateFields:      []string{"birth_date"},
+	AcceptsProfiles: true,
+	Preview: func(entry interface{}) interface{} {
+		athlete := entry.(databaseUtils.Athlete)
+		return AthleteBody{
+			FirstName: athlete.FirstName,
+			LastName:  athlete.LastName,
+			Email:     athlete.Email,
+			BirthDate: athlete.BirthDate,
+			Sex:       athlete.Sex,
+		}
+	},
 }
 
-var csvColumnCount = 5
+func init() {
+	importJobs.RegisterImporter(athleteImporter)
+}
 
 // CreateAthleteCSV bulk creates new athletes in the db from a csv file
 // @Summary Bulk creates new athletes from csv file
-// @Description Upload a CSV file to create multiple athlete profiles. If an athlete already exists, the process will continue, and the response will indicate which athletes already exist.
+// @Description Upload a CSV file or an XLSX workbook to create multiple athlete profiles. The file is imported in the background, the progress and the result of every row can be polled with the returned import job id. Athletes that already exist are skipped. The encoding (UTF-8, Windows-1252 or ISO-8859-1), the delimiter and a header row are detected automatically. Of workbooks, the first sheet or the sheet selected with sheet is imported. With dry_run, the file is only validated in the background and the preview of every row can be polled with the import job. The preview can be imported with /v1/import/commit afterward.
+// @Description Breaking change: the endpoint used to create the athletes within the request and answer 201 with the already_existing_athletes. It now answers 202 with the started import job, the already existing athletes are the skipped rows of /v1/import/get/{ImportJobId}.
 // @Tags Athlete Management
 // @Accept multipart/form-data
 // @Produce json
-// @Param Athletes formData file true "CSV file containing details of multiple athletes to create profiles"
+// @Param Athletes formData file true "CSV or XLSX file containing details of multiple athletes to create profiles"
+// @Param dry_run query bool false "Only validate the file, the preview of every row can be polled with the import job"
+// @Param profile_id query int false "Column mapping profile to read the file with"
+// @Param sheet query string false "Name of the sheet of a XLSX workbook, defaults to the first sheet"
 // @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
-// @Success 201 {object} AlreadyExistingAthletesResponse "Creation successful"
+// @Success 202 {object} importJobs.ImportJobResponse "Import started, or the preview with dry_run"
 // @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
 // @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
-// @Failure 409 {object} AlreadyExistingAthletesResponse "All athletes already exist; none have been created"
+// @Failure 404 {object} endpoints.ErrorResponse "Import profile not found"
 // @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
 // @Router /v1/athlete/bulk-create [post]
 func CreateAthleteCSV(c *gin.Context) {
 	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CreateMultipleAthletes")
 	defer span.End()
 
+	// Check if only a preview is requested
+	dryRun := false
+	if dryRunString := c.Query("dry_run"); dryRunString != "" {
+		var err0 error
+		dryRun, err0 = strconv.ParseBool(dryRunString)
+		if err0 != nil {
+			err0 = errors.Wrap(err0, "Invalid 'dry_run' query parameter")
+			FlowWatch.GetLogHelper().Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'dry_run' query parameter"})
+			return
+		}
+	}
+
 	// Bind body to csv file
 	file, err1 := c.FormFile("Athletes")
 	if err1 != nil || file == nil {
@@ -50,10 +92,8 @@ func CreateAthleteCSV(c *gin.Context) {
 		return
 	}
 
-	// Check MIME type
-	fileHeader := file.Header.Get("Content-Type")
-	if !strings.HasPrefix(fileHeader, "text/csv") && !strings.HasPrefix(fileHeader, "application/vnd.ms-excel") {
-		err := errors.New("Invalid file type, only CSV files are allowed")
+	// Check the file type
+	if err := csvHelper.CheckFileType(file); err != nil {
 		FlowWatch.GetLogHelper().Debug(ctx, err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
 		return
@@ -62,135 +102,211 @@ func CreateAthleteCSV(c *gin.Context) {
 	// Get the user id from the context
 	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
 
-	// Open the CSV file
-	fileContent, err2 := file.Open()
-	if err2 != nil {
-		err2 = errors.Wrap(err2, "Failed to open file")
-		FlowWatch.GetLogHelper().Debug(ctx, err2)
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Could not open file"})
-		return
-	}
-	defer func(fileContent multipart.File) {
-		err := fileContent.Close()
-		if err != nil {
-			err = errors.Wrap(err, "Failed to close file")
-			FlowWatch.GetLogHelper().Error(ctx, err)
-		}
-	}(fileContent)
-
-	// Read the CSV file
-	reader := csv.NewReader(fileContent)
-	reader.Comma = ';'
-	records, err3 := reader.ReadAll()
-	if err3 != nil {
-		err3 = errors.Wrap(err3, "Failed to read csv. Invalid CSV format?")
-		FlowWatch.GetLogHelper().Warn(ctx, err3)
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File could not be read. Invalid CSV format?"})
-		return
-	}
-
-	// Parse data
-	var athleteEntries []databaseUtils.Athlete
-	for _, record := range records {
-		// Ensure the column count is correct
-		if len(record) != csvColumnCount {
-			err := errors.New("Inconsistent number of columns in the CSV file")
-			FlowWatch.GetLogHelper().Debug(ctx, err)
-			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+	// Get the selected column mapping profile
+	var profile *databaseUtils.ImportProfile
+	if profileIdString := c.Query("profile_id"); profileIdString != "" {
+		profileId, errProfileId := strconv.ParseUint(profileIdString, 10, 32)
+		if errProfileId != nil {
+			errProfileId = errors.Wrap(errProfileId, "Failed to parse import profile ID")
+			FlowWatch.GetLogHelper().Debug(ctx, errProfileId)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid import profile ID"})
 			return
 		}
 
-		sex := strings.ToLower(record[4])
-		sex = strings.TrimSpace(sex)
-
-		if len(sex) == 0 {
-			FlowWatch.GetLogHelper().Debug(ctx, "Empty sex attribute in record: ", record)
-			c.AbortWithStatusJSON(http.StatusBadRequest,
-				endpoints.ErrorResponse{Error: "Sex attribute cannot be empty"})
+		var errProfile error
+		profile, errProfile = importJobs.GetImportProfile(ctx, uint(profileId), trainerEmail)
+		if errors.Is(errProfile, importJobs.ImportProfileNotFoundError) {
+			FlowWatch.GetLogHelper().Debug(ctx, errProfile)
+			c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Import profile not found"})
 			return
-		}
-		sex = sex[:1]
-
-		// Normalize the sex attribute
-		switch sex {
-		case "m", "f", "d":
-
-		case "w":
-			sex = "f"
-		default:
-			FlowWatch.GetLogHelper().Debug(ctx, "Invalid sex attribute: ", record[4][:1])
-			c.AbortWithStatusJSON(http.StatusBadRequest,
-				endpoints.ErrorResponse{Error: fmt.Sprintf("Invalid sex attribute: %s", sex)})
+		} else if errProfile != nil {
+			FlowWatch.GetLogHelper().Error(ctx, errProfile)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the import profile"})
 			return
 		}
+	}
 
-		// Map CSV data to an athlete object
-		athlete := databaseUtils.Athlete{
-			FirstName:    record[0],
-			LastName:     record[1],
-			BirthDate:    record[3],
-			Sex:          sex,
-			Email:        record[2],
-			TrainerEmail: trainerEmail,
-		}
+	// Read the CSV file or the workbook, a mapping profile defines the delimiter and the header rows itself
+	csvOptions := csvHelper.Options{DetectHeader: true, HeaderNames: athleteImporter.Fields}
+	if profile != nil {
+		csvOptions = csvHelper.Options{Delimiter: importJobs.GetDelimiter(profile)}
+	}
+	csvOptions.Sheet = c.Query("sheet")
+	csvFile, err3 := csvHelper.ReadUpload(file, csvOptions)
+	if errors.Is(err3, csvHelper.EmptyFileError) || errors.Is(err3, xlsxHelper.SheetNotFoundError) {
+		FlowWatch.GetLogHelper().Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err3.Error()})
+		return
+	} else if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to read the file. Invalid CSV or XLSX format?")
+		FlowWatch.GetLogHelper().Warn(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File could not be read. Invalid CSV or XLSX format?"})
+		return
+	}
+	FlowWatch.GetLogHelper().Debug(ctx, fmt.Sprintf("Read %s file with encoding %s and delimiter %q", csvFile.Format, csvFile.Encoding, csvFile.Delimiter))
+	records := csvFile.Records
 
-		// This is for a design issue revolving the date format in the csv file
-		// The date format in the csv file is dd.mm.yyyy
-		// The date format in the db is yyyy-mm-dd
-		// So we need to convert the date format from dd.mm.yyyy to yyyy-mm-dd
-		// Instead of using strings, we should use a date format library like time which we already use in the rest of the code
-		if errors.Is(formatHelper.IsDate(athlete.BirthDate), formatHelper.DateFormatInvalidError) {
-			if len(athlete.BirthDate) == 10 {
-				athlete.BirthDate = athlete.BirthDate[6:10] + "-" + athlete.BirthDate[3:5] + "-" + athlete.BirthDate[0:2]
-			}
+	// Rearrange the columns according to the mapping profile
+	if profile != nil {
+		var errMapping error
+		records, errMapping = importJobs.ApplyImportProfile(profile, athleteImporter.Type, records)
+		if errMapping != nil {
+			FlowWatch.GetLogHelper().Debug(ctx, errMapping)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: errMapping.Error()})
+			return
 		}
+	}
 
-		athleteEntries = append(athleteEntries, athlete)
-
-		// Validate the athlete body
-		err1 := validateAthlete(ctx, &athlete)
-		if errors.Is(err1, formatHelper.InvalidSexLengthError) || errors.Is(err1, formatHelper.InvalidSexValue) {
-			FlowWatch.GetLogHelper().Debug(ctx, err1)
-			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Sex needs to be <m|f|d>"})
-			return
-		} else if errors.Is(err1, formatHelper.DateFormatInvalidError) {
-			FlowWatch.GetLogHelper().Debug(ctx, err1)
-			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid date format"})
-			return
-		} else if errors.Is(err1, formatHelper.DateInFutureError) {
-			FlowWatch.GetLogHelper().Debug(ctx, err1)
-			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Date is in the Future"})
-			return
-		} else if errors.Is(err1, formatHelper.InvalidEmailAddressFormatError) || errors.Is(err1, formatHelper.EmailAddressContainsNameError) || errors.Is(err1, formatHelper.EmailAddressInvalidTldError) {
-			FlowWatch.GetLogHelper().Debug(ctx, err1)
-			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid email address format"})
-			return
-		} else if err1 != nil {
-			err1 = errors.Wrap(err1, "Failed to validate the athlete body")
-			FlowWatch.GetLogHelper().Error(ctx, err1)
-			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Internal server error"})
+	// Only validate the rows in the background, the preview can be polled with the import job
+	if dryRun {
+		previewJob, errPreview := importJobs.Preview(ctx, athleteImporter, trainerEmail, file.Filename, records)
+		if errPreview != nil {
+			FlowWatch.GetLogHelper().Error(ctx, errPreview)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to preview the import"})
 			return
 		}
-	}
 
-	// Write athletes to the db
-	err4, alreadyExistingAthletes := createNewAthletes(ctx, athleteEntries)
-	if errors.Is(err4, NoNewAthletesError) {
-		FlowWatch.GetLogHelper().Debug(ctx, err4)
-		c.AbortWithStatusJSON(http.StatusConflict, AlreadyExistingAthletesResponse{Message: "No new Athletes", AlreadyExistingAthletes: athleteEntries})
+		c.JSON(
+			http.StatusAccepted,
+			importJobs.ImportJobResponse{
+				Message:   "Preview started",
+				ImportJob: importJobs.TranslateImportJobToResponse(*previewJob),
+			},
+		)
 		return
-	} else if err4 != nil {
-		err4 = errors.Wrap(err4, "Failed to create the athletes")
+	}
+
+	// Import the rows in the background
+	importJob, err4 := importJobs.Start(ctx, athleteImporter, trainerEmail, file.Filename, records)
+	if err4 != nil {
 		FlowWatch.GetLogHelper().Error(ctx, err4)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Internal server error"})
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to start the import"})
 		return
 	}
 
 	c.JSON(
-		http.StatusCreated,
-		AlreadyExistingAthletesResponse{
-			Message:                 "Creation successful",
-			AlreadyExistingAthletes: alreadyExistingAthletes,
+		http.StatusAccepted,
+		importJobs.ImportJobResponse{
+			Message:   "Import started",
+			ImportJob: importJobs.TranslateImportJobToResponse(*importJob),
 		},
 	)
 }
+
+// validateAthleteRow validates a single csv record of the athlete import and returns the athlete to create
+func validateAthleteRow(ctx context.Context, trainerEmail string, record []string) (interface{}, *importJobs.RowError) {
+	// Ensure the column count is correct
+	if len(record) != csvColumnCount {
+		return nil, importJobs.RowFailed("Inconsistent number of columns")
+	}
+
+	sex := strings.ToLower(record[4])
+	sex = strings.TrimSpace(sex)
+
+	if len(sex) == 0 {
+		FlowWatch.GetLogHelper().Debug(ctx, "Empty sex attribute in record: ", record)
+		return nil, importJobs.RowFailed("Sex attribute cannot be empty")
+	}
+	sex = sex[:1]
+
+	// Normalize the sex attribute
+	switch sex {
+	case "m", "f", "d":
+
+	case "w":
+		sex = "f"
+	default:
+		FlowWatch.GetLogHelper().Debug(ctx, "Invalid sex attribute: ", sex)
+		return nil, importJobs.RowFailed(fmt.Sprintf("Invalid sex attribute: %s", sex))
+	}
+
+	// Map CSV data to an athlete object
+	athlete := databaseUtils.Athlete{
+		FirstName:    record[0],
+		LastName:     record[1],
+		BirthDate:    record[3],
+		Sex:          sex,
+		Email:        record[2],
+		TrainerEmail: trainerEmail,
+	}
+
+	// This is for a design issue revolving the date format in the csv file
+	// The date format in the csv file is dd.mm.yyyy
+	// The date format in the db is yyyy-mm-dd
+	// So we need to convert the date format from dd.mm.yyyy to yyyy-mm-dd
+	// Instead of using strings, we should use a date format library like time which we already use in the rest of the code
+	if errors.Is(formatHelper.IsDate(athlete.BirthDate), formatHelper.DateFormatInvalidError) {
+		if len(athlete.BirthDate) == 10 {
+			athlete.BirthDate = athlete.BirthDate[6:10] + "-" + athlete.BirthDate[3:5] + "-" + athlete.BirthDate[0:2]
+		}
+	}
+
+	// Validate the athlete body
+	if rowErr := ValidateImportedAthlete(ctx, &athlete); rowErr != nil {
+		return nil, rowErr
+	}
+
+	// Skip athletes that already exist
+	exists, err2 := athleteExists(ctx, &athlete, false)
+	if err2 != nil {
+		FlowWatch.GetLogHelper().Error(ctx, err2)
+		return nil, importJobs.RowFailed("Could not check if the athlete already exists")
+	}
+	if exists {
+		return nil, importJobs.RowSkipped("Athlete already exists")
+	}
+
+	return athlete, nil
+}
+
+// ValidateImportedAthlete validates an athlete of an import and translates the validation error to the reason of the row
+func ValidateImportedAthlete(ctx context.Context, athlete *databaseUtils.Athlete) *importJobs.RowError {
+	err1 := validateAthlete(ctx, athlete)
+	if errors.Is(err1, formatHelper.InvalidSexLengthError) || errors.Is(err1, formatHelper.InvalidSexValue) {
+		FlowWatch.GetLogHelper().Debug(ctx, err1)
+		return importJobs.RowFailed("Sex needs to be <m|f|d>")
+	} else if errors.Is(err1, formatHelper.DateFormatInvalidError) {
+		FlowWatch.GetLogHelper().Debug(ctx, err1)
+		return importJobs.RowFailed("Invalid date format")
+	} else if errors.Is(err1, formatHelper.DateInFutureError) {
+		FlowWatch.GetLogHelper().Debug(ctx, err1)
+		return importJobs.RowFailed("Date is in the Future")
+	} else if errors.Is(err1, formatHelper.InvalidEmailAddressFormatError) || errors.Is(err1, formatHelper.EmailAddressContainsNameError) || errors.Is(err1, formatHelper.EmailAddressInvalidTldError) {
+		FlowWatch.GetLogHelper().Debug(ctx, err1)
+		return importJobs.RowFailed("Invalid email address format")
+	} else if errors.Is(err1, formatHelper.EmptyStringError) {
+		FlowWatch.GetLogHelper().Debug(ctx, err1)
+		return importJobs.RowFailed(err1.Error())
+	} else if err1 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, err1)
+		return importJobs.RowFailed("Invalid athlete")
+	}
+
+	return nil
+}
+
+// createAthleteEntries writes the validated athletes of the import to the database
+func createAthleteEntries(ctx context.Context, entries []interface{}) ([]uint, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "CreateAthleteEntries")
+	defer span.End()
+
+	athletes := make([]databaseUtils.Athlete, len(entries))
+	for idx, entry := range entries {
+		athletes[idx] = entry.(databaseUtils.Athlete)
+	}
+
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Create(&athletes).Error
+	})
+	err1 = databaseUtils.TranslatePostgresError(err1)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to create the athletes")
+		return nil, err1
+	}
+
+	ids := make([]uint, len(athletes))
+	for idx, athlete := range athletes {
+		ids[idx] = athlete.ID
+	}
+	return ids, nil
+}
@@ -1,9 +1,7 @@
 package athleteManagement
 
 import (
-	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/Team-Reissdorf/Backend/authHelper"
-	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -14,7 +12,7 @@ import (
 
 // DeleteAthlete deletes the given athlete profile
 // @Summary Deletes the given athlete profile
-// @Description Deletes the given athlete profile.
+// @Description Deletes the given athlete profile including all performance entries, guardian contacts, consents and swim certificate files.
 // @Tags Athlete Management
 // @Produce json
 // @Param AthleteId path int true "Delete the given athlete"
@@ -47,17 +45,8 @@ func DeleteAthlete(c *gin.Context) {
 	// Get the user id from the context
 	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
 
-	// Delete the athlete from the database
-	err2 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
-		result := tx.Delete(&databaseUtils.Athlete{}, "trainer_email = ? AND id = ?", trainerEmail, athleteId)
-		if result.Error != nil {
-			return errors.Wrap(result.Error, "Failed to delete the athlete")
-		}
-		if result.RowsAffected == 0 {
-			return gorm.ErrRecordNotFound
-		}
-		return nil
-	})
+	// Delete the athlete from the database and remove the files
+	err2 := EraseAthlete(ctx, uint(athleteId), trainerEmail)
 	if errors.Is(err2, gorm.ErrRecordNotFound) {
 		err2 = errors.Wrap(err2, "Athlete not found")
 		endpoints.Logger.Debug(ctx, err2)
@@ -0,0 +1,221 @@
+package athleteManagement
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxDuplicateNameDistance is the maximum levenshtein distance of two normalized names to be treated as similar
+	maxDuplicateNameDistance = 2
+
+	SameBirthDateReason = "Same birth date and similar name"
+	SameEmailReason     = "Same email address and similar first name"
+)
+
+var (
+	SameAthleteError = errors.New("An athlete cannot be merged with itself")
+)
+
+// athleteDuplicate holds two athletes which are likely the same person
+type athleteDuplicate struct {
+	Athlete      databaseUtils.Athlete
+	Duplicate    databaseUtils.Athlete
+	Reason       string
+	NameDistance int
+}
+
+// findDuplicateAthletes compares all athletes of the given trainer with each other and returns the likely duplicates.
+// Two athletes are likely duplicates if they share the birth date and have a similar full name,
+// or if they share the email address and have a similar first name.
+func findDuplicateAthletes(ctx context.Context, trainerEmail string) ([]athleteDuplicate, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "FindDuplicateAthletes")
+	defer span.End()
+
+	var athletes []databaseUtils.Athlete
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Where("trainer_email = ? AND anonymized_at IS NULL", strings.ToLower(trainerEmail)).
+			Order("id ASC").
+			Find(&athletes).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the athletes")
+		return nil, err1
+	}
+
+	var duplicates []athleteDuplicate
+	for i := 0; i < len(athletes); i++ {
+		for j := i + 1; j < len(athletes); j++ {
+			a, b := athletes[i], athletes[j]
+
+			nameDistance := formatHelper.NameDistance(a.FirstName+a.LastName, b.FirstName+b.LastName)
+			if a.BirthDate == b.BirthDate && nameDistance <= maxDuplicateNameDistance {
+				duplicates = append(duplicates, athleteDuplicate{Athlete: a, Duplicate: b, Reason: SameBirthDateReason, NameDistance: nameDistance})
+				continue
+			}
+
+			sameEmail := a.Email != "" && strings.EqualFold(a.Email, b.Email)
+			if !sameEmail {
+				continue
+			}
+			firstNameDistance := formatHelper.NameDistance(a.FirstName, b.FirstName)
+			if firstNameDistance <= maxDuplicateNameDistance {
+				duplicates = append(duplicates, athleteDuplicate{Athlete: a, Duplicate: b, Reason: SameEmailReason, NameDistance: firstNameDistance})
+			}
+		}
+	}
+
+	return duplicates, nil
+}
+
+// getAthleteIdsWithSwimCertificate returns a set of the given athlete ids that have at least one swim certificate
+func getAthleteIdsWithSwimCertificate(ctx context.Context, athleteIds []uint) (map[uint]bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetAthleteIdsWithSwimCertificate")
+	defer span.End()
+
+	withCertificate := make(map[uint]bool)
+	if len(athleteIds) == 0 {
+		return withCertificate, nil
+	}
+
+	var ids []uint
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.SwimCertificate{}).
+			Distinct("athlete_id").
+			Where("athlete_id IN ?", athleteIds).
+			Pluck("athlete_id", &ids).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the swim certificates")
+		return nil, err1
+	}
+
+	for _, id := range ids {
+		withCertificate[id] = true
+	}
+	return withCertificate, nil
+}
+
+// MergeDuplicateAthlete moves all performance entries, swim certificates, guardian contacts, consents, historical and earned badges of the duplicate athlete
+// to the surviving athlete and deletes the duplicate afterward. Both athletes need to belong to the given trainer.
+// The duplicates are also merged before the database migration, so tables that do not exist yet are skipped.
+// Throws: SameAthleteError, gorm.ErrRecordNotFound and other
+func MergeDuplicateAthlete(ctx context.Context, survivingAthleteId uint, duplicateAthleteId uint, trainerEmail string) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "MergeAthletes")
+	defer span.End()
+
+	if survivingAthleteId == duplicateAthleteId {
+		return SameAthleteError
+	}
+
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		// Ensure both athletes belong to the trainer
+		var athleteCount int64
+		errA := tx.Model(&databaseUtils.Athlete{}).
+			Where("id IN ? AND trainer_email = ?", []uint{survivingAthleteId, duplicateAthleteId}, strings.ToLower(trainerEmail)).
+			Count(&athleteCount).
+			Error
+		if errA != nil {
+			return errors.Wrap(errA, "Failed to check if the athletes exist")
+		}
+		if athleteCount != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// Move the performance entries
+		migrator := tx.Migrator()
+		errB := tx.Model(&databaseUtils.Performance{}).
+			Where("athlete_id = ?", duplicateAthleteId).
+			Update("athlete_id", survivingAthleteId).
+			Error
+		if errB != nil {
+			return errors.Wrap(errB, "Failed to move the performance entries")
+		}
+
+		// Move the swim certificates
+		errC := tx.Model(&databaseUtils.SwimCertificate{}).
+			Where("athlete_id = ?", duplicateAthleteId).
+			Update("athlete_id", survivingAthleteId).
+			Error
+		if errC != nil {
+			return errors.Wrap(errC, "Failed to move the swim certificates")
+		}
+
+		// Move the guardian contacts
+		if migrator.HasTable(&databaseUtils.Guardian{}) {
+			errD := tx.Model(&databaseUtils.Guardian{}).
+				Where("athlete_id = ?", duplicateAthleteId).
+				Update("athlete_id", survivingAthleteId).
+				Error
+			if errD != nil {
+				return errors.Wrap(errD, "Failed to move the guardian contacts")
+			}
+		}
+
+		// Move the consents
+		if migrator.HasTable(&databaseUtils.Consent{}) {
+			errE := tx.Model(&databaseUtils.Consent{}).
+				Where("athlete_id = ?", duplicateAthleteId).
+				Update("athlete_id", survivingAthleteId).
+				Error
+			if errE != nil {
+				return errors.Wrap(errE, "Failed to move the consents")
+			}
+		}
+
+		// Move the historical badges, the surviving athlete's badge of a year is kept
+		if migrator.HasTable(&databaseUtils.HistoricalBadge{}) {
+			errF := tx.Where("athlete_id = ? AND year IN (?)", duplicateAthleteId,
+				tx.Model(&databaseUtils.HistoricalBadge{}).Select("year").Where("athlete_id = ?", survivingAthleteId)).
+				Delete(&databaseUtils.HistoricalBadge{}).
+				Error
+			if errF != nil {
+				return errors.Wrap(errF, "Failed to delete the duplicated historical badges")
+			}
+			errG := tx.Model(&databaseUtils.HistoricalBadge{}).
+				Where("athlete_id = ?", duplicateAthleteId).
+				Update("athlete_id", survivingAthleteId).
+				Error
+			if errG != nil {
+				return errors.Wrap(errG, "Failed to move the historical badges")
+			}
+		}
+
+		// Move the recorded badges in the same way
+		if migrator.HasTable(&databaseUtils.EarnedBadge{}) {
+			errH := tx.Where("athlete_id = ? AND year IN (?)", duplicateAthleteId,
+				tx.Model(&databaseUtils.EarnedBadge{}).Select("year").Where("athlete_id = ?", survivingAthleteId)).
+				Delete(&databaseUtils.EarnedBadge{}).
+				Error
+			if errH != nil {
+				return errors.Wrap(errH, "Failed to delete the duplicated earned badges")
+			}
+			errI := tx.Model(&databaseUtils.EarnedBadge{}).
+				Where("athlete_id = ?", duplicateAthleteId).
+				Update("athlete_id", survivingAthleteId).
+				Error
+			if errI != nil {
+				return errors.Wrap(errI, "Failed to move the earned badges")
+			}
+		}
+
+		// Delete the duplicate
+		errJ := tx.Delete(&databaseUtils.Athlete{}, "id = ?", duplicateAthleteId).Error
+		if errJ != nil {
+			return errors.Wrap(errJ, "Failed to delete the duplicate athlete")
+		}
+		return nil
+	})
+	return err1
+}
@@ -13,7 +13,7 @@ import (
 
 // EditAthlete edits an existing athlete profile
 // @Summary Edits an existing athlete profile
-// @Description Edits an existing athlete profile with the given details. Duplicate email and birthdate combinations are not allowed.
+// @Description Edits an existing athlete profile with the given details. The email address is optional. Duplicate name and birthdate combinations of the same trainer are not allowed. Anonymized athletes cannot be edited.
 // @Tags Athlete Management
 // @Accept json
 // @Produce json
@@ -23,7 +23,7 @@ import (
 // @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
 // @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
 // @Failure 404 {object} endpoints.ErrorResponse "Athlete could not be found for this trainer"
-// @Failure 409 {object} endpoints.ErrorResponse "Athlete already exists"
+// @Failure 409 {object} endpoints.ErrorResponse "Athlete already exists or has been anonymized"
 // @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
 // @Router /v1/athlete/edit [put]
 func EditAthlete(c *gin.Context) {
@@ -99,7 +99,11 @@ func EditAthlete(c *gin.Context) {
 
 	// Update the athlete in the database
 	err3 := updateAthlete(ctx, athleteEntry)
-	if errors.Is(err3, databaseUtils.ErrForeignKeyViolation) {
+	if errors.Is(err3, AlreadyAnonymizedError) {
+		endpoints.Logger.Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: "The athlete has been anonymized and cannot be edited"})
+		return
+	} else if errors.Is(err3, databaseUtils.ErrForeignKeyViolation) {
 		err3 = errors.Wrap(err3, "Another athlete with the same personal information already exists")
 		endpoints.Logger.Debug(ctx, err3)
 		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: "Another athlete with the same personal information already exists"})
@@ -0,0 +1,141 @@
+package athleteManagement
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	AnonymizedFirstName  = "Anonymous"
+	anonymizedNamePrefix = "athlete-"
+)
+
+var (
+	AlreadyAnonymizedError = errors.New("The athlete has already been anonymized")
+)
+
+// EraseAthlete deletes the athlete with all related entries (performances, swim certificates, guardians and consents)
+// and removes the swim certificate files from the disk.
+// Throws: gorm.ErrRecordNotFound and other
+func EraseAthlete(ctx context.Context, athleteId uint, trainerEmail string) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "EraseAthlete")
+	defer span.End()
+
+	var documentPaths []string
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		// Remember the files before the certificates get deleted by the cascade
+		errA := tx.Model(&databaseUtils.SwimCertificate{}).
+			Where("athlete_id = ?", athleteId).
+			Pluck("document_path", &documentPaths).
+			Error
+		if errA != nil {
+			return errors.Wrap(errA, "Failed to get the swim certificate files")
+		}
+
+		result := tx.Delete(&databaseUtils.Athlete{}, "trainer_email = ? AND id = ?", strings.ToLower(trainerEmail), athleteId)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "Failed to delete the athlete")
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err1 != nil {
+		return err1
+	}
+
+	RemoveDocumentFiles(ctx, documentPaths)
+	return nil
+}
+
+// AnonymizeAthlete replaces the athlete with a pseudonymous athlete that only keeps the birth year and sex.
+// The performance entries stay linked to the athlete, so aggregate statistics stay correct.
+// Swim certificates, guardians and consents are deleted and the swim certificate files are removed from the disk.
+// Throws: gorm.ErrRecordNotFound, AlreadyAnonymizedError and other
+func AnonymizeAthlete(ctx context.Context, athleteId uint, trainerEmail string) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "AnonymizeAthlete")
+	defer span.End()
+
+	var documentPaths []string
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		var athlete databaseUtils.Athlete
+		errA := tx.Where("trainer_email = ? AND id = ?", strings.ToLower(trainerEmail), athleteId).
+			First(&athlete).
+			Error
+		if errA != nil {
+			return errors.Wrap(errA, "Failed to get the athlete")
+		}
+		if athlete.AnonymizedAt != nil {
+			return AlreadyAnonymizedError
+		}
+
+		errB := tx.Model(&databaseUtils.SwimCertificate{}).
+			Where("athlete_id = ?", athleteId).
+			Pluck("document_path", &documentPaths).
+			Error
+		if errB != nil {
+			return errors.Wrap(errB, "Failed to get the swim certificate files")
+		}
+
+		// Delete all related entries that contain personal data
+		if errC := tx.Delete(&databaseUtils.SwimCertificate{}, "athlete_id = ?", athleteId).Error; errC != nil {
+			return errors.Wrap(errC, "Failed to delete the swim certificates")
+		}
+		if errD := tx.Delete(&databaseUtils.Guardian{}, "athlete_id = ?", athleteId).Error; errD != nil {
+			return errors.Wrap(errD, "Failed to delete the guardians")
+		}
+		if errE := tx.Delete(&databaseUtils.Consent{}, "athlete_id = ?", athleteId).Error; errE != nil {
+			return errors.Wrap(errE, "Failed to delete the consents")
+		}
+
+		// Only keep the birth year and the sex of the athlete.
+		// The id is part of the pseudonym, so the unique index of the athletes is not violated.
+		now := time.Now()
+		errF := tx.Model(&databaseUtils.Athlete{}).
+			Where("id = ?", athleteId).
+			Select("first_name", "last_name", "birth_date", "email", "anonymized_at").
+			Updates(databaseUtils.Athlete{
+				FirstName:    AnonymizedFirstName,
+				LastName:     anonymizedNamePrefix + strconv.FormatUint(uint64(athleteId), 10),
+				BirthDate:    athlete.BirthDate[:4] + "-01-01",
+				Email:        "",
+				AnonymizedAt: &now,
+			}).
+			Error
+		if errF != nil {
+			return errors.Wrap(errF, "Failed to anonymize the athlete")
+		}
+		return nil
+	})
+	if err1 != nil {
+		return err1
+	}
+
+	RemoveDocumentFiles(ctx, documentPaths)
+	return nil
+}
+
+// RemoveDocumentFiles removes the given files and their directories if they are empty afterward.
+// Files that cannot be removed are only logged, since the database entries are already gone.
+func RemoveDocumentFiles(ctx context.Context, documentPaths []string) {
+	for _, documentPath := range documentPaths {
+		if err := os.Remove(documentPath); err != nil && !os.IsNotExist(err) {
+			endpoints.Logger.Warn(ctx, errors.Wrap(err, "Failed to remove the file "+documentPath))
+			continue
+		}
+
+		// Fails on purpose if the directory still contains other files
+		_ = os.Remove(filepath.Dir(documentPath))
+	}
+}
@@ -0,0 +1,110 @@
+package athleteManagement
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/xlsxHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ExportAthletes exports all athletes of the trainer as a csv file or a xlsx workbook
+// @Summary Exports all athlete profiles as a csv file or a xlsx workbook
+// @Description All athlete profiles of the given trainer are exported with the columns of the athlete import, so that the file can be edited and imported again. With format=xlsx, the birth dates are typed date cells.
+// @Tags Athlete Management
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default) or xlsx"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {file} file "CSV file or XLSX workbook"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid export format"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/athlete/export [get]
+func ExportAthletes(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "ExportAthletes")
+	defer span.End()
+
+	// Check the requested file format
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		err := errors.New("Invalid export format " + format)
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'format' query parameter, allowed are csv and xlsx"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Get all athletes for the given trainer
+	var athletes []databaseUtils.Athlete
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Where("trainer_email = ?", strings.ToLower(trainerEmail)).
+			Order("last_name, first_name").
+			Find(&athletes).Error
+		err = errors.Wrap(err, "Failed to get the athletes")
+		return err
+	})
+	if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athletes"})
+		return
+	}
+
+	header := athleteImporter.Fields
+
+	if format == "xlsx" {
+		rows := make([][]xlsxHelper.Cell, 0, len(athletes))
+		for _, athlete := range athletes {
+			birthDate, err2 := time.Parse(time.DateOnly, athlete.BirthDate[:10])
+			if err2 != nil {
+				err2 = errors.Wrap(err2, "Invalid birth date of the athlete")
+				endpoints.Logger.Error(ctx, err2)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the export"})
+				return
+			}
+
+			rows = append(rows, []xlsxHelper.Cell{
+				xlsxHelper.String(athlete.FirstName),
+				xlsxHelper.String(athlete.LastName),
+				xlsxHelper.String(athlete.Email),
+				xlsxHelper.Date(birthDate),
+				xlsxHelper.String(athlete.Sex),
+			})
+		}
+
+		c.Header("Content-Type", xlsxHelper.ContentType)
+		c.Header("Content-Disposition", "attachment; filename=athletes.xlsx")
+		if err3 := xlsxHelper.Write(c.Writer, "Athleten", header, rows); err3 != nil {
+			err3 = errors.Wrap(err3, "Failed to write the xlsx export")
+			endpoints.Logger.Error(ctx, err3)
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=athletes.csv")
+	w := csv.NewWriter(c.Writer)
+	w.Comma = ';'
+	defer w.Flush()
+
+	_ = w.Write(header)
+	for _, athlete := range athletes {
+		_ = w.Write([]string{
+			athlete.FirstName,
+			athlete.LastName,
+			athlete.Email,
+			athlete.BirthDate[:10],
+			athlete.Sex,
+		})
+	}
+}
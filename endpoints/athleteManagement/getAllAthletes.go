@@ -2,6 +2,7 @@ package athleteManagement
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/LucaSchmitz2003/DatabaseFlow"
@@ -21,11 +22,13 @@ type AthletesResponse struct {
 
 // GetAllAthletes returns all athletes
 // @Summary Returns all athlete profiles and swim certificates
-// @Description All athlete profiles and swim certificates of the given trainer are returned
+// @Description All athlete profiles of the given trainer are returned together with their swim certificate and consent status. Anonymized athletes are only returned with include_anonymized.
 // @Tags Athlete Management
 // @Produce json
+// @Param include_anonymized query bool false "Also return the anonymized athletes"
 // @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
 // @Success 200 {object} AthletesResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid 'include_anonymized' query parameter"
 // @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
 // @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
 // @Router /v1/athlete/get-all [get]
@@ -33,13 +36,30 @@ func GetAllAthletes(c *gin.Context) {
 	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetAllAthletes")
 	defer span.End()
 
+	// Check if the anonymized athletes should be included
+	includeAnonymized := false
+	if includeAnonymizedString := c.Query("include_anonymized"); includeAnonymizedString != "" {
+		var err0 error
+		includeAnonymized, err0 = strconv.ParseBool(includeAnonymizedString)
+		if err0 != nil {
+			err0 = errors.Wrap(err0, "Invalid 'include_anonymized' query parameter")
+			endpoints.Logger.Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'include_anonymized' query parameter"})
+			return
+		}
+	}
+
 	// Get the user id from the context
 	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
 
 	// Get all athletes for the given trainer
 	var athletes []databaseUtils.Athlete
 	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
-		err := tx.Where("trainer_email = ?", strings.ToLower(trainerEmail)).Find(&athletes).Error
+		query := tx.Where("trainer_email = ?", strings.ToLower(trainerEmail))
+		if !includeAnonymized {
+			query = query.Where("anonymized_at IS NULL")
+		}
+		err := query.Find(&athletes).Error
 		err = errors.Wrap(err, "Failed to get the athletes")
 		return err
 	})
@@ -106,6 +126,18 @@ func GetAllAthletes(c *gin.Context) {
 
 	// }
 
+	// Get the consents of all athletes
+	athleteIds := make([]uint, len(athletes))
+	for idx, athlete := range athletes {
+		athleteIds[idx] = athlete.ID
+	}
+	consentScopes, err3 := GetActiveConsentScopes(ctx, athleteIds)
+	if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the consents"})
+		return
+	}
+
 	// Translate athletes to response type
 	athletesResponse := make([]AthleteBodyWithId, len(athletes))
 	for idx, athlete := range athletes {
@@ -116,7 +148,7 @@ func GetAllAthletes(c *gin.Context) {
 				sc_flag = true
 			}
 		}
-		athleteBody, err2 := translateAthleteToResponse(ctx, athlete, sc_flag)
+		athleteBody, err2 := translateAthleteToResponse(ctx, athlete, sc_flag, consentScopes[athlete.ID])
 		if err2 != nil {
 			err2 = errors.Wrap(err2, "Failed to translate the athlete")
 			endpoints.Logger.Error(ctx, err2)
@@ -86,8 +86,16 @@ func GetAthleteByID(c *gin.Context) {
 		flag = true
 	}
 
+	// Get the consents of the athlete
+	consentScopes, errConsent := GetActiveConsentScopes(ctx, []uint{athlete.ID})
+	if errConsent != nil {
+		endpoints.Logger.Error(ctx, errConsent)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the consents"})
+		return
+	}
+
 	// Translate athlete to response type
-	athleteBody, err3 := translateAthleteToResponse(ctx, *athlete, flag)
+	athleteBody, err3 := translateAthleteToResponse(ctx, *athlete, flag, consentScopes[athlete.ID])
 	if err3 != nil {
 		err3 = errors.Wrap(err3, "Failed to translate the athlete")
 		endpoints.Logger.Error(ctx, err3)
@@ -0,0 +1,96 @@
+package athleteManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type DuplicateAthletesResponse struct {
+	Message    string                 `json:"message" example:"Request successful"`
+	Duplicates []DuplicateAthletePair `json:"duplicates"`
+}
+
+// GetDuplicateAthletes returns likely duplicate athletes
+// @Summary Returns likely duplicate athlete profiles
+// @Description Compares all athlete profiles of the given trainer and returns pairs that are likely the same person. Names are compared fuzzy (e.g. "Müller" and "Mueller" or small typos).
+// @Tags Athlete Management
+// @Produce json
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} DuplicateAthletesResponse "Request successful"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/athlete/get-duplicates [get]
+func GetDuplicateAthletes(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetDuplicateAthletes")
+	defer span.End()
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Search for likely duplicates
+	duplicates, err1 := findDuplicateAthletes(ctx, trainerEmail)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to find duplicate athletes")
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to find duplicate athletes"})
+		return
+	}
+
+	// Get the swim certificate status of all affected athletes
+	var athleteIds []uint
+	for _, duplicate := range duplicates {
+		athleteIds = append(athleteIds, duplicate.Athlete.ID, duplicate.Duplicate.ID)
+	}
+	withSwimCertificate, err2 := getAthleteIdsWithSwimCertificate(ctx, athleteIds)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the swim certs"})
+		return
+	}
+
+	// Get the consents of all affected athletes
+	consentScopes, err3 := GetActiveConsentScopes(ctx, athleteIds)
+	if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the consents"})
+		return
+	}
+
+	// Translate the duplicates to the response type
+	duplicatePairs := make([]DuplicateAthletePair, len(duplicates))
+	for idx, duplicate := range duplicates {
+		athleteBody, errA := translateAthleteToResponse(ctx, duplicate.Athlete, withSwimCertificate[duplicate.Athlete.ID], consentScopes[duplicate.Athlete.ID])
+		if errA != nil {
+			errA = errors.Wrap(errA, "Failed to translate the athlete")
+			endpoints.Logger.Error(ctx, errA)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Internal server error"})
+			return
+		}
+		duplicateBody, errB := translateAthleteToResponse(ctx, duplicate.Duplicate, withSwimCertificate[duplicate.Duplicate.ID], consentScopes[duplicate.Duplicate.ID])
+		if errB != nil {
+			errB = errors.Wrap(errB, "Failed to translate the athlete")
+			endpoints.Logger.Error(ctx, errB)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Internal server error"})
+			return
+		}
+
+		duplicatePairs[idx] = DuplicateAthletePair{
+			Athlete:      *athleteBody,
+			Duplicate:    *duplicateBody,
+			Reason:       duplicate.Reason,
+			NameDistance: duplicate.NameDistance,
+		}
+	}
+
+	c.JSON(
+		http.StatusOK,
+		DuplicateAthletesResponse{
+			Message:    "Request successful",
+			Duplicates: duplicatePairs,
+		},
+	)
+}
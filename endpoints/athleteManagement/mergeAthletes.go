@@ -0,0 +1,62 @@
+package athleteManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// MergeAthletes merges a duplicate athlete into the surviving athlete
+// @Summary Merges two athlete profiles
+// @Description Moves all performance entries, swim certificates, guardian contacts, consents, historical and earned badges of the duplicate athlete to the surviving athlete and deletes the duplicate afterward.
+// @Tags Athlete Management
+// @Accept json
+// @Produce json
+// @Param Athletes body MergeAthletesBody true "Ids of the surviving and the duplicate athlete"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Merge successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete could not be found for this trainer"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/athlete/merge [post]
+func MergeAthletes(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "MergeAthletes")
+	defer span.End()
+
+	// Bind JSON body to struct
+	var body MergeAthletesBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Merge the athletes
+	err1 := MergeDuplicateAthlete(ctx, body.SurvivingAthleteId, body.DuplicateAthleteId, trainerEmail)
+	if errors.Is(err1, SameAthleteError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err1.Error()})
+		return
+	} else if errors.Is(err1, gorm.ErrRecordNotFound) {
+		err1 = errors.Wrap(err1, "Athlete not found")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to merge the athletes")
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to merge the athletes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "Merge successful"})
+}
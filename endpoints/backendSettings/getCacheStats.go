@@ -0,0 +1,35 @@
+package backendSettings
+
+import (
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type CacheStatsResponse struct {
+	Message      string             `json:"message" example:"Request successful"`
+	RulesetCache rulesetCache.Stats `json:"ruleset_cache"`
+}
+
+// GetCacheStats returns the metrics of the ruleset cache
+// @Summary Returns the metrics of the ruleset cache
+// @Description Returns the hits, misses and hit rates of the cached exercises, rulesets and exercise goals since the start of the server, as well as the number of loads and invalidations.
+// @Tags Settings
+// @Produce json
+// @Param Authorization  header  string  false  "Settings access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} CacheStatsResponse "Request successful"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Router /v1/backendSettings/cache-stats [get]
+func GetCacheStats(c *gin.Context) {
+	_, span := endpoints.Tracer.Start(c.Request.Context(), "GetCacheStats")
+	defer span.End()
+
+	c.JSON(
+		http.StatusOK,
+		CacheStatsResponse{
+			Message:      "Request successful",
+			RulesetCache: rulesetCache.GetStats(),
+		},
+	)
+}
@@ -0,0 +1,105 @@
+package badgeManagement
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/pdfHelper"
+)
+
+// Layout of the annual report in points
+const (
+	reportMargin     = 50
+	reportLineHeight = 18
+	reportFontSize   = 10
+)
+
+// reportColumns are the headings of the report table and the x positions of the columns
+var reportColumns = []struct {
+	heading string
+	x       float64
+}{
+	{"Altersklasse", reportMargin},
+	{"Geschlecht", 150},
+	{"Bronze", 270},
+	{"Silber", 340},
+	{"Gold", 410},
+	{"Gesamt", 480},
+}
+
+var reportSexLabels = map[string]string{"m": "männlich", "f": "weiblich", "d": "divers"}
+
+// writeAnnualReportPdf writes the report as pdf document with a summary and a table per age class and sex
+func writeAnnualReportPdf(writer io.Writer, report *AnnualReportBody) error {
+	document := pdfHelper.New()
+	document.AddPage()
+
+	y := float64(reportMargin + 20)
+	document.Text(reportMargin, y, 16, true, "Sportabzeichen-Jahresmeldung "+strconv.Itoa(report.Year))
+	y += reportLineHeight
+	document.Text(reportMargin, y, 9, false, "Erstellt am "+time.Now().Format("02.01.2006"))
+	y += 2 * reportLineHeight
+
+	summary := []string{
+		"Sportlerinnen und Sportler mit Leistungen: " + strconv.Itoa(report.Athletes),
+		"Abgelegte Sportabzeichen: " + strconv.Itoa(report.Badges) + " (Bronze " + strconv.Itoa(report.Bronze) +
+			", Silber " + strconv.Itoa(report.Silver) + ", Gold " + strconv.Itoa(report.Gold) + ")",
+		"Alle Disziplinen erfüllt, aber ohne gültigen Schwimmnachweis: " + strconv.Itoa(report.MissingSwimCertificate),
+	}
+	for _, anniversary := range report.Anniversaries {
+		summary = append(summary, "Jubiläumsabzeichen ("+strconv.Itoa(anniversary.RepeatCount)+". Sportabzeichen): "+strconv.Itoa(anniversary.Badges))
+	}
+	if report.Unassigned > 0 {
+		summary = append(summary, "Sportabzeichen außerhalb der Altersklassen: "+strconv.Itoa(report.Unassigned))
+	}
+	for _, line := range summary {
+		document.Text(reportMargin, y, reportFontSize, false, line)
+		y += reportLineHeight
+	}
+	y += reportLineHeight
+
+	writeHeading := func() {
+		for _, column := range reportColumns {
+			document.Text(column.x, y, reportFontSize, true, column.heading)
+		}
+		document.Line(reportMargin, y+5, pdfHelper.PageWidth-reportMargin, y+5)
+		y += reportLineHeight
+	}
+	writeHeading()
+
+	for _, row := range report.Rows {
+		// Continue the table on a new page
+		if y > pdfHelper.PageHeight-reportMargin-reportLineHeight {
+			document.AddPage()
+			y = reportMargin + 20
+			writeHeading()
+		}
+
+		values := []string{
+			row.AgeBand,
+			reportSexLabels[row.Sex],
+			strconv.Itoa(row.Bronze),
+			strconv.Itoa(row.Silver),
+			strconv.Itoa(row.Gold),
+			strconv.Itoa(row.Total),
+		}
+		for idx, column := range reportColumns {
+			document.Text(column.x, y, reportFontSize, false, values[idx])
+		}
+		y += reportLineHeight
+	}
+
+	if y > pdfHelper.PageHeight-reportMargin-reportLineHeight {
+		document.AddPage()
+		y = reportMargin + 20
+		writeHeading()
+	}
+	document.Line(reportMargin, y-reportLineHeight+5, pdfHelper.PageWidth-reportMargin, y-reportLineHeight+5)
+	totals := []string{"Gesamt", "", strconv.Itoa(report.Bronze), strconv.Itoa(report.Silver), strconv.Itoa(report.Gold), strconv.Itoa(report.Badges)}
+	for idx, column := range reportColumns {
+		document.Text(column.x, y, reportFontSize, true, totals[idx])
+	}
+
+	return document.Write(writer)
+}
@@ -0,0 +1,147 @@
+package badgeManagement
+
+type AnnualReportRow struct {
+	AgeBand string `json:"age_band" example:"10-11"`
+	FromAge uint   `json:"from_age" example:"10"`
+	ToAge   uint   `json:"to_age" example:"11"`
+	Sex     string `json:"sex" example:"<m|f|d>"`
+	Bronze  int    `json:"bronze" example:"3"`
+	Silver  int    `json:"silver" example:"5"`
+	Gold    int    `json:"gold" example:"2"`
+	Total   int    `json:"total" example:"10"`
+}
+
+type AnniversaryCount struct {
+	RepeatCount int `json:"repeat_count" example:"10"`
+	Badges      int `json:"badges" example:"2"`
+}
+
+type AnnualReportBody struct {
+	Year                   int                `json:"year" example:"2025"`
+	Athletes               int                `json:"athletes" example:"42"` // Athletes with at least one performance in the year
+	Badges                 int                `json:"badges" example:"30"`
+	Bronze                 int                `json:"bronze" example:"10"`
+	Silver                 int                `json:"silver" example:"12"`
+	Gold                   int                `json:"gold" example:"8"`
+	MissingSwimCertificate int                `json:"missing_swim_certificate" example:"2"` // Athletes with medals in all disciplines, but without a valid swim certificate
+	Unassigned             int                `json:"unassigned" example:"0"`               // Badges of athletes outside the age classes
+	Anniversaries          []AnniversaryCount `json:"anniversaries"`                        // Anniversary badges (5th, 10th, ...) earned in the year
+	Rows                   []AnnualReportRow  `json:"rows"`
+}
+
+type AnnualReportResponse struct {
+	Message string           `json:"message" example:"Request successful"`
+	Report  AnnualReportBody `json:"report"`
+}
+
+type BadgeOrderRequest struct {
+	Year       int    `json:"year,omitempty" example:"2025"`     // Defaults to the current year
+	AthleteIds []uint `json:"athlete_ids,omitempty" example:"1"` // Defaults to all athletes with a performance in the year
+}
+
+type BadgeOrderItemBody struct {
+	Item         string `json:"item" example:"<badge|certificate|repeat_number>"`
+	Category     string `json:"category" example:"<children|youth|adults>"`
+	Level        string `json:"level" example:"<bronze|silver|gold>"`
+	RepeatNumber int    `json:"repeat_number,omitempty" example:"5"` // Only set for repeat numbers
+	Quantity     int    `json:"quantity" example:"3"`
+}
+
+type BadgeOrderAthlete struct {
+	AthleteId    uint   `json:"athlete_id" example:"1"`
+	FirstName    string `json:"first_name" example:"Bob"`
+	LastName     string `json:"last_name" example:"Alice"`
+	Category     string `json:"category" example:"<children|youth|adults>"`
+	Level        string `json:"level" example:"<bronze|silver|gold>"`
+	Points       int    `json:"points" example:"9"`
+	RepeatNumber int    `json:"repeat_number" example:"5"` // Number of badges earned up to the year, including it
+	Anniversary  bool   `json:"anniversary" example:"true"`
+}
+
+type BadgeOrderBody struct {
+	Year     int                  `json:"year" example:"2025"`
+	Badges   int                  `json:"badges" example:"12"`
+	Items    []BadgeOrderItemBody `json:"items"`
+	Athletes []BadgeOrderAthlete  `json:"athletes"`
+}
+
+type BadgeOrderResponse struct {
+	Message string         `json:"message" example:"Request successful"`
+	Order   BadgeOrderBody `json:"order"`
+}
+
+type HistoricalBadgeBody struct {
+	AthleteId uint   `json:"athlete_id" example:"1"`
+	Year      int    `json:"year" example:"2015"`
+	Level     string `json:"level" example:"<bronze|silver|gold>"` // Empty if unknown
+	Note      string `json:"note" example:"Certificate of the old club"`
+}
+
+type BadgeHistoryEntry struct {
+	Year              int    `json:"year" example:"2025"`
+	Level             string `json:"level" example:"<bronze|silver|gold>"`
+	Points            int    `json:"points,omitempty" example:"9"` // Only set for badges earned with performances
+	Source            string `json:"source" example:"<performances|manual>"`
+	HistoricalBadgeId uint   `json:"historical_badge_id,omitempty" example:"1"` // Set if a historical badge was entered for the year
+	Note              string `json:"note,omitempty" example:"Certificate of the old club"`
+	RepeatCount       int    `json:"repeat_count" example:"5"` // Number of badges earned up to the year, including it
+	Anniversary       bool   `json:"anniversary" example:"true"`
+}
+
+type BadgeHistoryBody struct {
+	AthleteId uint                `json:"athlete_id" example:"1"`
+	Badges    int                 `json:"badges" example:"5"`
+	Entries   []BadgeHistoryEntry `json:"entries"`
+}
+
+type BadgeHistoryResponse struct {
+	Message string           `json:"message" example:"Request successful"`
+	History BadgeHistoryBody `json:"history"`
+}
+
+type DashboardStatusCounts struct {
+	Gold                   int `json:"gold" example:"4"`
+	Silver                 int `json:"silver" example:"6"`
+	Bronze                 int `json:"bronze" example:"3"`
+	MissingDisciplines     int `json:"missing_disciplines" example:"8"`      // Athletes with performances, but without a medal in each discipline
+	MissingSwimCertificate int `json:"missing_swim_certificate" example:"2"` // Athletes with a medal in each discipline, but without a valid swim certificate
+	NoPerformances         int `json:"no_performances" example:"5"`
+}
+
+type DashboardActivity struct {
+	PerformanceId uint   `json:"performance_id" example:"1"`
+	AthleteId     uint   `json:"athlete_id" example:"1"`
+	FirstName     string `json:"first_name" example:"Bob"`
+	LastName      string `json:"last_name" example:"Alice"`
+	ExerciseId    uint   `json:"exercise_id" example:"1"`
+	Exercise      string `json:"exercise" example:"Sprint 50m"`
+	Discipline    string `json:"discipline" example:"Schnelligkeit"`
+	Date          string `json:"date" example:"YYYY-MM-DD"`
+	Medal         string `json:"medal" example:"<bronze|silver|gold>"`
+}
+
+type DashboardCloseAthlete struct {
+	AthleteId              uint     `json:"athlete_id" example:"1"`
+	FirstName              string   `json:"first_name" example:"Bob"`
+	LastName               string   `json:"last_name" example:"Alice"`
+	Level                  string   `json:"level" example:"<bronze|silver>"` // Empty if no badge has been earned yet
+	Points                 int      `json:"points" example:"10"`
+	NextLevel              string   `json:"next_level" example:"<bronze|silver|gold>"`
+	MissingPoints          int      `json:"missing_points" example:"1"`
+	MissingDisciplines     []string `json:"missing_disciplines"`
+	MissingSwimCertificate bool     `json:"missing_swim_certificate" example:"false"`
+}
+
+type DashboardBody struct {
+	Year             int                     `json:"year" example:"2025"`
+	Athletes         int                     `json:"athletes" example:"28"`
+	Status           DashboardStatusCounts   `json:"status"`
+	LastActivity     string                  `json:"last_activity,omitempty" example:"YYYY-MM-DD"` // Date of the latest performance in the year
+	RecentActivity   []DashboardActivity     `json:"recent_activity"`
+	CloseToNextLevel []DashboardCloseAthlete `json:"close_to_next_level"`
+}
+
+type DashboardResponse struct {
+	Message   string        `json:"message" example:"Request successful"`
+	Dashboard DashboardBody `json:"dashboard"`
+}
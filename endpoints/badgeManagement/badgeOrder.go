@@ -0,0 +1,256 @@
+package badgeManagement
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/xlsxHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Items of the badge order
+const (
+	BadgeOrderItem        = "badge"
+	CertificateOrderItem  = "certificate"
+	RepeatNumberOrderItem = "repeat_number"
+)
+
+var badgeOrderItems = []string{BadgeOrderItem, CertificateOrderItem, RepeatNumberOrderItem}
+
+var badgeLevels = []string{badgeHelper.BronzeLevel, badgeHelper.SilverLevel, badgeHelper.GoldLevel}
+
+// firstBadgeYear is the first year of which medals are loaded to count the repeated badges
+const firstBadgeYear = 1900
+
+// badgeOrderHeader contains the column names of the csv and xlsx order list, which match the json keys of BadgeOrderItemBody
+var badgeOrderHeader = []string{"item", "category", "level", "repeat_number", "quantity"}
+
+// CreateBadgeOrder calculates the badges, certificates and repeat numbers to order for the earned badges of a year
+// @Summary Calculates the order list of the badges, certificates and repeat numbers of a year
+// @Description Evaluates the badges that the athletes earned in the given year and counts the badges and certificates to order per category (children up to 11, youth 12-17, adults from 18 years) and level.
+// @Description Athletes that earned the badge repeatedly additionally need a repeat number, e.g. 5 for the fifth badge. The repeat number counts the badges earned in all years up to the given one, including the historical badges.
+// @Description Without athlete ids, all athletes of the trainer with a performance in the year are included.
+// @Tags Badge Management
+// @Accept json
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param json body BadgeOrderRequest true "Year and athletes of the order"
+// @Param format query string false "json (default), csv or xlsx"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} BadgeOrderResponse "Request successful"
+// @Success 200 {file} file "CSV file or XLSX workbook"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "One or more athletes do not exist"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/badge/order [post]
+func CreateBadgeOrder(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CreateBadgeOrder")
+	defer span.End()
+
+	// Read in JSON body
+	var req BadgeOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	} else if req.Year < firstBadgeYear || req.Year > 9999 {
+		err := errors.New("Invalid year " + strconv.Itoa(req.Year))
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid year"})
+		return
+	}
+
+	// Check the requested format
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "xlsx" {
+		err := errors.New("Invalid order format " + format)
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'format' query parameter, allowed are json, csv and xlsx"})
+		return
+	}
+
+	// Remove duplicated athlete ids
+	athleteIds := make([]uint, 0, len(req.AthleteIds))
+	seen := make(map[uint]bool, len(req.AthleteIds))
+	for _, athleteId := range req.AthleteIds {
+		if !seen[athleteId] {
+			seen[athleteId] = true
+			athleteIds = append(athleteIds, athleteId)
+		}
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	order, err1 := createBadgeOrder(ctx, trainerEmail, req.Year, athleteIds)
+	if errors.Is(err1, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	} else if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the badge order"})
+		return
+	}
+
+	fileName := "sportabzeichen_bestellung_" + strconv.Itoa(req.Year)
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename="+fileName+".csv")
+		writeBadgeOrderCsv(c, order)
+	case "xlsx":
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", "attachment; filename="+fileName+".xlsx")
+		rows := make([][]xlsxHelper.Cell, len(order.Items))
+		for idx, item := range order.Items {
+			rows[idx] = []xlsxHelper.Cell{
+				xlsxHelper.String(item.Item),
+				xlsxHelper.String(item.Category),
+				xlsxHelper.String(item.Level),
+				xlsxHelper.Number(float64(item.RepeatNumber)),
+				xlsxHelper.Number(float64(item.Quantity)),
+			}
+		}
+		if err2 := xlsxHelper.Write(c.Writer, "Bestellung", badgeOrderHeader, rows); err2 != nil {
+			endpoints.Logger.Error(ctx, errors.Wrap(err2, "Failed to write the xlsx order list"))
+		}
+	default:
+		c.JSON(
+			http.StatusOK,
+			BadgeOrderResponse{
+				Message: "Request successful",
+				Order:   *order,
+			},
+		)
+	}
+}
+
+// badgeOrderKey identifies an item of the order list
+type badgeOrderKey struct {
+	item         string
+	category     string
+	level        string
+	repeatNumber int
+}
+
+// createBadgeOrder evaluates the badges of the year and counts the items to order
+func createBadgeOrder(ctx context.Context, trainerEmail string, year int, athleteIds []uint) (*BadgeOrderBody, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "CreateBadgeOrderList")
+	defer span.End()
+
+	badgeData, err1 := getBadgeData(ctx, trainerEmail, badgeDataFilter{
+		athleteIds: athleteIds,
+		activeYear: year,
+		fromYear:   firstBadgeYear,
+		toYear:     year,
+	})
+	if err1 != nil {
+		return nil, err1
+	}
+
+	order := BadgeOrderBody{
+		Year:     year,
+		Athletes: make([]BadgeOrderAthlete, 0),
+		Items:    make([]BadgeOrderItemBody, 0),
+	}
+	quantities := make(map[badgeOrderKey]int)
+	for _, data := range badgeData {
+		result := data.evaluate(year)
+		if !result.Earned() {
+			continue
+		}
+
+		age, err2 := badgeHelper.AgeInYear(data.athlete.BirthDate, year)
+		if err2 != nil {
+			return nil, err2
+		}
+		category := badgeHelper.CategoryOfAge(age)
+		repeatNumber := data.earnedBadges(year)
+
+		order.Badges++
+		order.Athletes = append(order.Athletes, BadgeOrderAthlete{
+			AthleteId:    data.athlete.ID,
+			FirstName:    data.athlete.FirstName,
+			LastName:     data.athlete.LastName,
+			Category:     category,
+			Level:        result.Level,
+			Points:       result.Points,
+			RepeatNumber: repeatNumber,
+			Anniversary:  badgeHelper.IsAnniversary(repeatNumber),
+		})
+
+		quantities[badgeOrderKey{item: BadgeOrderItem, category: category, level: result.Level}]++
+		quantities[badgeOrderKey{item: CertificateOrderItem, category: category, level: result.Level}]++
+		// The first badge has no repeat number
+		if repeatNumber > 1 {
+			quantities[badgeOrderKey{item: RepeatNumberOrderItem, category: category, level: result.Level, repeatNumber: repeatNumber}]++
+		}
+	}
+
+	for key, quantity := range quantities {
+		order.Items = append(order.Items, BadgeOrderItemBody{
+			Item:         key.item,
+			Category:     key.category,
+			Level:        key.level,
+			RepeatNumber: key.repeatNumber,
+			Quantity:     quantity,
+		})
+	}
+	sort.Slice(order.Items, func(i, j int) bool {
+		a, b := order.Items[i], order.Items[j]
+		if a.Item != b.Item {
+			return indexOf(badgeOrderItems, a.Item) < indexOf(badgeOrderItems, b.Item)
+		}
+		if a.Category != b.Category {
+			return indexOf(badgeHelper.Categories, a.Category) < indexOf(badgeHelper.Categories, b.Category)
+		}
+		if a.Level != b.Level {
+			return indexOf(badgeLevels, a.Level) < indexOf(badgeLevels, b.Level)
+		}
+		return a.RepeatNumber < b.RepeatNumber
+	})
+
+	return &order, nil
+}
+
+// indexOf returns the position of the value in the list, or -1 if it is missing
+func indexOf(list []string, value string) int {
+	for idx, entry := range list {
+		if entry == value {
+			return idx
+		}
+	}
+	return -1
+}
+
+// writeBadgeOrderCsv writes the items of the order list as csv file
+func writeBadgeOrderCsv(c *gin.Context, order *BadgeOrderBody) {
+	w := csv.NewWriter(c.Writer)
+	w.Comma = ';'
+	defer w.Flush()
+
+	_ = w.Write(badgeOrderHeader)
+	for _, item := range order.Items {
+		repeatNumber := ""
+		if item.RepeatNumber > 0 {
+			repeatNumber = strconv.Itoa(item.RepeatNumber)
+		}
+		_ = w.Write([]string{item.Item, item.Category, item.Level, repeatNumber, strconv.Itoa(item.Quantity)})
+	}
+}
@@ -0,0 +1,460 @@
+package badgeManagement
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sources of a badge in the history
+const (
+	PerformancesBadgeSource = "performances"
+	ManualBadgeSource       = "manual"
+)
+
+var (
+	InvalidBadgeLevelError = errors.New("Level needs to be <bronze|silver|gold> or empty if unknown")
+	InvalidBadgeYearError  = errors.New("Year needs to be between 1900 and the current year")
+	BadgeExistsError       = errors.New("A historical badge of this year already exists")
+)
+
+// athleteBadgeData contains the stored data of an athlete that decides the badges
+type athleteBadgeData struct {
+	athlete              databaseUtils.Athlete
+	medals               map[int]map[string]string // Year -> best medal per discipline
+	swimCertificateDates []time.Time
+	historicalBadges     map[int]databaseUtils.HistoricalBadge // Year -> manually entered badge
+	recordedBadges       map[int]databaseUtils.EarnedBadge     // Year -> recorded badge earned with performances
+}
+
+// evaluate checks if the athlete has earned the badge of the given year
+func (data athleteBadgeData) evaluate(year int) badgeHelper.Result {
+	hasSwimCertificate := false
+	for _, certificateDate := range data.swimCertificateDates {
+		if badgeHelper.IsSwimCertificateValid(certificateDate, data.athlete.BirthDate, year) {
+			hasSwimCertificate = true
+			break
+		}
+	}
+	return badgeHelper.Evaluate(data.medals[year], hasSwimCertificate)
+}
+
+// badgeHistory returns the badges that the athlete has earned up to the given year, including it, ordered by year.
+// The history is built from the recorded badges, so it is kept when the swim certificates are deleted.
+// A recorded badge takes precedence over a historical badge of the same year, so every year counts once.
+func (data athleteBadgeData) badgeHistory(upToYear int) []BadgeHistoryEntry {
+	uniqueYears := make(map[int]bool, len(data.recordedBadges)+len(data.historicalBadges))
+	for year := range data.recordedBadges {
+		uniqueYears[year] = year <= upToYear
+	}
+	for year := range data.historicalBadges {
+		uniqueYears[year] = year <= upToYear
+	}
+	years := make([]int, 0, len(uniqueYears))
+	for year, included := range uniqueYears {
+		if included {
+			years = append(years, year)
+		}
+	}
+	sort.Ints(years)
+
+	history := make([]BadgeHistoryEntry, 0, len(years))
+	for _, year := range years {
+		historicalBadge := data.historicalBadges[year]
+		entry := BadgeHistoryEntry{
+			Year:              year,
+			HistoricalBadgeId: historicalBadge.ID,
+			Note:              historicalBadge.Note,
+		}
+		if earnedBadge, isEarned := data.recordedBadges[year]; isEarned {
+			entry.Level = earnedBadge.Level
+			entry.Points = earnedBadge.Points
+			entry.Source = PerformancesBadgeSource
+		} else {
+			entry.Level = historicalBadge.Level
+			entry.Source = ManualBadgeSource
+		}
+		entry.RepeatCount = len(history) + 1
+		entry.Anniversary = badgeHelper.IsAnniversary(entry.RepeatCount)
+		history = append(history, entry)
+	}
+	return history
+}
+
+// earnedBadges counts the badges that the athlete has earned up to the given year, including it
+func (data athleteBadgeData) earnedBadges(upToYear int) int {
+	return len(data.badgeHistory(upToYear))
+}
+
+// badgeDataFilter selects the athletes and the years of the loaded badge data
+type badgeDataFilter struct {
+	athleteIds      []uint // Athletes to load, if empty all athletes of the trainer with a performance in the active year
+	activeYear      int
+	includeInactive bool // Also load the athletes without a performance in the active year, except the anonymized ones
+	fromYear        int  // First year of the loaded medals
+	toYear          int  // Last year of the loaded medals
+}
+
+// getBadgeData returns the badge data (medals, swim certificates, historical and recorded badges) of the trainer's athletes selected by the filter, ordered by name.
+// The recorded badges of the loaded years are brought up to date with the loaded data, but only in memory.
+// Throws: gorm.ErrRecordNotFound if one of the given athletes does not exist
+func getBadgeData(ctx context.Context, trainerEmail string, filter badgeDataFilter) ([]athleteBadgeData, error) {
+	badgeData, err1 := loadBadgeData(ctx, trainerEmail, filter)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	updateRecordedBadges(badgeData, filter.fromYear, filter.toYear)
+
+	return badgeData, nil
+}
+
+// loadBadgeData loads the badge data of the athletes selected by the filter, with the recorded badges as stored in the database.
+// If no trainer is given, the athletes of all trainers are loaded.
+// Throws: gorm.ErrRecordNotFound if one of the given athletes does not exist
+func loadBadgeData(ctx context.Context, trainerEmail string, filter badgeDataFilter) ([]athleteBadgeData, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetBadgeDataFromDB")
+	defer span.End()
+
+	type disciplineMedal struct {
+		AthleteId      uint
+		Year           int
+		DisciplineName string
+		Points         int
+	}
+
+	var athletes []databaseUtils.Athlete
+	var medals []disciplineMedal
+	var certificates []databaseUtils.SwimCertificate
+	var historicalBadges []databaseUtils.HistoricalBadge
+	var recordedBadges []databaseUtils.EarnedBadge
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		query := tx.Model(&databaseUtils.Athlete{})
+		if trainerEmail != "" {
+			query = query.Where("trainer_email = ?", strings.ToLower(trainerEmail))
+		}
+		if len(filter.athleteIds) > 0 {
+			query = query.Where("id IN ?", filter.athleteIds)
+		} else if filter.includeInactive {
+			query = query.Where("(athletes.anonymized_at IS NULL OR EXISTS (SELECT 1 FROM performances WHERE performances.athlete_id = athletes.id AND "+
+				"performances.date >= ? AND performances.date <= ?))",
+				fmt.Sprintf("%04d-01-01", filter.activeYear), fmt.Sprintf("%04d-12-31", filter.activeYear))
+		} else {
+			query = query.Where("EXISTS (SELECT 1 FROM performances WHERE performances.athlete_id = athletes.id AND "+
+				"performances.date >= ? AND performances.date <= ?)",
+				fmt.Sprintf("%04d-01-01", filter.activeYear), fmt.Sprintf("%04d-12-31", filter.activeYear))
+		}
+		err := query.Order("last_name, first_name, id").
+			Find(&athletes).
+			Error
+		if err != nil {
+			return err
+		}
+		if len(athletes) != len(filter.athleteIds) && len(filter.athleteIds) > 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if len(athletes) == 0 {
+			return nil
+		}
+
+		athleteIds := make([]uint, len(athletes))
+		for idx, athlete := range athletes {
+			athleteIds[idx] = athlete.ID
+		}
+
+		// Best medal of every athlete per year and discipline
+		err = tx.Model(&databaseUtils.Performance{}).
+			Select("performances.athlete_id, CAST(EXTRACT(YEAR FROM performances.date) AS INTEGER) AS year, exercises.discipline_name, "+
+				"MAX(CASE performances.medal WHEN 'gold' THEN 3 WHEN 'silver' THEN 2 WHEN 'bronze' THEN 1 ELSE 0 END) AS points").
+			Joins("JOIN exercises ON performances.exercise_id = exercises.id").
+			Where("performances.athlete_id IN ? AND performances.date >= ? AND performances.date <= ?",
+				athleteIds, fmt.Sprintf("%04d-01-01", filter.fromYear), fmt.Sprintf("%04d-12-31", filter.toYear)).
+			Group("performances.athlete_id, year, exercises.discipline_name").
+			Scan(&medals).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&databaseUtils.SwimCertificate{}).
+			Where("athlete_id IN ?", athleteIds).
+			Order("date DESC").
+			Find(&certificates).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&databaseUtils.HistoricalBadge{}).
+			Where("athlete_id IN ? AND year >= ? AND year <= ?", athleteIds, filter.fromYear, filter.toYear).
+			Find(&historicalBadges).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&databaseUtils.EarnedBadge{}).
+			Where("athlete_id IN ? AND year >= ? AND year <= ?", athleteIds, filter.fromYear, filter.toYear).
+			Find(&recordedBadges).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the badge data")
+		return nil, err1
+	}
+
+	badgeData := make([]athleteBadgeData, len(athletes))
+	indexOfAthletes := make(map[uint]int, len(athletes))
+	for idx, athlete := range athletes {
+		badgeData[idx] = athleteBadgeData{
+			athlete:          athlete,
+			medals:           make(map[int]map[string]string),
+			historicalBadges: make(map[int]databaseUtils.HistoricalBadge),
+			recordedBadges:   make(map[int]databaseUtils.EarnedBadge),
+		}
+		indexOfAthletes[athlete.ID] = idx
+	}
+	for _, medal := range medals {
+		data := &badgeData[indexOfAthletes[medal.AthleteId]]
+		if data.medals[medal.Year] == nil {
+			data.medals[medal.Year] = make(map[string]string)
+		}
+		data.medals[medal.Year][medal.DisciplineName] = badgeHelper.MedalOfPoints(medal.Points)
+	}
+	for _, certificate := range certificates {
+		data := &badgeData[indexOfAthletes[certificate.AthleteId]]
+		data.swimCertificateDates = append(data.swimCertificateDates, certificate.Date)
+	}
+	for _, historicalBadge := range historicalBadges {
+		badgeData[indexOfAthletes[historicalBadge.AthleteId]].historicalBadges[historicalBadge.Year] = historicalBadge
+	}
+	for _, recordedBadge := range recordedBadges {
+		badgeData[indexOfAthletes[recordedBadge.AthleteId]].recordedBadges[recordedBadge.Year] = recordedBadge
+	}
+
+	return badgeData, nil
+}
+
+// updateRecordedBadges brings the recorded badges of the given years up to date with the performances and swim certificates
+// and returns the badges that have to be saved and the ids of the badges that have to be deleted.
+// A badge that is not earned anymore only because of the swim certificate stays recorded, since the certificates are
+// deleted by the retention policies and the anonymization. If the performances do not reach all disciplines anymore,
+// they have been changed by the trainer and the recorded badge is removed.
+func updateRecordedBadges(badgeData []athleteBadgeData, fromYear int, toYear int) ([]databaseUtils.EarnedBadge, []uint) {
+	var changedBadges []databaseUtils.EarnedBadge
+	var removedBadgeIds []uint
+	for _, data := range badgeData {
+		for year := fromYear; year <= toYear; year++ {
+			earnedBadge, isRecorded := data.recordedBadges[year]
+			if _, hasMedals := data.medals[year]; !hasMedals && !isRecorded {
+				continue
+			}
+
+			result := data.evaluate(year)
+			switch {
+			case result.Earned():
+				if isRecorded && earnedBadge.Level == result.Level && earnedBadge.Points == result.Points {
+					continue
+				}
+				earnedBadge.Year = year
+				earnedBadge.Level = result.Level
+				earnedBadge.Points = result.Points
+				earnedBadge.AthleteId = data.athlete.ID
+				data.recordedBadges[year] = earnedBadge
+
+				// The recorded badge is updated by the unique index of the athlete and the year
+				earnedBadge.ID = 0
+				changedBadges = append(changedBadges, earnedBadge)
+			case isRecorded && len(result.MissingDisciplines) > 0:
+				delete(data.recordedBadges, year)
+				removedBadgeIds = append(removedBadgeIds, earnedBadge.ID)
+			}
+		}
+	}
+
+	return changedBadges, removedBadgeIds
+}
+
+// RecordEarnedBadges records all badges that the given athletes of the trainer have earned with performances.
+// It needs to be called whenever the performances or swim certificates of the athletes have changed and before the
+// swim certificates are deleted, so that the badges are kept. If no trainer is given, the athletes are not checked against a trainer.
+// Throws: gorm.ErrRecordNotFound if one of the athletes does not exist
+func RecordEarnedBadges(ctx context.Context, trainerEmail string, athleteIds []uint) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "RecordEarnedBadges")
+	defer span.End()
+
+	if len(athleteIds) == 0 {
+		return nil
+	}
+
+	filter := badgeDataFilter{
+		athleteIds: athleteIds,
+		fromYear:   firstBadgeYear,
+		toYear:     time.Now().Year(),
+	}
+	badgeData, err1 := loadBadgeData(ctx, trainerEmail, filter)
+	if err1 != nil {
+		return err1
+	}
+
+	changedBadges, removedBadgeIds := updateRecordedBadges(badgeData, filter.fromYear, filter.toYear)
+	if len(changedBadges) == 0 && len(removedBadgeIds) == 0 {
+		return nil
+	}
+
+	err2 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		if len(changedBadges) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "athlete_id"}, {Name: "year"}},
+				DoUpdates: clause.AssignmentColumns([]string{"level", "points", "updated_at"}),
+			}).
+				Create(&changedBadges).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(removedBadgeIds) > 0 {
+			return tx.Delete(&databaseUtils.EarnedBadge{}, "id IN ?", removedBadgeIds).Error
+		}
+		return nil
+	})
+	if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to record the earned badges")
+		return err2
+	}
+
+	return nil
+}
+
+// recordBatchSize is the number of athletes whose badges are recorded at once by the background job
+const recordBatchSize = 100
+
+// RecordAllEarnedBadges records the earned badges of all athletes with performances.
+// It runs as a background job, so that the badges are also recorded after changes that do not record them directly (e.g. merged athletes).
+func RecordAllEarnedBadges(ctx context.Context) (string, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "RecordAllEarnedBadges")
+	defer span.End()
+
+	var athleteIds []uint
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.Performance{}).
+			Distinct("athlete_id").
+			Order("athlete_id").
+			Pluck("athlete_id", &athleteIds).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the athletes with performances")
+		return "", err1
+	}
+
+	for start := 0; start < len(athleteIds); start += recordBatchSize {
+		end := min(start+recordBatchSize, len(athleteIds))
+		// An athlete that has been erased in the meantime is recorded with the next run
+		err2 := RecordEarnedBadges(ctx, "", athleteIds[start:end])
+		if err2 != nil && !errors.Is(err2, gorm.ErrRecordNotFound) {
+			return "", err2
+		}
+	}
+
+	return fmt.Sprintf("Earned badges of %d athletes recorded", len(athleteIds)), nil
+}
+
+// getAgeBands returns the age classes of the rulesets of the given year, or the standard age classes if there are none.
+// Overlapping age classes of different exercises are skipped.
+func getAgeBands(ctx context.Context, year int) ([]badgeHelper.AgeBand, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetAgeBands")
+	defer span.End()
+
+	exercises, err1 := rulesetCache.GetExercises(ctx)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	unique := make(map[badgeHelper.AgeBand]bool)
+	for _, exercise := range exercises {
+		goals, err2 := rulesetCache.GetExerciseGoals(ctx, exercise.ID, year)
+		if err2 != nil {
+			return nil, err2
+		}
+		for _, goal := range goals {
+			unique[badgeHelper.AgeBand{FromAge: goal.FromAge, ToAge: goal.ToAge}] = true
+		}
+	}
+	if len(unique) == 0 {
+		return badgeHelper.StandardAgeBands, nil
+	}
+
+	bands := make([]badgeHelper.AgeBand, 0, len(unique))
+	for band := range unique {
+		bands = append(bands, band)
+	}
+	sort.Slice(bands, func(i, j int) bool {
+		if bands[i].FromAge != bands[j].FromAge {
+			return bands[i].FromAge < bands[j].FromAge
+		}
+		return bands[i].ToAge < bands[j].ToAge
+	})
+
+	disjoint := bands[:1]
+	for _, band := range bands[1:] {
+		if band.FromAge > disjoint[len(disjoint)-1].ToAge {
+			disjoint = append(disjoint, band)
+		}
+	}
+	return disjoint, nil
+}
+
+// validateHistoricalBadge checks if the year and the level of a historical badge are valid
+// Throws: InvalidBadgeYearError, InvalidBadgeLevelError
+func validateHistoricalBadge(ctx context.Context, historicalBadge *databaseUtils.HistoricalBadge) error {
+	_, span := endpoints.Tracer.Start(ctx, "ValidateHistoricalBadge")
+	defer span.End()
+
+	if historicalBadge.Year < firstBadgeYear || historicalBadge.Year > time.Now().Year() {
+		return InvalidBadgeYearError
+	}
+
+	historicalBadge.Level = strings.ToLower(strings.TrimSpace(historicalBadge.Level))
+	if historicalBadge.Level != "" && !badgeHelper.IsLevel(historicalBadge.Level) {
+		return InvalidBadgeLevelError
+	}
+
+	historicalBadge.Note = strings.TrimSpace(historicalBadge.Note)
+	return nil
+}
+
+// historicalBadgeExistsForTrainer checks if a historical badge with the given id exists for an athlete of the given trainer
+func historicalBadgeExistsForTrainer(ctx context.Context, historicalBadgeId uint, trainerEmail string) (bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "HistoricalBadgeExistsForTrainer")
+	defer span.End()
+
+	var badgeCount int64
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.HistoricalBadge{}).
+			Joins("INNER JOIN athletes ON historical_badges.athlete_id = athletes.id").
+			Where("historical_badges.id = ? AND athletes.trainer_email = ?", historicalBadgeId, strings.ToLower(trainerEmail)).
+			Count(&badgeCount).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to check if the historical badge exists")
+		return false, err1
+	}
+
+	return badgeCount > 0, nil
+}
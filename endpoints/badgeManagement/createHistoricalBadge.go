@@ -0,0 +1,113 @@
+package badgeManagement
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// CreateHistoricalBadge records a badge that an athlete earned without performances in the system
+// @Summary Records a historical badge
+// @Description Records a badge that the athlete earned before the system was used, so that it counts for the repeat number. Only one historical badge can be recorded per year.
+// @Description If the athlete also earned the badge of the year with stored performances, the year is only counted once.
+// @Tags Badge Management
+// @Accept json
+// @Produce json
+// @Param Badge body HistoricalBadgeBody true "Details of the historical badge"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 201 {object} endpoints.SuccessResponse "Creation successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete could not be found for this trainer"
+// @Failure 409 {object} endpoints.ErrorResponse "A historical badge of this year already exists"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/badge/history/create [post]
+func CreateHistoricalBadge(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CreateHistoricalBadge")
+	defer span.End()
+
+	// Bind JSON body to struct
+	var body HistoricalBadgeBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Translate into a database object
+	historicalBadge := databaseUtils.HistoricalBadge{
+		Year:      body.Year,
+		Level:     body.Level,
+		Note:      body.Note,
+		AthleteId: body.AthleteId,
+	}
+
+	// Validate the historical badge
+	err1 := validateHistoricalBadge(ctx, &historicalBadge)
+	if err1 != nil {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err1.Error()})
+		return
+	}
+
+	// Check if the athlete exists and is assigned to the given trainer
+	exists, err2 := athleteManagement.AthleteExistsForTrainer(ctx, body.AthleteId, trainerEmail)
+	if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to check if the athlete exists and is assigned to the trainer")
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the athlete exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, fmt.Sprintf("Athlete with id %d does not exist", body.AthleteId))
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete does not exist"})
+		return
+	}
+
+	// Create the historical badge in the database if the year is still free
+	err3 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		var badgeCount int64
+		err := tx.Model(&databaseUtils.HistoricalBadge{}).
+			Where("athlete_id = ? AND year = ?", historicalBadge.AthleteId, historicalBadge.Year).
+			Count(&badgeCount).
+			Error
+		if err != nil {
+			return err
+		}
+		if badgeCount > 0 {
+			return BadgeExistsError
+		}
+
+		err = tx.Create(&historicalBadge).Error
+		return err
+	})
+	if errors.Is(err3, BadgeExistsError) {
+		endpoints.Logger.Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: err3.Error()})
+		return
+	} else if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to create the historical badge")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the historical badge"})
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		endpoints.SuccessResponse{
+			Message: "Creation successful",
+		},
+	)
+}
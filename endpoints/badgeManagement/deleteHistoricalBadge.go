@@ -0,0 +1,77 @@
+package badgeManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// DeleteHistoricalBadge deletes the given historical badge
+// @Summary Deletes the given historical badge
+// @Description Deletes the given historical badge. Badges earned with stored performances are not affected.
+// @Tags Badge Management
+// @Produce json
+// @Param BadgeId path int true "Delete the given historical badge"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Deletion successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Historical badge could not be found for this trainer"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/badge/history/delete/{BadgeId} [delete]
+func DeleteHistoricalBadge(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "DeleteHistoricalBadge")
+	defer span.End()
+
+	// Get the badge id from the context
+	badgeIdString := c.Param("BadgeId")
+	if badgeIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid badge ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid badge ID"})
+		return
+	}
+	badgeId, err1 := strconv.ParseUint(badgeIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse badge ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid badge ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Check if the badge exists and belongs to an athlete of the given trainer
+	exists, err2 := historicalBadgeExistsForTrainer(ctx, uint(badgeId), trainerEmail)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the historical badge exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, "Historical badge does not exist")
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Historical badge not found"})
+		return
+	}
+
+	// Delete the badge from the database
+	err3 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Delete(&databaseUtils.HistoricalBadge{}, "id = ?", badgeId).Error
+		return err
+	})
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to delete the historical badge")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to delete the historical badge"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "Deletion successful"})
+}
@@ -0,0 +1,229 @@
+package badgeManagement
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// reportSexes are the sexes of the report rows, rows of diverse athletes are only added if they earned badges
+var reportSexes = []string{"m", "f", "d"}
+
+// GetAnnualReport returns the annual report of the earned badges of the trainer's athletes
+// @Summary Returns the annual report (Jahresmeldung) of the earned badges
+// @Description Counts the badges that the athletes of the trainer earned in the given year, broken down by age class, sex and level.
+// @Description A badge is earned with at least a bronze medal in each discipline (Ausdauer, Kraft, Schnelligkeit, Koordination) and a valid swim certificate. The level results from the sum of the best medal per discipline (bronze 1, silver 2, gold 3): 4-7 bronze, 8-10 silver, 11-12 gold.
+// @Description The age class is decided by the age the athlete reaches in the year. The age classes are taken from the rulesets of the year.
+// @Description The report only contains counts and no personal data, so every athlete is counted, including the anonymized ones and minors without a consent to publish results.
+// @Description Anniversaries counts the 5th, 10th, 15th, ... badges of the year, including the historical badges of the athletes.
+// @Tags Badge Management
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param year query int false "Year of the report, defaults to the current year"
+// @Param format query string false "json (default), csv or pdf"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} AnnualReportResponse "Request successful"
+// @Success 200 {file} file "CSV file or PDF document"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/badge/annual-report [get]
+func GetAnnualReport(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetAnnualReport")
+	defer span.End()
+
+	// Get the year of the report
+	year := time.Now().Year()
+	if yearString := c.Query("year"); yearString != "" {
+		var err0 error
+		year, err0 = strconv.Atoi(yearString)
+		if err0 != nil || year < 1900 || year > 9999 {
+			err0 = errors.New("Invalid 'year' query parameter: " + yearString)
+			endpoints.Logger.Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'year' query parameter"})
+			return
+		}
+	}
+
+	// Check the requested format
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" && format != "pdf" {
+		err := errors.New("Invalid report format " + format)
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'format' query parameter, allowed are json, csv and pdf"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	report, err1 := createAnnualReport(ctx, trainerEmail, year)
+	if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the annual report"})
+		return
+	}
+
+	fileName := "jahresmeldung_" + strconv.Itoa(year)
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename="+fileName+".csv")
+		writeAnnualReportCsv(c, report)
+	case "pdf":
+		c.Header("Content-Type", "application/pdf")
+		c.Header("Content-Disposition", "attachment; filename="+fileName+".pdf")
+		if err2 := writeAnnualReportPdf(c.Writer, report); err2 != nil {
+			endpoints.Logger.Error(ctx, err2)
+		}
+	default:
+		c.JSON(
+			http.StatusOK,
+			AnnualReportResponse{
+				Message: "Request successful",
+				Report:  *report,
+			},
+		)
+	}
+}
+
+// createAnnualReport evaluates the badges of the trainer's athletes and counts them per age class, sex and level
+func createAnnualReport(ctx context.Context, trainerEmail string, year int) (*AnnualReportBody, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "CreateAnnualReport")
+	defer span.End()
+
+	badgeData, err1 := getBadgeData(ctx, trainerEmail, badgeDataFilter{activeYear: year, fromYear: firstBadgeYear, toYear: year})
+	if err1 != nil {
+		return nil, err1
+	}
+	ageBands, err2 := getAgeBands(ctx, year)
+	if err2 != nil {
+		return nil, err2
+	}
+
+	report := AnnualReportBody{
+		Year:     year,
+		Athletes: len(badgeData),
+	}
+
+	// Prepare a row for every age class and sex
+	rowIndex := make(map[badgeHelper.AgeBand]map[string]int, len(ageBands))
+	for _, band := range ageBands {
+		rowIndex[band] = make(map[string]int, len(reportSexes))
+		for _, sex := range reportSexes {
+			rowIndex[band][sex] = len(report.Rows)
+			report.Rows = append(report.Rows, AnnualReportRow{
+				AgeBand: band.Label(),
+				FromAge: band.FromAge,
+				ToAge:   band.ToAge,
+				Sex:     sex,
+			})
+		}
+	}
+
+	anniversaries := make(map[int]int)
+	for _, data := range badgeData {
+		result := data.evaluate(year)
+		if !result.Earned() {
+			if len(result.MissingDisciplines) == 0 && result.MissingSwimCertificate {
+				report.MissingSwimCertificate++
+			}
+			continue
+		}
+
+		report.Badges++
+		incrementLevel(&report.Bronze, &report.Silver, &report.Gold, result.Level)
+		if repeatCount := data.earnedBadges(year); badgeHelper.IsAnniversary(repeatCount) {
+			anniversaries[repeatCount]++
+		}
+
+		// Find the row of the athlete's age class
+		age, err3 := badgeHelper.AgeInYear(data.athlete.BirthDate, year)
+		if err3 != nil {
+			return nil, err3
+		}
+		rowIdx := -1
+		for _, band := range ageBands {
+			if idx, exists := rowIndex[band][data.athlete.Sex]; exists && band.Contains(age) {
+				rowIdx = idx
+				break
+			}
+		}
+		if rowIdx < 0 {
+			report.Unassigned++
+			continue
+		}
+
+		row := &report.Rows[rowIdx]
+		incrementLevel(&row.Bronze, &row.Silver, &row.Gold, result.Level)
+		row.Total++
+	}
+
+	// Only keep the rows of diverse athletes that earned badges
+	rows := report.Rows[:0]
+	for _, row := range report.Rows {
+		if row.Sex != "d" || row.Total > 0 {
+			rows = append(rows, row)
+		}
+	}
+	report.Rows = rows
+
+	report.Anniversaries = make([]AnniversaryCount, 0, len(anniversaries))
+	for repeatCount, badges := range anniversaries {
+		report.Anniversaries = append(report.Anniversaries, AnniversaryCount{RepeatCount: repeatCount, Badges: badges})
+	}
+	sort.Slice(report.Anniversaries, func(i, j int) bool {
+		return report.Anniversaries[i].RepeatCount < report.Anniversaries[j].RepeatCount
+	})
+
+	return &report, nil
+}
+
+// incrementLevel increments the counter of the given badge level
+func incrementLevel(bronze, silver, gold *int, level string) {
+	switch level {
+	case badgeHelper.GoldLevel:
+		*gold++
+	case badgeHelper.SilverLevel:
+		*silver++
+	case badgeHelper.BronzeLevel:
+		*bronze++
+	}
+}
+
+// writeAnnualReportCsv writes the rows of the report and a total row as csv file
+func writeAnnualReportCsv(c *gin.Context, report *AnnualReportBody) {
+	w := csv.NewWriter(c.Writer)
+	w.Comma = ';'
+	defer w.Flush()
+
+	_ = w.Write([]string{"age_band", "sex", "bronze", "silver", "gold", "total"})
+	for _, row := range report.Rows {
+		_ = w.Write([]string{
+			row.AgeBand,
+			row.Sex,
+			strconv.Itoa(row.Bronze),
+			strconv.Itoa(row.Silver),
+			strconv.Itoa(row.Gold),
+			strconv.Itoa(row.Total),
+		})
+	}
+	_ = w.Write([]string{
+		"total",
+		"",
+		strconv.Itoa(report.Bronze),
+		strconv.Itoa(report.Silver),
+		strconv.Itoa(report.Gold),
+		strconv.Itoa(report.Badges),
+	})
+}
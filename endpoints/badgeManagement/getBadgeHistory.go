@@ -0,0 +1,80 @@
+package badgeManagement
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetBadgeHistory returns the earned badges of an athlete by year
+// @Summary Returns the badge history of an athlete
+// @Description Returns the badges that the athlete earned, either with stored performances or entered as historical badge, ordered by year.
+// @Description Badges earned with performances are recorded, so they are kept when the swim certificate is deleted by the retention policies or the anonymization.
+// @Description The repeat count is the number of badges earned up to the year, including it. Every fifth badge (5th, 10th, 15th, ...) is an anniversary badge.
+// @Tags Badge Management
+// @Produce json
+// @Param AthleteId path int true "Get the badge history of the given athlete"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} BadgeHistoryResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/badge/history/get/{AthleteId} [get]
+func GetBadgeHistory(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetBadgeHistory")
+	defer span.End()
+
+	// Get the athlete id from the context
+	athleteIdString := c.Param("AthleteId")
+	if athleteIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid athlete ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid athlete ID"})
+		return
+	}
+	athleteId, err1 := strconv.ParseUint(athleteIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse athlete ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid athlete ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	currentYear := time.Now().Year()
+	badgeData, err2 := getBadgeData(ctx, trainerEmail, badgeDataFilter{
+		athleteIds: []uint{uint(athleteId)},
+		fromYear:   firstBadgeYear,
+		toYear:     currentYear,
+	})
+	if errors.Is(err2, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the badge history"})
+		return
+	}
+
+	history := badgeData[0].badgeHistory(currentYear)
+	c.JSON(
+		http.StatusOK,
+		BadgeHistoryResponse{
+			Message: "Request successful",
+			History: BadgeHistoryBody{
+				AthleteId: uint(athleteId),
+				Badges:    len(history),
+				Entries:   history,
+			},
+		},
+	)
+}
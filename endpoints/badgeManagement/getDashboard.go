@@ -0,0 +1,194 @@
+package badgeManagement
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	recentActivityLimit     = 10 // Number of the latest performances on the dashboard
+	closeToLevelPoints      = 1  // Maximum missing medal points of an athlete close to the next level
+	closeToLevelDisciplines = 1  // Maximum missing disciplines of an athlete close to the bronze badge
+)
+
+// GetDashboard returns the badge progress of the trainer's athletes in a year
+// @Summary Returns the dashboard summary of the trainer's athletes
+// @Description Counts the athletes per badge status in the given year: finished with gold, silver or bronze, missing disciplines, missing swim certificate and without performances. Anonymized athletes without performances are not counted.
+// @Description Additionally returns the latest performances and the athletes close to a higher level, which either miss one medal point for the next level, one discipline for the bronze badge or only the swim certificate for the badge of their points.
+// @Tags Badge Management
+// @Produce json
+// @Param year query int false "Year of the dashboard, defaults to the current year"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} DashboardResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid query parameters"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/badge/dashboard [get]
+func GetDashboard(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetDashboard")
+	defer span.End()
+
+	// Get the year of the dashboard
+	year := time.Now().Year()
+	if yearString := c.Query("year"); yearString != "" {
+		var err0 error
+		year, err0 = strconv.Atoi(yearString)
+		if err0 != nil || year < firstBadgeYear || year > 9999 {
+			err0 = errors.New("Invalid 'year' query parameter: " + yearString)
+			endpoints.Logger.Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'year' query parameter"})
+			return
+		}
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	badgeData, err1 := getBadgeData(ctx, trainerEmail, badgeDataFilter{
+		activeYear:      year,
+		includeInactive: true,
+		fromYear:        year,
+		toYear:          year,
+	})
+	if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the badge status"})
+		return
+	}
+
+	activities, err2 := getRecentActivity(ctx, trainerEmail, year)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the recent activity"})
+		return
+	}
+
+	dashboard := DashboardBody{
+		Year:             year,
+		Athletes:         len(badgeData),
+		RecentActivity:   activities,
+		CloseToNextLevel: make([]DashboardCloseAthlete, 0),
+	}
+	if len(activities) > 0 {
+		dashboard.LastActivity = activities[0].Date
+	}
+
+	for _, data := range badgeData {
+		if len(data.medals[year]) == 0 {
+			dashboard.Status.NoPerformances++
+			continue
+		}
+
+		result := data.evaluate(year)
+		switch {
+		case result.Level == badgeHelper.GoldLevel:
+			dashboard.Status.Gold++
+		case result.Level == badgeHelper.SilverLevel:
+			dashboard.Status.Silver++
+		case result.Level == badgeHelper.BronzeLevel:
+			dashboard.Status.Bronze++
+		case len(result.MissingDisciplines) > 0:
+			dashboard.Status.MissingDisciplines++
+		default:
+			dashboard.Status.MissingSwimCertificate++
+		}
+
+		if closeAthlete, isClose := closeToNextLevel(data.athlete, result); isClose {
+			dashboard.CloseToNextLevel = append(dashboard.CloseToNextLevel, closeAthlete)
+		}
+	}
+
+	c.JSON(
+		http.StatusOK,
+		DashboardResponse{
+			Message:   "Request successful",
+			Dashboard: dashboard,
+		},
+	)
+}
+
+// closeToNextLevel checks if the athlete misses at most one medal point for the next level, one discipline for the bronze badge
+// or only the swim certificate for the badge
+func closeToNextLevel(athlete databaseUtils.Athlete, result badgeHelper.Result) (DashboardCloseAthlete, bool) {
+	closeAthlete := DashboardCloseAthlete{
+		AthleteId:              athlete.ID,
+		FirstName:              athlete.FirstName,
+		LastName:               athlete.LastName,
+		Level:                  result.Level,
+		Points:                 result.Points,
+		MissingDisciplines:     make([]string, 0),
+		MissingSwimCertificate: result.MissingSwimCertificate,
+	}
+
+	if len(result.MissingDisciplines) > 0 {
+		if len(result.MissingDisciplines) > closeToLevelDisciplines {
+			return closeAthlete, false
+		}
+		// Every discipline adds at least one point, so the bronze badge is reached with the missing disciplines
+		closeAthlete.NextLevel = badgeHelper.BronzeLevel
+		closeAthlete.MissingDisciplines = result.MissingDisciplines
+		return closeAthlete, true
+	}
+
+	// With all disciplines, only the swim certificate is missing for the badge of the reached points
+	if result.MissingSwimCertificate {
+		closeAthlete.NextLevel = badgeHelper.LevelOfPoints(result.Points)
+		return closeAthlete, true
+	}
+
+	closeAthlete.NextLevel, closeAthlete.MissingPoints = badgeHelper.NextLevel(result.Points)
+	if closeAthlete.NextLevel == "" || closeAthlete.MissingPoints > closeToLevelPoints {
+		return closeAthlete, false
+	}
+	return closeAthlete, true
+}
+
+// getRecentActivity returns the latest performances of the trainer's athletes in the given year
+func getRecentActivity(ctx context.Context, trainerEmail string, year int) ([]DashboardActivity, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetRecentActivityFromDB")
+	defer span.End()
+
+	activities := make([]DashboardActivity, 0, recentActivityLimit)
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Performance{}).
+			Select("performances.id AS performance_id, performances.athlete_id, athletes.first_name, athletes.last_name, "+
+				"performances.exercise_id, exercises.name AS exercise, exercises.discipline_name AS discipline, performances.date, performances.medal").
+			Joins("JOIN athletes ON performances.athlete_id = athletes.id").
+			Joins("JOIN exercises ON performances.exercise_id = exercises.id").
+			Where("athletes.trainer_email = ? AND performances.date >= ? AND performances.date <= ?",
+				strings.ToLower(trainerEmail), fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year)).
+			Order("performances.date DESC, performances.created_at DESC, performances.id DESC").
+			Limit(recentActivityLimit).
+			Scan(&activities).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the recent activity")
+		return nil, err1
+	}
+
+	for idx := range activities {
+		date, err2 := formatHelper.FormatDate(activities[idx].Date)
+		if err2 != nil {
+			return nil, err2
+		}
+		activities[idx].Date = date
+	}
+
+	return activities, nil
+}
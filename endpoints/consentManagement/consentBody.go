@@ -0,0 +1,20 @@
+package consentManagement
+
+type ConsentBody struct {
+	AthleteId uint   `json:"athlete_id" example:"1"`
+	Type      string `json:"type" example:"<written|digital|verbal>"`
+	Scope     string `json:"scope" example:"<store_data|store_documents|publish_results>"`
+	GivenBy   string `json:"given_by" example:"Carol Alice (mother)"`
+	GivenAt   string `json:"given_at" example:"YYYY-MM-DD"`
+}
+
+type ConsentBodyWithId struct {
+	ConsentId uint   `json:"consent_id" example:"1"`
+	AthleteId uint   `json:"athlete_id" example:"1"`
+	Type      string `json:"type" example:"written"`
+	Scope     string `json:"scope" example:"store_documents"`
+	GivenBy   string `json:"given_by" example:"Carol Alice (mother)"`
+	GivenAt   string `json:"given_at" example:"YYYY-MM-DD"`
+	Revoked   bool   `json:"revoked" example:"false"`
+	RevokedAt string `json:"revoked_at,omitempty" example:"YYYY-MM-DD"`
+}
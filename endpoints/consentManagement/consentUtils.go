@@ -0,0 +1,119 @@
+package consentManagement
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	InvalidConsentScopeError = errors.New("Scope needs to be <store_data|store_documents|publish_results>")
+	InvalidConsentTypeError  = errors.New("Type needs to be <written|digital|verbal>")
+)
+
+// validateConsent checks if all values of a consent are valid.
+// Throws: InvalidConsentScopeError, InvalidConsentTypeError and forwards errors of the formatHelper
+func validateConsent(ctx context.Context, consent *databaseUtils.Consent) error {
+	_, span := endpoints.Tracer.Start(ctx, "ValidateConsent")
+	defer span.End()
+
+	consent.Scope = strings.ToLower(strings.TrimSpace(consent.Scope))
+	if !slices.Contains(athleteManagement.PossibleConsentScopes, consent.Scope) {
+		return InvalidConsentScopeError
+	}
+
+	consent.Type = strings.ToLower(strings.TrimSpace(consent.Type))
+	if !slices.Contains(athleteManagement.PossibleConsentTypes, consent.Type) {
+		return InvalidConsentTypeError
+	}
+
+	consent.GivenBy = strings.TrimSpace(consent.GivenBy)
+	if err := formatHelper.IsEmpty(consent.GivenBy); err != nil {
+		return errors.Wrap(err, "Given by")
+	}
+
+	if err := formatHelper.IsEmpty(consent.GivenAt); err != nil {
+		return errors.Wrap(err, "Given at")
+	} else if err = formatHelper.IsDate(consent.GivenAt); err != nil {
+		return errors.Wrap(err, "Invalid date")
+	} else if err = formatHelper.IsFuture(consent.GivenAt); err != nil {
+		return errors.Wrap(err, "Date is in the future")
+	}
+
+	return nil
+}
+
+// translateConsentToResponse converts a consent database object to the response type
+func translateConsentToResponse(consent databaseUtils.Consent) (*ConsentBodyWithId, error) {
+	givenAt, err := formatHelper.FormatDate(consent.GivenAt)
+	if err != nil {
+		return nil, err
+	}
+
+	consentBody := ConsentBodyWithId{
+		ConsentId: consent.ID,
+		AthleteId: consent.AthleteId,
+		Type:      consent.Type,
+		Scope:     consent.Scope,
+		GivenBy:   consent.GivenBy,
+		GivenAt:   givenAt,
+		Revoked:   consent.RevokedAt != nil,
+	}
+	if consent.RevokedAt != nil {
+		consentBody.RevokedAt = consent.RevokedAt.Format("2006-01-02")
+	}
+
+	return &consentBody, nil
+}
+
+// consentExistsForTrainer checks if a consent with the given id exists for an athlete of the given trainer
+func consentExistsForTrainer(ctx context.Context, consentId uint, trainerEmail string) (bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "ConsentExistsForTrainer")
+	defer span.End()
+
+	var consentCount int64
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Consent{}).
+			Joins("INNER JOIN athletes ON consents.athlete_id = athletes.id").
+			Where("consents.id = ? AND athletes.trainer_email = ?", consentId, strings.ToLower(trainerEmail)).
+			Count(&consentCount).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to check if the consent exists")
+		return false, err1
+	}
+
+	return consentCount > 0, nil
+}
+
+// GetConsentsOfAthlete returns all consents of the given athlete including the revoked ones
+func GetConsentsOfAthlete(ctx context.Context, athleteId uint) ([]databaseUtils.Consent, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetConsentsOfAthleteFromDB")
+	defer span.End()
+
+	var consents []databaseUtils.Consent
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Consent{}).
+			Where("athlete_id = ?", athleteId).
+			Order("given_at DESC").
+			Find(&consents).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the consents")
+		return nil, err1
+	}
+
+	return consents, nil
+}
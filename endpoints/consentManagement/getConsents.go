@@ -0,0 +1,95 @@
+package consentManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ConsentsResponse struct {
+	Message  string              `json:"message" example:"Request successful"`
+	Consents []ConsentBodyWithId `json:"consents"`
+}
+
+// GetConsents returns all consents of the given athlete
+// @Summary Returns all consents of an athlete
+// @Description All consent records of the given athlete are returned, including the revoked ones
+// @Tags Consent Management
+// @Produce json
+// @Param AthleteId path int true "Get the consents of the given athlete"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} ConsentsResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/consent/get/{AthleteId} [get]
+func GetConsents(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetConsents")
+	defer span.End()
+
+	// Get the athlete id from the context
+	athleteIdString := c.Param("AthleteId")
+	if athleteIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid athlete ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid athlete ID"})
+		return
+	}
+	athleteId, err1 := strconv.ParseUint(athleteIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse athlete ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid athlete ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Check if the athlete exists for the given trainer
+	exists, err2 := athleteManagement.AthleteExistsForTrainer(ctx, uint(athleteId), trainerEmail)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the athlete exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, "Athlete does not exist")
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	}
+
+	// Get the consents from the database
+	consents, err3 := GetConsentsOfAthlete(ctx, uint(athleteId))
+	if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the consents"})
+		return
+	}
+
+	// Translate the consents to the response type
+	consentBodies := make([]ConsentBodyWithId, len(consents))
+	for idx, consent := range consents {
+		consentBody, err := translateConsentToResponse(consent)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to translate the consent")
+			endpoints.Logger.Error(ctx, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Internal server error"})
+			return
+		}
+		consentBodies[idx] = *consentBody
+	}
+
+	c.JSON(
+		http.StatusOK,
+		ConsentsResponse{
+			Message:  "Request successful",
+			Consents: consentBodies,
+		},
+	)
+}
@@ -0,0 +1,81 @@
+package consentManagement
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// RevokeConsent revokes the given consent
+// @Summary Revokes a consent
+// @Description Marks the given consent as revoked. The record is kept as proof of the former consent.
+// @Tags Consent Management
+// @Produce json
+// @Param ConsentId path int true "Revoke the given consent"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Revocation successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Consent could not be found for this trainer"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/consent/revoke/{ConsentId} [put]
+func RevokeConsent(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "RevokeConsent")
+	defer span.End()
+
+	// Get the consent id from the context
+	consentIdString := c.Param("ConsentId")
+	if consentIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid consent ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid consent ID"})
+		return
+	}
+	consentId, err1 := strconv.ParseUint(consentIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse consent ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid consent ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Check if the consent exists and belongs to an athlete of the given trainer
+	exists, err2 := consentExistsForTrainer(ctx, uint(consentId), trainerEmail)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the consent exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, "Consent does not exist")
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Consent not found"})
+		return
+	}
+
+	// Mark the consent as revoked
+	err3 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Consent{}).
+			Where("id = ? AND revoked_at IS NULL", consentId).
+			Update("revoked_at", time.Now()).
+			Error
+		return err
+	})
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to revoke the consent")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to revoke the consent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "Revocation successful"})
+}
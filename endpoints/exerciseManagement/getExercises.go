@@ -9,11 +9,13 @@ import (
 
 	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/disciplineManagement"
 	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
@@ -147,7 +149,7 @@ func GetExercisesOfDiscipline(c *gin.Context) {
 			return
 		}
 		var errC error
-		age, errC = athleteManagement.CalculateAge(ctx, birthDate)
+		age, errC = badgeHelper.AgeInYear(birthDate, time.Now().Year())
 		if errC != nil {
 			errC = errors.Wrap(errC, "Failed to calculate the age of the athlete")
 			endpoints.Logger.Error(ctx, errC)
@@ -158,33 +160,26 @@ func GetExercisesOfDiscipline(c *gin.Context) {
 		sex = athlete.Sex
 	}
 
-	// Get the exercises, and optionally filter for the age and ruleset year
-	var results []ExerciseBodyWithId
-	db := DatabaseFlow.GetDB(ctx)
-	query := db.Model(&databaseUtils.Exercise{})
-
-	if athleteIdIsSet {
-		query = query.
-			Joins("JOIN exercise_rulesets ON exercise_rulesets.exercise_id = exercises.id").
-			Joins("JOIN exercise_goals ON exercise_goals.ruleset_id = exercise_rulesets.id AND exercise_goals.from_age <= ? AND exercise_goals.to_age >= ? AND exercise_goals.sex = ?", age, age, sex).
-			Select("exercises.id as exercise_id, exercises.name, exercises.unit, exercises.discipline_name, exercise_goals.description as age_specifics")
-	} else {
-		query = query.Select("exercises.id as exercise_id, exercises.name, exercises.unit, exercises.discipline_name")
-	}
-
-	if performanceDateIsSet && athleteIdIsSet {
-		query = query.
-			Where("exercise_rulesets.ruleset_year = ?", strconv.Itoa(performanceYear))
-	} else if performanceDateIsSet {
-		query = query.
-			Joins("JOIN exercise_rulesets ON exercise_rulesets.exercise_id = exercises.id").
-			Where("exercise_rulesets.ruleset_year = ?", strconv.Itoa(performanceYear))
+	// Get the ruleset years to filter for
+	var rulesetYears []int
+	if performanceDateIsSet {
+		rulesetYears = []int{performanceYear}
+	} else if athleteIdIsSet {
+		years, errD := rulesetCache.GetRulesetYears(ctx)
+		if errD != nil {
+			endpoints.Logger.Error(ctx, errD)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get exercises"})
+			return
+		}
+		for _, year := range years {
+			if yearInt, err := strconv.Atoi(year); err == nil {
+				rulesetYears = append(rulesetYears, yearInt)
+			}
+		}
 	}
 
-	err2 := query.
-		Where("discipline_name = ?", disciplineName).
-		Find(&results).
-		Error
+	// Get the exercises from the ruleset cache, and optionally filter for the age and ruleset year
+	exercises, err2 := rulesetCache.GetExercises(ctx)
 	if err2 != nil {
 		err2 = errors.Wrap(err2, "Failed to get exercises")
 		endpoints.Logger.Debug(ctx, err2)
@@ -192,6 +187,49 @@ func GetExercisesOfDiscipline(c *gin.Context) {
 		return
 	}
 
+	results := []ExerciseBodyWithId{}
+	for _, exercise := range exercises {
+		if exercise.DisciplineName != disciplineName {
+			continue
+		}
+		result := ExerciseBodyWithId{
+			ExerciseId:     exercise.ID,
+			Name:           exercise.Name,
+			Unit:           exercise.Unit,
+			DisciplineName: exercise.DisciplineName,
+		}
+		if !performanceDateIsSet && !athleteIdIsSet {
+			results = append(results, result)
+			continue
+		}
+
+		for _, year := range rulesetYears {
+			if athleteIdIsSet {
+				// Add the age specific description of the athlete's goal
+				goal, err3 := rulesetCache.GetExerciseGoal(ctx, exercise.ID, year, age, sex)
+				if errors.Is(err3, gorm.ErrRecordNotFound) {
+					continue
+				} else if err3 != nil {
+					endpoints.Logger.Error(ctx, err3)
+					c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get exercises"})
+					return
+				}
+				result.AgeSpecifics = goal.Description
+			} else {
+				hasRuleset, err3 := rulesetCache.HasRuleset(ctx, exercise.ID, year)
+				if err3 != nil {
+					endpoints.Logger.Error(ctx, err3)
+					c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get exercises"})
+					return
+				}
+				if !hasRuleset {
+					continue
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
 	c.JSON(
 		http.StatusOK,
 		ExercisesResponse{
@@ -201,15 +239,9 @@ func GetExercisesOfDiscipline(c *gin.Context) {
 	)
 }
 
+// GetExerciseByNameAndDiscipline returns the exercise with the given name of the given discipline from the ruleset cache
 func GetExerciseByNameAndDiscipline(ctx context.Context, name string, discipline string) (databaseUtils.Exercise, error) {
-	var exercise databaseUtils.Exercise
-
-	err := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
-		return tx.Model(&databaseUtils.Exercise{}).
-			Where("name = ? AND discipline_name = ?", name, discipline).
-			First(&exercise).Error
-	})
-
+	exercise, err := rulesetCache.GetExerciseByNameAndDiscipline(ctx, name, discipline)
 	if err != nil {
 		return databaseUtils.Exercise{}, errors.Wrap(err, "Exercise not found or DB error")
 	}
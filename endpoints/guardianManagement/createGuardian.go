@@ -0,0 +1,110 @@
+package guardianManagement
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// CreateGuardian creates a new guardian contact for an athlete
+// @Summary Creates a new guardian contact
+// @Description Creates a new guardian contact (e.g. a parent) for the given athlete. Either an email address or a phone number is required.
+// @Tags Guardian Management
+// @Accept json
+// @Produce json
+// @Param Guardian body GuardianBody true "Details of the guardian"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 201 {object} endpoints.SuccessResponse "Creation successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete could not be found for this trainer"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/guardian/create [post]
+func CreateGuardian(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CreateGuardian")
+	defer span.End()
+
+	// Bind JSON body to struct
+	var body GuardianBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Translate into a database object
+	guardian := databaseUtils.Guardian{
+		Name:      body.Name,
+		Email:     body.Email,
+		Phone:     body.Phone,
+		Relation:  body.Relation,
+		AthleteId: body.AthleteId,
+	}
+
+	// Validate the guardian body
+	err1 := validateGuardian(ctx, &guardian)
+	if errors.Is(err1, formatHelper.EmptyStringError) || errors.Is(err1, NoContactInformationError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err1.Error()})
+		return
+	} else if errors.Is(err1, formatHelper.InvalidEmailAddressFormatError) || errors.Is(err1, formatHelper.EmailAddressContainsNameError) || errors.Is(err1, formatHelper.EmailAddressInvalidTldError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid email address format"})
+		return
+	} else if errors.Is(err1, formatHelper.InvalidPhoneNumberError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid phone number format"})
+		return
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to validate the guardian body")
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	// Check if the athlete exists and is assigned to the given trainer
+	exists, err2 := athleteManagement.AthleteExistsForTrainer(ctx, body.AthleteId, trainerEmail)
+	if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to check if the athlete exists and is assigned to the trainer")
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the athlete exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, fmt.Sprintf("Athlete with id %d does not exist", body.AthleteId))
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete does not exist"})
+		return
+	}
+
+	// Create the guardian in the database
+	err3 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Create(&guardian).Error
+		return err
+	})
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to create the guardian")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the guardian"})
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		endpoints.SuccessResponse{
+			Message: "Creation successful",
+		},
+	)
+}
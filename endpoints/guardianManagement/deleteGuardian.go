@@ -0,0 +1,77 @@
+package guardianManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// DeleteGuardian deletes the given guardian contact
+// @Summary Deletes the given guardian contact
+// @Description Deletes the given guardian contact.
+// @Tags Guardian Management
+// @Produce json
+// @Param GuardianId path int true "Delete the given guardian"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Deletion successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Guardian could not be found for this trainer"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/guardian/delete/{GuardianId} [delete]
+func DeleteGuardian(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "DeleteGuardian")
+	defer span.End()
+
+	// Get the guardian id from the context
+	guardianIdString := c.Param("GuardianId")
+	if guardianIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid guardian ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid guardian ID"})
+		return
+	}
+	guardianId, err1 := strconv.ParseUint(guardianIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse guardian ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid guardian ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Check if the guardian exists and belongs to an athlete of the given trainer
+	exists, err2 := guardianExistsForTrainer(ctx, uint(guardianId), trainerEmail)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the guardian exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, "Guardian does not exist")
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Guardian not found"})
+		return
+	}
+
+	// Delete the guardian from the database
+	err3 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Delete(&databaseUtils.Guardian{}, "id = ?", guardianId).Error
+		return err
+	})
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to delete the guardian")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to delete the guardian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "Deletion successful"})
+}
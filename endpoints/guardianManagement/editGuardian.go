@@ -0,0 +1,112 @@
+package guardianManagement
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// EditGuardian edits an existing guardian contact
+// @Summary Edits an existing guardian contact
+// @Description Edits an existing guardian contact with the given details. The assigned athlete cannot be changed.
+// @Tags Guardian Management
+// @Accept json
+// @Produce json
+// @Param Guardian body GuardianBodyWithId true "Edited details of a guardian"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Edited successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Guardian could not be found for this trainer"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/guardian/edit [put]
+func EditGuardian(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "EditGuardian")
+	defer span.End()
+
+	// Bind JSON body to struct
+	var body GuardianBodyWithId
+	if err := c.ShouldBindJSON(&body); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Translate into a database object
+	guardian := databaseUtils.Guardian{
+		ID:       body.GuardianId,
+		Name:     body.Name,
+		Email:    body.Email,
+		Phone:    body.Phone,
+		Relation: body.Relation,
+	}
+
+	// Validate the guardian body
+	err1 := validateGuardian(ctx, &guardian)
+	if errors.Is(err1, formatHelper.EmptyStringError) || errors.Is(err1, NoContactInformationError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err1.Error()})
+		return
+	} else if errors.Is(err1, formatHelper.InvalidEmailAddressFormatError) || errors.Is(err1, formatHelper.EmailAddressContainsNameError) || errors.Is(err1, formatHelper.EmailAddressInvalidTldError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid email address format"})
+		return
+	} else if errors.Is(err1, formatHelper.InvalidPhoneNumberError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid phone number format"})
+		return
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to validate the guardian body")
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	// Check if the guardian exists and belongs to an athlete of the given trainer
+	exists, err2 := guardianExistsForTrainer(ctx, guardian.ID, trainerEmail)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the guardian exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, fmt.Sprintf("Guardian with id %d does not exist", guardian.ID))
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Guardian does not exist"})
+		return
+	}
+
+	// Update the guardian in the database (select all fields to allow clearing the email or phone number)
+	err3 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Guardian{}).
+			Where("id = ?", guardian.ID).
+			Select("name", "email", "phone", "relation").
+			Updates(guardian).
+			Error
+		return err
+	})
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to update the guardian")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to update the guardian"})
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		endpoints.SuccessResponse{
+			Message: "Edited successful",
+		},
+	)
+}
@@ -0,0 +1,88 @@
+package guardianManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type GuardiansResponse struct {
+	Message   string               `json:"message" example:"Request successful"`
+	Guardians []GuardianBodyWithId `json:"guardians"`
+}
+
+// GetGuardians returns all guardians of the given athlete
+// @Summary Returns all guardian contacts of an athlete
+// @Description All guardian contacts of the given athlete are returned
+// @Tags Guardian Management
+// @Produce json
+// @Param AthleteId path int true "Get the guardians of the given athlete"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} GuardiansResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/guardian/get/{AthleteId} [get]
+func GetGuardians(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetGuardians")
+	defer span.End()
+
+	// Get the athlete id from the context
+	athleteIdString := c.Param("AthleteId")
+	if athleteIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid athlete ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid athlete ID"})
+		return
+	}
+	athleteId, err1 := strconv.ParseUint(athleteIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse athlete ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid athlete ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Check if the athlete exists for the given trainer
+	exists, err2 := athleteManagement.AthleteExistsForTrainer(ctx, uint(athleteId), trainerEmail)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the athlete exists"})
+		return
+	}
+	if !exists {
+		endpoints.Logger.Debug(ctx, "Athlete does not exist")
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	}
+
+	// Get the guardians from the database
+	guardians, err3 := GetGuardiansOfAthlete(ctx, uint(athleteId))
+	if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the guardians"})
+		return
+	}
+
+	// Translate the guardians to the response type
+	guardianBodies := make([]GuardianBodyWithId, len(guardians))
+	for idx, guardian := range guardians {
+		guardianBodies[idx] = translateGuardianToResponse(guardian)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		GuardiansResponse{
+			Message:   "Request successful",
+			Guardians: guardianBodies,
+		},
+	)
+}
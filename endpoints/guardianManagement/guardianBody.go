@@ -0,0 +1,18 @@
+package guardianManagement
+
+type GuardianBody struct {
+	AthleteId uint   `json:"athlete_id" example:"1"`
+	Name      string `json:"name" example:"Carol Alice"`
+	Email     string `json:"email" example:"carol.alice@example.com"`
+	Phone     string `json:"phone" example:"+49 170 1234567"`
+	Relation  string `json:"relation" example:"mother"`
+}
+
+type GuardianBodyWithId struct {
+	GuardianId uint   `json:"guardian_id" example:"1"`
+	AthleteId  uint   `json:"athlete_id" example:"1"`
+	Name       string `json:"name" example:"Carol Alice"`
+	Email      string `json:"email" example:"carol.alice@example.com"`
+	Phone      string `json:"phone" example:"+49 170 1234567"`
+	Relation   string `json:"relation" example:"mother"`
+}
@@ -0,0 +1,110 @@
+package guardianManagement
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	NoContactInformationError = errors.New("Either an email address or a phone number is required")
+)
+
+// validateGuardian checks if all values of a guardian are valid.
+// Throws: NoContactInformationError and forwards errors of the formatHelper
+func validateGuardian(ctx context.Context, guardian *databaseUtils.Guardian) error {
+	_, span := endpoints.Tracer.Start(ctx, "ValidateGuardian")
+	defer span.End()
+
+	guardian.Name = strings.TrimSpace(guardian.Name)
+	if err := formatHelper.IsEmpty(guardian.Name); err != nil {
+		return errors.Wrap(err, "Name")
+	}
+
+	guardian.Relation = strings.ToLower(strings.TrimSpace(guardian.Relation))
+	if err := formatHelper.IsEmpty(guardian.Relation); err != nil {
+		return errors.Wrap(err, "Relation")
+	}
+
+	guardian.Email = strings.ToLower(strings.TrimSpace(guardian.Email))
+	guardian.Phone = strings.TrimSpace(guardian.Phone)
+	if guardian.Email == "" && guardian.Phone == "" {
+		return NoContactInformationError
+	}
+
+	if guardian.Email != "" {
+		if err := formatHelper.IsEmail(guardian.Email); err != nil {
+			return errors.Wrap(err, "Invalid email address")
+		}
+	}
+
+	if guardian.Phone != "" {
+		if err := formatHelper.IsPhoneNumber(guardian.Phone); err != nil {
+			return errors.Wrap(err, "Invalid phone number")
+		}
+	}
+
+	return nil
+}
+
+// translateGuardianToResponse converts a guardian database object to the response type
+func translateGuardianToResponse(guardian databaseUtils.Guardian) GuardianBodyWithId {
+	return GuardianBodyWithId{
+		GuardianId: guardian.ID,
+		AthleteId:  guardian.AthleteId,
+		Name:       guardian.Name,
+		Email:      guardian.Email,
+		Phone:      guardian.Phone,
+		Relation:   guardian.Relation,
+	}
+}
+
+// GetGuardiansOfAthlete returns all guardians of the given athlete
+func GetGuardiansOfAthlete(ctx context.Context, athleteId uint) ([]databaseUtils.Guardian, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetGuardiansOfAthleteFromDB")
+	defer span.End()
+
+	var guardians []databaseUtils.Guardian
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Guardian{}).
+			Where("athlete_id = ?", athleteId).
+			Order("id ASC").
+			Find(&guardians).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the guardians")
+		return nil, err1
+	}
+
+	return guardians, nil
+}
+
+// guardianExistsForTrainer checks if a guardian with the given id exists for an athlete of the given trainer
+func guardianExistsForTrainer(ctx context.Context, guardianId uint, trainerEmail string) (bool, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GuardianExistsForTrainer")
+	defer span.End()
+
+	var guardianCount int64
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Guardian{}).
+			Joins("INNER JOIN athletes ON guardians.athlete_id = athletes.id").
+			Where("guardians.id = ? AND athletes.trainer_email = ?", guardianId, strings.ToLower(trainerEmail)).
+			Count(&guardianCount).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to check if the guardian exists")
+		return false, err1
+	}
+
+	return guardianCount > 0, nil
+}
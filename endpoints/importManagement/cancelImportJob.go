@@ -0,0 +1,60 @@
+package importManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// CancelImportJob cancels a running import job
+// @Summary Cancels an import job
+// @Description Cancels the given import job. The import stops before the next batch of rows, entries that have already been created are kept.
+// @Tags Import Management
+// @Produce json
+// @Param ImportJobId path int true "ID of the import job"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Cancellation requested"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Import job not found"
+// @Failure 409 {object} endpoints.ErrorResponse "The import job has already finished"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/cancel/{ImportJobId} [put]
+func CancelImportJob(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CancelImportJob")
+	defer span.End()
+
+	// Get the import job id from the context
+	jobId, err1 := strconv.ParseUint(c.Param("ImportJobId"), 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse import job ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid import job ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	err2 := importJobs.Cancel(ctx, uint(jobId), trainerEmail)
+	if errors.Is(err2, importJobs.ImportJobNotFoundError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Import job not found"})
+		return
+	} else if errors.Is(err2, importJobs.ImportJobFinishedError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: err2.Error()})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to cancel the import job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "Cancellation requested"})
+}
@@ -0,0 +1,70 @@
+package importManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// CommitImportJob imports the records of a dry run
+// @Summary Imports a previewed file
+// @Description Imports the file of a finished dry run in the background. The rows are validated again, since the data might have changed since the preview. Every dry run can only be committed once.
+// @Tags Import Management
+// @Produce json
+// @Param ImportJobId path int true "ID of the dry run"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 202 {object} importJobs.ImportJobResponse "Import started"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter or the import job is not a dry run"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Import job not found"
+// @Failure 409 {object} endpoints.ErrorResponse "The dry run has already been committed or did not finish successfully"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/commit/{ImportJobId} [post]
+func CommitImportJob(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CommitImportJob")
+	defer span.End()
+
+	// Get the import job id from the context
+	jobId, err1 := strconv.ParseUint(c.Param("ImportJobId"), 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse import job ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid import job ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	job, err2 := importJobs.Commit(ctx, uint(jobId), trainerEmail)
+	if errors.Is(err2, importJobs.ImportJobNotFoundError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Import job not found"})
+		return
+	} else if errors.Is(err2, importJobs.ImportJobNotPreviewError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err2.Error()})
+		return
+	} else if errors.Is(err2, importJobs.PreviewAlreadyCommittedError) || errors.Is(err2, importJobs.PreviewNotCommittableError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: err2.Error()})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to commit the import"})
+		return
+	}
+
+	c.JSON(
+		http.StatusAccepted,
+		importJobs.ImportJobResponse{
+			Message:   "Import started",
+			ImportJob: importJobs.TranslateImportJobToResponse(*job),
+		},
+	)
+}
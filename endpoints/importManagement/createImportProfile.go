@@ -0,0 +1,83 @@
+package importManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ImportProfileResponse struct {
+	Message       string                             `json:"message" example:"Creation successful"`
+	ImportProfile importJobs.ImportProfileBodyWithId `json:"import_profile"`
+}
+
+// CreateImportProfile creates a new column mapping profile for csv imports
+// @Summary Creates a new import profile
+// @Description Creates a named column mapping profile that can be selected with the profile_id query parameter of the bulk-create endpoints. Columns are mapped by the column name of the header (the last skipped row) or by the 1-based column index. The row numbers of imports with a profile count from the first data row. Possible fields of athletes: first_name, last_name, email, birth_date, sex. Possible fields of performances: last_name, first_name, sex, birth_year, birth_date, exercise, discipline, date, result, points.
+// @Tags Import Management
+// @Accept json
+// @Produce json
+// @Param ImportProfile body importJobs.ImportProfileBody true "Details of the import profile"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 201 {object} ImportProfileResponse "Creation successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 409 {object} endpoints.ErrorResponse "An import profile with this name already exists"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/profile/create [post]
+func CreateImportProfile(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CreateImportProfile")
+	defer span.End()
+
+	// Bind JSON body to struct
+	var body importJobs.ImportProfileBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Translate into a database object
+	profile := databaseUtils.ImportProfile{
+		Name:         body.Name,
+		Type:         body.Type,
+		Delimiter:    body.Delimiter,
+		SkipRows:     body.SkipRows,
+		DateFormat:   body.DateFormat,
+		Columns:      body.Columns,
+		SexValues:    body.SexValues,
+		TrainerEmail: trainerEmail,
+	}
+
+	err1 := importJobs.CreateImportProfile(ctx, &profile)
+	if errors.Is(err1, importJobs.InvalidImportProfileError) || errors.Is(err1, importJobs.MappingNotSupportedError) || errors.Is(err1, importJobs.UnknownImportTypeError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err1.Error()})
+		return
+	} else if errors.Is(err1, importJobs.ImportProfileAlreadyExistsError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: err1.Error()})
+		return
+	} else if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the import profile"})
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		ImportProfileResponse{
+			Message:       "Creation successful",
+			ImportProfile: importJobs.TranslateImportProfileToResponse(profile),
+		},
+	)
+}
@@ -0,0 +1,55 @@
+package importManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// DeleteImportProfile deletes the given import profile
+// @Summary Deletes the given import profile
+// @Description Deletes the given column mapping profile. Imports that used the profile are not affected.
+// @Tags Import Management
+// @Produce json
+// @Param ImportProfileId path int true "Delete the given import profile"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Deletion successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Import profile not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/profile/delete/{ImportProfileId} [delete]
+func DeleteImportProfile(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "DeleteImportProfile")
+	defer span.End()
+
+	// Get the import profile id from the context
+	profileId, err1 := strconv.ParseUint(c.Param("ImportProfileId"), 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse import profile ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid import profile ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	err2 := importJobs.DeleteImportProfile(ctx, uint(profileId), trainerEmail)
+	if errors.Is(err2, importJobs.ImportProfileNotFoundError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Import profile not found"})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to delete the import profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "Deletion successful"})
+}
@@ -0,0 +1,92 @@
+package importManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// EditImportProfile edits an existing import profile
+// @Summary Edits an existing import profile
+// @Description Overwrites the given column mapping profile with the given details.
+// @Tags Import Management
+// @Accept json
+// @Produce json
+// @Param ImportProfile body importJobs.ImportProfileBodyWithId true "Edited details of the import profile"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} ImportProfileResponse "Edited successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Import profile not found"
+// @Failure 409 {object} endpoints.ErrorResponse "An import profile with this name already exists"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/profile/edit [put]
+func EditImportProfile(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "EditImportProfile")
+	defer span.End()
+
+	// Bind JSON body to struct
+	var body importJobs.ImportProfileBodyWithId
+	if err := c.ShouldBindJSON(&body); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Check if the profile exists for the given trainer
+	_, err1 := importJobs.GetImportProfile(ctx, body.ImportProfileId, trainerEmail)
+	if errors.Is(err1, importJobs.ImportProfileNotFoundError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Import profile not found"})
+		return
+	} else if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the import profile"})
+		return
+	}
+
+	// Translate into a database object
+	profile := databaseUtils.ImportProfile{
+		ID:           body.ImportProfileId,
+		Name:         body.Name,
+		Type:         body.Type,
+		Delimiter:    body.Delimiter,
+		SkipRows:     body.SkipRows,
+		DateFormat:   body.DateFormat,
+		Columns:      body.Columns,
+		SexValues:    body.SexValues,
+		TrainerEmail: trainerEmail,
+	}
+
+	err2 := importJobs.UpdateImportProfile(ctx, &profile)
+	if errors.Is(err2, importJobs.InvalidImportProfileError) || errors.Is(err2, importJobs.MappingNotSupportedError) || errors.Is(err2, importJobs.UnknownImportTypeError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err2.Error()})
+		return
+	} else if errors.Is(err2, importJobs.ImportProfileAlreadyExistsError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: err2.Error()})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to edit the import profile"})
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		ImportProfileResponse{
+			Message:       "Edited successful",
+			ImportProfile: importJobs.TranslateImportProfileToResponse(profile),
+		},
+	)
+}
@@ -0,0 +1,92 @@
+package importManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRowLimit = 500
+	maxRowLimit     = 5000
+)
+
+type ImportJobWithRowsResponse struct {
+	Message   string                   `json:"message" example:"Request successful"`
+	ImportJob importJobs.ImportJobBody `json:"import_job"`
+	Rows      []importJobs.RowResult   `json:"rows"`
+}
+
+// GetImportJob returns the progress and the row results of an import job
+// @Summary Returns the progress and the results of an import job
+// @Description Returns the progress, the summary and the result of every processed row of the given import job. The rows can be filtered by their status and paginated.
+// @Tags Import Management
+// @Produce json
+// @Param ImportJobId path int true "ID of the import job"
+// @Param status query string false "Only return rows with the given status (created, skipped, failed)"
+// @Param offset query int false "Number of rows to skip"
+// @Param limit query int false "Maximum number of rows (default 500, max 5000)"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} importJobs.ImportJobWithRowsResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Import job not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/get/{ImportJobId} [get]
+func GetImportJob(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetImportJob")
+	defer span.End()
+
+	// Get the import job id from the context
+	jobId, err1 := strconv.ParseUint(c.Param("ImportJobId"), 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse import job ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid import job ID"})
+		return
+	}
+
+	// Get the pagination of the rows
+	offset, err2 := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, err3 := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRowLimit)))
+	if err2 != nil || err3 != nil || offset < 0 || limit <= 0 || limit > maxRowLimit {
+		endpoints.Logger.Debug(ctx, "Invalid pagination parameters")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid offset or limit"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	job, err4 := importJobs.GetImportJob(ctx, uint(jobId), trainerEmail)
+	if errors.Is(err4, importJobs.ImportJobNotFoundError) {
+		endpoints.Logger.Debug(ctx, err4)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Import job not found"})
+		return
+	} else if err4 != nil {
+		endpoints.Logger.Error(ctx, err4)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the import job"})
+		return
+	}
+
+	rows, err5 := importJobs.GetRowResults(ctx, job.ID, c.Query("status"), offset, limit)
+	if err5 != nil {
+		endpoints.Logger.Error(ctx, err5)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the import results"})
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		importJobs.ImportJobWithRowsResponse{
+			Message:   "Request successful",
+			ImportJob: importJobs.TranslateImportJobToResponse(*job),
+			Rows:      rows,
+		},
+	)
+}
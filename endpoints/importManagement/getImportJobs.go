@@ -0,0 +1,56 @@
+package importManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportJobCount is the number of the latest import jobs that are returned
+const maxImportJobCount = 50
+
+type ImportJobsResponse struct {
+	Message    string                     `json:"message" example:"Request successful"`
+	ImportJobs []importJobs.ImportJobBody `json:"import_jobs"`
+}
+
+// GetImportJobs returns the latest import jobs of the trainer
+// @Summary Returns the latest import jobs
+// @Description Returns the latest 50 import jobs of the trainer with their progress, newest first.
+// @Tags Import Management
+// @Produce json
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} ImportJobsResponse "Request successful"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/get-all [get]
+func GetImportJobs(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetImportJobs")
+	defer span.End()
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	jobs, err1 := importJobs.GetImportJobsOfTrainer(ctx, trainerEmail, maxImportJobCount)
+	if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the import jobs"})
+		return
+	}
+
+	jobBodies := make([]importJobs.ImportJobBody, len(jobs))
+	for idx, job := range jobs {
+		jobBodies[idx] = importJobs.TranslateImportJobToResponse(job)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		ImportJobsResponse{
+			Message:    "Request successful",
+			ImportJobs: jobBodies,
+		},
+	)
+}
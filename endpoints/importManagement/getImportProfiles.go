@@ -0,0 +1,53 @@
+package importManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+)
+
+type ImportProfilesResponse struct {
+	Message        string                               `json:"message" example:"Request successful"`
+	ImportProfiles []importJobs.ImportProfileBodyWithId `json:"import_profiles"`
+}
+
+// GetImportProfiles returns all import profiles of the trainer
+// @Summary Returns all import profiles
+// @Description Returns all column mapping profiles of the trainer ordered by their name.
+// @Tags Import Management
+// @Produce json
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} ImportProfilesResponse "Request successful"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/import/profile/get-all [get]
+func GetImportProfiles(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetImportProfiles")
+	defer span.End()
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	profiles, err1 := importJobs.GetImportProfilesOfTrainer(ctx, trainerEmail)
+	if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the import profiles"})
+		return
+	}
+
+	profileBodies := make([]importJobs.ImportProfileBodyWithId, len(profiles))
+	for idx, profile := range profiles {
+		profileBodies[idx] = importJobs.TranslateImportProfileToResponse(profile)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		ImportProfilesResponse{
+			Message:        "Request successful",
+			ImportProfiles: profileBodies,
+		},
+	)
+}
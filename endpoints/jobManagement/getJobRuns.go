@@ -0,0 +1,79 @@
+package jobManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/jobScheduler"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRunLimit = 20
+	maxRunLimit     = 500
+)
+
+type JobRunsResponse struct {
+	Message string       `json:"message" example:"Request successful"`
+	Runs    []JobRunBody `json:"runs"`
+}
+
+// GetJobRuns returns the latest runs of a background job
+// @Summary Returns the latest runs of a background job
+// @Description Returns the latest runs of the given job with their status and result, newest first.
+// @Tags Job Management
+// @Produce json
+// @Param JobName path string true "Name of the job"
+// @Param limit query int false "Maximum number of runs (default 20, max 500)"
+// @Param Authorization  header  string  false  "Settings access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} JobRunsResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Job not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/job/runs/{JobName} [get]
+func GetJobRuns(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetJobRuns")
+	defer span.End()
+
+	// Check if the job exists
+	job, err1 := jobScheduler.GetJob(c.Param("JobName"))
+	if err1 != nil {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Job not found"})
+		return
+	}
+
+	limit := defaultRunLimit
+	if limitString := c.Query("limit"); limitString != "" {
+		var err2 error
+		limit, err2 = strconv.Atoi(limitString)
+		if err2 != nil || limit <= 0 || limit > maxRunLimit {
+			endpoints.Logger.Debug(ctx, errors.New("Invalid limit: "+limitString))
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid limit"})
+			return
+		}
+	}
+
+	runs, err3 := jobScheduler.GetJobRuns(ctx, job.Name, limit)
+	if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the job runs"})
+		return
+	}
+
+	runBodies := make([]JobRunBody, len(runs))
+	for idx, run := range runs {
+		runBodies[idx] = translateJobRunToResponse(run)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		JobRunsResponse{
+			Message: "Request successful",
+			Runs:    runBodies,
+		},
+	)
+}
@@ -0,0 +1,65 @@
+package jobManagement
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/jobScheduler"
+	"github.com/gin-gonic/gin"
+)
+
+type JobsResponse struct {
+	Message string    `json:"message" example:"Request successful"`
+	Jobs    []JobBody `json:"jobs"`
+}
+
+// GetJobs returns all background jobs with their last run
+// @Summary Returns all background jobs
+// @Description Returns all registered background jobs with their schedule, the next scheduled execution and the result of the last run.
+// @Tags Job Management
+// @Produce json
+// @Param Authorization  header  string  false  "Settings access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} JobsResponse "Request successful"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/job/get-all [get]
+func GetJobs(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetJobs")
+	defer span.End()
+
+	// Get the last run of every job
+	lastRuns, err1 := jobScheduler.GetLastJobRuns(ctx)
+	if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the job runs"})
+		return
+	}
+
+	now := time.Now()
+	jobs := jobScheduler.GetJobs()
+	jobBodies := make([]JobBody, len(jobs))
+	for idx, job := range jobs {
+		jobBodies[idx] = JobBody{
+			Name:         job.Name,
+			Description:  job.Description,
+			Schedule:     job.Schedule,
+			RunOnStartup: job.RunOnStartup,
+		}
+		if nextRun := job.NextRun(now); nextRun != nil {
+			jobBodies[idx].NextRun = nextRun.UTC().Format(time.RFC3339)
+		}
+		if lastRun, exists := lastRuns[job.Name]; exists {
+			runBody := translateJobRunToResponse(lastRun)
+			jobBodies[idx].LastRun = &runBody
+		}
+	}
+
+	c.JSON(
+		http.StatusOK,
+		JobsResponse{
+			Message: "Request successful",
+			Jobs:    jobBodies,
+		},
+	)
+}
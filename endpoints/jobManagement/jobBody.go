@@ -0,0 +1,21 @@
+package jobManagement
+
+type JobRunBody struct {
+	RunId      uint   `json:"run_id" example:"1"`
+	JobName    string `json:"job_name" example:"retention"`
+	Trigger    string `json:"trigger" example:"schedule"`
+	Status     string `json:"status" example:"succeeded"`
+	StartedAt  string `json:"started_at" example:"2025-01-31T03:00:00Z"`
+	FinishedAt string `json:"finished_at" example:"2025-01-31T03:00:02Z"`
+	Result     string `json:"result" example:"Retention policies applied to 3 entries, 0 failed"`
+	Error      string `json:"error" example:""`
+}
+
+type JobBody struct {
+	Name         string      `json:"name" example:"retention"`
+	Description  string      `json:"description" example:"Applies the data retention policies"`
+	Schedule     string      `json:"schedule" example:"0 3 * * *"`
+	RunOnStartup bool        `json:"run_on_startup"`
+	NextRun      string      `json:"next_run" example:"2025-02-01T03:00:00Z"`
+	LastRun      *JobRunBody `json:"last_run"`
+}
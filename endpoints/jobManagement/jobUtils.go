@@ -0,0 +1,25 @@
+package jobManagement
+
+import (
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+)
+
+// translateJobRunToResponse converts a job run database object to the response type
+func translateJobRunToResponse(run databaseUtils.JobRun) JobRunBody {
+	runBody := JobRunBody{
+		RunId:     run.ID,
+		JobName:   run.JobName,
+		Trigger:   run.Trigger,
+		Status:    run.Status,
+		StartedAt: run.StartedAt.UTC().Format(time.RFC3339),
+		Result:    run.Result,
+		Error:     run.Error,
+	}
+	if run.FinishedAt != nil {
+		runBody.FinishedAt = run.FinishedAt.UTC().Format(time.RFC3339)
+	}
+
+	return runBody
+}
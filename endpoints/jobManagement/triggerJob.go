@@ -0,0 +1,57 @@
+package jobManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/jobScheduler"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type TriggerJobResponse struct {
+	Message string     `json:"message" example:"Job started"`
+	Run     JobRunBody `json:"run"`
+}
+
+// TriggerJob starts a background job manually
+// @Summary Starts a background job manually
+// @Description Starts the given job in the background. The progress can be followed with the runs endpoint.
+// @Tags Job Management
+// @Produce json
+// @Param JobName path string true "Name of the job"
+// @Param Authorization  header  string  false  "Settings access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 202 {object} TriggerJobResponse "Job started"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Job not found"
+// @Failure 409 {object} endpoints.ErrorResponse "The job is already running"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/job/trigger/{JobName} [post]
+func TriggerJob(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "TriggerJob")
+	defer span.End()
+
+	run, err1 := jobScheduler.Trigger(ctx, c.Param("JobName"))
+	if errors.Is(err1, jobScheduler.JobNotFoundError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Job not found"})
+		return
+	} else if errors.Is(err1, jobScheduler.JobAlreadyRunningError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: "The job is already running"})
+		return
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to start the job")
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to start the job"})
+		return
+	}
+
+	c.JSON(
+		http.StatusAccepted,
+		TriggerJobResponse{
+			Message: "Job started",
+			Run:     translateJobRunToResponse(*run),
+		},
+	)
+}
@@ -0,0 +1,178 @@
+package performanceManagement
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// CalculateMedal calculates the medal of a result without an athlete or a performance entry
+// @Summary Calculates the medal of a result
+// @Description Calculates which medal a result would give in an exercise for the given age, sex and year, without storing anything. Either the age or the birth date is required, for the birth date the age reached in the year is used.
+// @Description The result is given as text in the unit of the exercise (e.g. 3:45 for minutes or 4,20 for meters). Returns the medal, the thresholds of the applicable exercise goal and the distance to the next level in the unit of the normalized points.
+// @Tags Performance Management
+// @Accept json
+// @Produce json
+// @Param json body MedalCalculationRequest true "Exercise, athlete data and result"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} MedalCalculationResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Exercise or exercise goal not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/performance/calculate-medal [post]
+func CalculateMedal(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CalculateMedal")
+	defer span.End()
+
+	// Read in JSON body
+	var req MedalCalculationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		err = errors.Wrap(err, "Failed to bind JSON body")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	// Validate the year
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	} else if req.Year < 1900 || req.Year > 9999 {
+		err := errors.New("Invalid year")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Validate the sex
+	req.Sex = strings.ToLower(strings.TrimSpace(req.Sex))
+	if err := formatHelper.IsSex(req.Sex); err != nil {
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get the age from the age or the birth date
+	var age int
+	req.BirthDate = strings.TrimSpace(req.BirthDate)
+	if (req.Age == nil) == (req.BirthDate == "") {
+		err := errors.New("Either the age or the birth date is required")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+		return
+	} else if req.Age != nil {
+		age = *req.Age
+	} else {
+		if err := formatHelper.IsDate(req.BirthDate); err != nil {
+			endpoints.Logger.Debug(ctx, err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid birth date"})
+			return
+		}
+		var err error
+		age, err = badgeHelper.AgeInYear(req.BirthDate, req.Year)
+		if err != nil {
+			endpoints.Logger.Debug(ctx, err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid birth date"})
+			return
+		}
+	}
+	if age < 0 {
+		err := errors.New("The age must not be negative")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get the exercise
+	exercise, err1 := rulesetCache.GetExercise(ctx, req.ExerciseId)
+	if errors.Is(err1, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Exercise not found"})
+		return
+	} else if err1 != nil {
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the exercise"})
+		return
+	}
+
+	// Normalize the result to the stored unit
+	points, err2 := formatHelper.NormalizeResult(req.Result, exercise.Unit)
+	if err2 == nil && points < 0 {
+		err2 = errors.New("Negative result")
+	}
+	if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to normalize the result "+req.Result)
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid result for the unit " + exercise.Unit})
+		return
+	}
+
+	// Get the exercise goal of the age class
+	exerciseGoal, err3 := getExerciseGoal(ctx, exercise.ID, req.Year, age, req.Sex)
+	if errors.Is(err3, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "No exercise goal for the given age, sex and year"})
+		return
+	} else if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the exercise goal"})
+		return
+	}
+
+	calculation := MedalCalculationBody{
+		ExerciseId:      exercise.ID,
+		Exercise:        exercise.Name,
+		Unit:            exercise.Unit,
+		Year:            req.Year,
+		Age:             age,
+		Sex:             req.Sex,
+		Points:          uint64(points),
+		Medal:           getMedalStatus(ctx, exerciseGoal, uint64(points)),
+		FromAge:         exerciseGoal.FromAge,
+		ToAge:           exerciseGoal.ToAge,
+		Bronze:          exerciseGoal.Bronze,
+		Silver:          exerciseGoal.Silver,
+		Gold:            exerciseGoal.Gold,
+		AgeSpecifics:    exerciseGoal.Description,
+		SmallerIsBetter: isSmallerBetter(exerciseGoal.Bronze, exerciseGoal.Gold),
+	}
+	calculation.NextLevel, calculation.DistanceToNextLevel = getDistanceToNextLevel(exerciseGoal, calculation.Medal, calculation.Points)
+
+	c.JSON(
+		http.StatusOK,
+		MedalCalculationResponse{
+			Message:     "Request successful",
+			Calculation: calculation,
+		},
+	)
+}
+
+// getDistanceToNextLevel returns the next better medal and how much the points need to improve to reach it
+func getDistanceToNextLevel(exerciseGoal databaseUtils.ExerciseGoal, medal string, points uint64) (string, uint64) {
+	var nextLevel string
+	var threshold uint64
+	switch medal {
+	case GoldStatus:
+		return "", 0
+	case SilverStatus:
+		nextLevel, threshold = GoldStatus, exerciseGoal.Gold
+	case BronzeStatus:
+		nextLevel, threshold = SilverStatus, exerciseGoal.Silver
+	default:
+		nextLevel, threshold = BronzeStatus, exerciseGoal.Bronze
+	}
+
+	if isSmallerBetter(exerciseGoal.Bronze, exerciseGoal.Gold) {
+		return nextLevel, points - threshold
+	}
+	return nextLevel, threshold - points
+}
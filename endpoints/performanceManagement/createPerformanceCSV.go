@@ -1,7 +1,7 @@
 package performanceManagement
 
 import (
-	"encoding/csv"
+	"context"
 	"fmt"
 	"net/http"
 	"slices"
@@ -9,50 +9,155 @@ import (
 	"strings"
 	"time"
 
+	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/LucaSchmitz2003/FlowWatch"
 
 	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/csvHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/exerciseManagement"
 	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/Team-Reissdorf/Backend/xlsxHelper"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"gorm.io/gorm"
 )
 
-// BulkCreatePerformanceResponse defines the response structure for the bulk performance creation endpoint
-type BulkCreatePerformanceResponse struct {
-	Message       string                   `json:"message" example:"Bulk Creation successful"`
-	FailedEntries []FailedPerformanceEntry `json:"failed_entries,omitempty"`
+var csvColumnCount = 10
+
+// performanceImporter imports performance entries row by row in the background
+var performanceImporter = importJobs.Importer{
+	Type: "performances",
+	ValidateRow: func(ctx context.Context, trainerEmail string, record []string) (interface{}, *importJobs.RowError) {
+		return validatePerformanceRow(ctx, trainerEmail, record, false)
+	},
+	CreateEntries:   createPerformanceEntries,
+	Fields:          []string{"last_name", "first_name", "sex", "birth_year", "birth_date", "exercise", "discipline", "date", "result", "points"},
+	RequiredFields:  []string{"last_name", "first_name", "sex", "birth_date", "exercise", "discipline", "date", "result"},
+	DateFields:      []string{"birth_date", "date"},
+	Preview:         previewPerformanceEntry,
+	Note:            notePerformanceEntry,
+	AcceptsProfiles: true,
 }
 
-// FailedPerformanceEntry represents a failed CSV entry with the row number and reason for the error
-type FailedPerformanceEntry struct {
-	Row    int    `json:"row" example:"2"`
-	Reason string `json:"reason" example:"Invalid athlete ID"`
+// performanceImporterCreatingAthletes additionally creates the athletes that do not exist yet.
+// It is a separate import type, so that the mode is kept when a dry run is committed.
+var performanceImporterCreatingAthletes = importJobs.Importer{
+	Type: "performances_create_athletes",
+	ValidateRow: func(ctx context.Context, trainerEmail string, record []string) (interface{}, *importJobs.RowError) {
+		return validatePerformanceRow(ctx, trainerEmail, record, true)
+	},
+	CreateEntries:  createPerformanceEntries,
+	Fields:         performanceImporter.Fields,
+	RequiredFields: performanceImporter.RequiredFields,
+	DateFields:     performanceImporter.DateFields,
+	Preview:        previewPerformanceEntry,
+	Note:           notePerformanceEntry,
 }
 
-var csvColumnCount = 10
+// FailedPerformanceEntry represents the result of a CSV row with the row number and the reason if it failed.
+// It is the row result of the import jobs, which extends it with the status of every row, the created performance entry, a note and the preview of a dry run.
+type FailedPerformanceEntry = importJobs.RowResult
+
+// performanceImportEntry is a validated row of the performance import
+type performanceImportEntry struct {
+	performance databaseUtils.Performance
+	preview     PerformancePreviewBody
+
+	newAthlete       *databaseUtils.Athlete // Athlete to create before the performance, nil if the athlete exists
+	createdAthleteId uint                   // Set by createPerformanceEntries if this row created the new athlete
+	note             string                 // Remark of the row result (e.g. a differing medal of the imported file)
+}
+
+func init() {
+	importJobs.RegisterImporter(performanceImporter)
+	importJobs.RegisterImporter(performanceImporterCreatingAthletes)
+}
 
 // BulkCreatePerformanceEntries allows bulk creation of performance entries from a CSV file
 // @Summary      Bulk create performance entries from CSV
-// @Description  Upload a .csv file to bulk-create performance entries.
+// @Description  Upload a .csv file or a .xlsx workbook to bulk-create performance entries. The file is imported in the background, the progress and the result of every row can be polled with the returned import job id.
+// @Description  The encoding (UTF-8, Windows-1252 or ISO-8859-1), the delimiter and a header row are detected automatically. Of workbooks, the first sheet or the sheet selected with sheet is imported.
+// @Description  With dry_run, the file is only validated: athletes and exercises are resolved and the medals are calculated, but nothing is created. The preview is created in the background and can be polled with the import job. It can be imported with /v1/import/commit afterward.
+// @Description  With create_missing_athletes, athletes that cannot be found by name and birth date are created from the row. The rows that created an athlete have a note in the result.
+// @Description  Breaking change: the endpoint used to import the file within the request and answer 201 with the failed_entries. It now answers 202 with the started import job, the result of every row (FailedPerformanceEntry with row and reason, extended by status, entity_id, note and preview) is returned by /v1/import/get/{ImportJobId}.
 // @Tags         Performance Management
 // @Accept       multipart/form-data
 // @Produce      json
-// @Param        Performances  formData  file  true  "CSV file; must have extension .csv; columns: lastName;firstName;gender;birthYear;birthDate;exercise;category;date;result;points"
+// @Param        Performances  formData  file  true  "CSV or XLSX file; columns: lastName;firstName;gender;birthYear;birthDate;exercise;category;date;result;points"
+// @Param        dry_run  query  bool  false  "Only validate the file, the preview of every row can be polled with the import job"
+// @Param        create_missing_athletes  query  bool  false  "Create athletes that do not exist yet instead of failing the row"
+// @Param        profile_id  query  int  false  "Column mapping profile to read the file with"
+// @Param        sheet  query  string  false  "Name of the sheet of a XLSX workbook, defaults to the first sheet"
 // @Param        Authorization  header  string  false  "Bearer JWT token"
-// @Success      201  {object}  BulkCreatePerformanceResponse  "Bulk creation successful"
+// @Success      202  {object}  importJobs.ImportJobResponse  "Import started, or the preview with dry_run"
 // @Failure      400  {object}  endpoints.ErrorResponse  "Bad request: missing file / invalid CSV / wrong extension"
 // @Failure      401  {object}  endpoints.ErrorResponse  "Unauthorized: invalid or missing token"
-// @Failure      409  {object}  endpoints.ErrorResponse  "Conflict: all entries failed, none created"
+// @Failure      404  {object}  endpoints.ErrorResponse  "Import profile not found"
 // @Failure      500  {object}  endpoints.ErrorResponse  "Internal server error (DB failure or file read error)"
 // @Router       /v1/performance/bulk-create [post]
 func BulkCreatePerformanceEntries(c *gin.Context) {
 	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "BulkCreatePerformanceEntries")
 	defer span.End()
 
+	// Check if only a preview is requested
+	dryRun := false
+	if dryRunString := c.Query("dry_run"); dryRunString != "" {
+		var err0 error
+		dryRun, err0 = strconv.ParseBool(dryRunString)
+		if err0 != nil {
+			err0 = errors.Wrap(err0, "Invalid 'dry_run' query parameter")
+			endpoints.Logger.Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'dry_run' query parameter"})
+			return
+		}
+	}
+
+	// Check if missing athletes should be created
+	importer := performanceImporter
+	if createAthletesString := c.Query("create_missing_athletes"); createAthletesString != "" {
+		createAthletes, errCreateAthletes := strconv.ParseBool(createAthletesString)
+		if errCreateAthletes != nil {
+			errCreateAthletes = errors.Wrap(errCreateAthletes, "Invalid 'create_missing_athletes' query parameter")
+			endpoints.Logger.Debug(ctx, errCreateAthletes)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'create_missing_athletes' query parameter"})
+			return
+		}
+		if createAthletes {
+			importer = performanceImporterCreatingAthletes
+		}
+	}
+
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Get the selected column mapping profile
+	var profile *databaseUtils.ImportProfile
+	if profileIdString := c.Query("profile_id"); profileIdString != "" {
+		profileId, errProfileId := strconv.ParseUint(profileIdString, 10, 32)
+		if errProfileId != nil {
+			errProfileId = errors.Wrap(errProfileId, "Failed to parse import profile ID")
+			endpoints.Logger.Debug(ctx, errProfileId)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid import profile ID"})
+			return
+		}
+
+		var errProfile error
+		profile, errProfile = importJobs.GetImportProfile(ctx, uint(profileId), trainerEmail)
+		if errors.Is(errProfile, importJobs.ImportProfileNotFoundError) {
+			endpoints.Logger.Debug(ctx, errProfile)
+			c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Import profile not found"})
+			return
+		} else if errProfile != nil {
+			endpoints.Logger.Error(ctx, errProfile)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the import profile"})
+			return
+		}
+	}
+
 	// File from multipart/form-data
 	f, err1 := c.FormFile("Performances")
 	if err1 != nil {
@@ -60,225 +165,354 @@ func BulkCreatePerformanceEntries(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File Field `Performances` in Request is missing"})
 		return
 	}
-	// Check MIME type
-	fileHeader := f.Header.Get("Content-Type")
-	if !strings.HasPrefix(fileHeader, "text/csv") && !strings.HasPrefix(fileHeader, "application/vnd.ms-excel") {
-		err := errors.New("Invalid file type, only CSV files are allowed")
-		endpoints.Logger.Debug(ctx, err)
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+	// Check the file type
+	if err2 := csvHelper.CheckFileType(f); err2 != nil {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err2.Error()})
 		return
 	}
 
-	infile, err2 := f.Open()
-	if err2 != nil {
-		endpoints.Logger.Error(ctx, err2)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Could not open file"})
+	// Read the CSV file or the workbook, a mapping profile defines the delimiter and the header rows itself
+	csvOptions := csvHelper.Options{DetectHeader: true, HeaderNames: performanceImporter.Fields}
+	if profile != nil {
+		csvOptions = csvHelper.Options{Delimiter: importJobs.GetDelimiter(profile)}
+	}
+	csvOptions.Sheet = c.Query("sheet")
+	csvFile, err3 := csvHelper.ReadUpload(f, csvOptions)
+	if errors.Is(err3, csvHelper.EmptyFileError) || errors.Is(err3, xlsxHelper.SheetNotFoundError) {
+		endpoints.Logger.Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err3.Error()})
+		return
+	} else if err3 != nil {
+		endpoints.Logger.Warn(ctx, errors.Wrap(err3, "Failed to read the file"))
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid CSV or XLSX format"})
 		return
 	}
-	defer infile.Close()
+	endpoints.Logger.Debug(ctx, fmt.Sprintf("Read %s file with encoding %s and delimiter %q", csvFile.Format, csvFile.Encoding, csvFile.Delimiter))
+	records := csvFile.Records
+
+	// Rearrange the columns according to the mapping profile
+	if profile != nil {
+		var errMapping error
+		records, errMapping = importJobs.ApplyImportProfile(profile, performanceImporter.Type, records)
+		if errMapping != nil {
+			endpoints.Logger.Debug(ctx, errMapping)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: errMapping.Error()})
+			return
+		}
+	}
 
-	// CSV-Reader with comma.delimeter
-	reader := csv.NewReader(infile)
-	reader.Comma = ';'
+	// Only validate the rows in the background, the preview can be polled with the import job
+	if dryRun {
+		previewJob, errPreview := importJobs.Preview(ctx, importer, trainerEmail, f.Filename, records)
+		if errPreview != nil {
+			endpoints.Logger.Error(ctx, errPreview)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to preview the import"})
+			return
+		}
 
-	records, err3 := reader.ReadAll()
-	if err3 != nil {
-		endpoints.Logger.Warn(ctx, errors.Wrap(err3, "Failed to read CSV"))
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid CSV-Format"})
+		c.JSON(
+			http.StatusAccepted,
+			importJobs.ImportJobResponse{
+				Message:   "Preview started",
+				ImportJob: importJobs.TranslateImportJobToResponse(*previewJob),
+			},
+		)
 		return
 	}
 
-	var performanceEntries []databaseUtils.Performance
-	var failedEntries []FailedPerformanceEntry
-
-	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+	// Import the rows in the background
+	importJob, err4 := importJobs.Start(ctx, importer, trainerEmail, f.Filename, records)
+	if err4 != nil {
+		endpoints.Logger.Error(ctx, err4)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to start the import"})
+		return
+	}
 
-	for i, rec := range records {
-		rowNum := i + 1
+	c.JSON(http.StatusAccepted, importJobs.ImportJobResponse{
+		Message:   "Import started",
+		ImportJob: importJobs.TranslateImportJobToResponse(*importJob),
+	})
+}
 
-		// Spaltenanzahl
-		if len(rec) < csvColumnCount {
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Invalid column count"})
-			continue
-		}
+// validatePerformanceRow validates a single csv record of the performance import and returns the performance entry to create.
+// If createMissingAthletes is set, an athlete that does not exist is validated and created together with the performance.
+func validatePerformanceRow(ctx context.Context, trainerEmail string, rec []string, createMissingAthletes bool) (interface{}, *importJobs.RowError) {
+	// Spaltenanzahl
+	if len(rec) < csvColumnCount {
+		return nil, importJobs.RowFailed("Invalid column count")
+	}
 
-		// read all fields
-		lastName := strings.TrimSpace(rec[0])
-		firstName := strings.TrimSpace(rec[1])
-		gender := strings.ToLower(rec[2])
-		birthYearStr := strings.TrimSpace(rec[3])
-		birthDateRaw := strings.TrimSpace(rec[4])
-		if t, err := time.Parse("02.01.2006", birthDateRaw); err == nil {
-			birthDateRaw = t.Format("2006-01-02")
-		}
-		exerciseName := strings.TrimSpace(rec[5])
-		category := strings.TrimSpace(rec[6])
-		performanceDate := strings.TrimSpace(rec[7])
-		resultRaw := strings.TrimSpace(rec[8])
-		pointsStr := strings.TrimSpace(rec[9])
+	// read all fields
+	lastName := strings.TrimSpace(rec[0])
+	firstName := strings.TrimSpace(rec[1])
+	gender := strings.ToLower(rec[2])
+	birthYearStr := strings.TrimSpace(rec[3])
+	birthDateRaw := strings.TrimSpace(rec[4])
+	if t, err := time.Parse("02.01.2006", birthDateRaw); err == nil {
+		birthDateRaw = t.Format("2006-01-02")
+	}
+	exerciseName := strings.TrimSpace(rec[5])
+	category := strings.TrimSpace(rec[6])
+	performanceDate := strings.TrimSpace(rec[7])
+	resultRaw := strings.TrimSpace(rec[8])
+	pointsStr := strings.TrimSpace(rec[9])
 
-		FlowWatch.GetLogHelper().Debug(ctx, "Results are ", resultRaw)
+	FlowWatch.GetLogHelper().Debug(ctx, "Results are ", resultRaw)
 
-		// parse birthYear
-		_, err4 := strconv.Atoi(birthYearStr)
-		if err4 != nil {
+	// parse birthYear (optional, since it is part of the birth date)
+	if birthYearStr != "" {
+		if _, err4 := strconv.Atoi(birthYearStr); err4 != nil {
 			FlowWatch.GetLogHelper().Debug(ctx, "Failed to parse birth year", err4)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Invalid birthyear"})
-			continue
+			return nil, importJobs.RowFailed("Invalid birthyear")
 		}
+	}
 
-		// find exercise
-		exercise, err5 := exerciseManagement.GetExerciseByNameAndDiscipline(ctx, exerciseName, category)
-		if err5 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to get exercise", err5)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Exercise not found"})
-			continue
-		}
+	// find exercise
+	exercise, err5 := exerciseManagement.GetExerciseByNameAndDiscipline(ctx, exerciseName, category)
+	if err5 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to get exercise", err5)
+		return nil, importJobs.RowFailed("Exercise not found")
+	}
 
-		// validate date
-		// This is for a design issue revolving the date format in the csv file
-		// The date format in the csv file is dd.mm.yyyy
-		// The date format in the db is yyyy-mm-dd
-		// So we need to convert the date format from dd.mm.yyyy to yyyy-mm-dd
-		// Instead of using strings, we should use a date format library like time which we already use in the rest of the code
-		if errors.Is(formatHelper.IsDate(performanceDate), formatHelper.DateFormatInvalidError) {
-			if len(performanceDate) == 10 {
-				performanceDate = performanceDate[6:10] + "-" + performanceDate[3:5] + "-" + performanceDate[0:2]
-			}
+	// validate date
+	// This is for a design issue revolving the date format in the csv file
+	// The date format in the csv file is dd.mm.yyyy
+	// The date format in the db is yyyy-mm-dd
+	// So we need to convert the date format from dd.mm.yyyy to yyyy-mm-dd
+	// Instead of using strings, we should use a date format library like time which we already use in the rest of the code
+	if errors.Is(formatHelper.IsDate(performanceDate), formatHelper.DateFormatInvalidError) {
+		if len(performanceDate) == 10 {
+			performanceDate = performanceDate[6:10] + "-" + performanceDate[3:5] + "-" + performanceDate[0:2]
 		}
+	}
 
-		if err6 := formatHelper.IsDate(performanceDate); err6 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to parse date", err6)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Invalid date"})
-			continue
-		}
+	if err6 := formatHelper.IsDate(performanceDate); err6 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to parse date", err6)
+		return nil, importJobs.RowFailed("Invalid date")
+	}
 
-		if err7 := formatHelper.IsFuture(performanceDate); err7 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to check future", err7)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Invalid date. Date is in the future"})
-			continue
-		}
+	if err7 := formatHelper.IsFuture(performanceDate); err7 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to check future", err7)
+		return nil, importJobs.RowFailed("Invalid date. Date is in the future")
+	}
 
-		gender = strings.TrimSpace(gender)
+	gender = strings.TrimSpace(gender)
 
-		if len(gender) == 0 {
-			FlowWatch.GetLogHelper().Debug(ctx, "Empty sex attribute in record: ", rec)
-			c.AbortWithStatusJSON(http.StatusBadRequest,
-				endpoints.ErrorResponse{Error: "Sex attribute cannot be empty"})
-			return
-		}
-		gender = gender[:1]
-
-		// Normalize the sex attribute
-		switch gender {
-		case "m", "f", "d":
-
-		case "w":
-			gender = "f"
-		default:
-			FlowWatch.GetLogHelper().Debug(ctx, "Invalid sex attribute: ", gender)
-			c.AbortWithStatusJSON(http.StatusBadRequest,
-				endpoints.ErrorResponse{Error: fmt.Sprintf("Invalid sex attribute: %s", gender)})
-			return
-		}
-		
-		// validate gender
-		if err8 := formatHelper.IsSex(gender); err8 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to parse gender", err8)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Invalid gender"})
-			continue
-		}
+	if len(gender) == 0 {
+		FlowWatch.GetLogHelper().Debug(ctx, "Empty sex attribute in record: ", rec)
+		return nil, importJobs.RowFailed("Sex attribute cannot be empty")
+	}
+	gender = gender[:1]
 
-		// TODO: Check
-		// validate result   -> ...
-		time_units := []string{"second", "minute"}
-		if slices.Contains(time_units, exercise.Unit) {
-			if err9 := formatHelper.IsDuration(resultRaw); err9 != nil {
-				FlowWatch.GetLogHelper().Debug(ctx, "Failed to validate result", err9)
-				failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Invalid result format"})
-				continue
-			}
-		}
+	// Normalize the sex attribute
+	switch gender {
+	case "m", "f", "d":
+
+	case "w":
+		gender = "f"
+	default:
+		FlowWatch.GetLogHelper().Debug(ctx, "Invalid sex attribute: ", gender)
+		return nil, importJobs.RowFailed(fmt.Sprintf("Invalid sex attribute: %s", gender))
+	}
+
+	// validate gender
+	if err8 := formatHelper.IsSex(gender); err8 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to parse gender", err8)
+		return nil, importJobs.RowFailed("Invalid gender")
+	}
 
-		// normalize units
-		normalizedResult, err10 := formatHelper.NormalizeResult(resultRaw, exercise.Unit)
-		if err10 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to normalize result", err10)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Failed to normalize result"})
-			continue
+	// TODO: Check
+	// validate result   -> ...
+	time_units := []string{"second", "minute"}
+	if slices.Contains(time_units, exercise.Unit) {
+		if err9 := formatHelper.IsDuration(resultRaw); err9 != nil {
+			FlowWatch.GetLogHelper().Debug(ctx, "Failed to validate result", err9)
+			return nil, importJobs.RowFailed("Invalid result format")
 		}
+	}
+
+	// normalize units
+	normalizedResult, err10 := formatHelper.NormalizeResult(resultRaw, exercise.Unit)
+	if err10 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to normalize result", err10)
+		return nil, importJobs.RowFailed("Failed to normalize result")
+	}
 
-		// parse points
-		_, err11 := strconv.Atoi(pointsStr)
-		if err11 != nil {
+	// parse points (optional, the medal is evaluated from the result)
+	if pointsStr != "" {
+		if _, err11 := strconv.Atoi(pointsStr); err11 != nil {
 			FlowWatch.GetLogHelper().Debug(ctx, "Failed to parse points", err11)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Invalid points"})
-			continue
+			return nil, importJobs.RowFailed("Invalid points")
 		}
+	}
 
-		// find athlete
-		// TODO: check if the athlete exists bzw. if the function works
-		athlete, err12 := athleteManagement.GetAthleteByDetails(ctx, firstName, lastName, birthDateRaw, trainerEmail)
-		if err12 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to get athlete by details", err12)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Athlete not found"})
-			continue
+	// find athlete
+	// TODO: check if the athlete exists bzw. if the function works
+	var newAthlete *databaseUtils.Athlete
+	athlete, err12 := athleteManagement.GetAthleteByDetails(ctx, firstName, lastName, birthDateRaw, trainerEmail)
+	if err12 != nil && !createMissingAthletes {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to get athlete by details", err12)
+		return nil, importJobs.RowFailed("Athlete not found")
+	} else if err12 != nil {
+		// Create the athlete from the details of the row instead
+		athlete = &databaseUtils.Athlete{
+			FirstName:    firstName,
+			LastName:     lastName,
+			BirthDate:    birthDateRaw,
+			Sex:          gender,
+			TrainerEmail: strings.ToLower(trainerEmail),
 		}
-
-		// validate date
-		// This is for a design issue revolving the date format in the csv file
-		// The date format in the csv file is dd.mm.yyyy
-		// The date format in the db is yyyy-mm-dd
-		// So we need to convert the date format from dd.mm.yyyy to yyyy-mm-dd
-		// Instead of using strings, we should use a date format library like time which we already use in the rest of the code
-		if errors.Is(formatHelper.IsDate(birthDateRaw), formatHelper.DateFormatInvalidError) {
-			if len(birthDateRaw) == 10 {
-				birthDateRaw = birthDateRaw[6:10] + "-" + birthDateRaw[3:5] + "-" + birthDateRaw[0:2]
-			}
+		if rowErr := athleteManagement.ValidateImportedAthlete(ctx, athlete); rowErr != nil {
+			rowErr.Reason = "Athlete not found and could not be created: " + rowErr.Reason
+			return nil, rowErr
 		}
+		newAthlete = athlete
+	}
 
-		// calculate age
-		age, err13 := athleteManagement.CalculateAge(ctx, birthDateRaw)
-		if err13 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to calculate age", err13)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Age could not be calculated"})
-			continue
+	// validate date
+	// This is for a design issue revolving the date format in the csv file
+	// The date format in the csv file is dd.mm.yyyy
+	// The date format in the db is yyyy-mm-dd
+	// So we need to convert the date format from dd.mm.yyyy to yyyy-mm-dd
+	// Instead of using strings, we should use a date format library like time which we already use in the rest of the code
+	if errors.Is(formatHelper.IsDate(birthDateRaw), formatHelper.DateFormatInvalidError) {
+		if len(birthDateRaw) == 10 {
+			birthDateRaw = birthDateRaw[6:10] + "-" + birthDateRaw[3:5] + "-" + birthDateRaw[0:2]
 		}
+	}
+
+	// calculate age
+	age, err13 := badgeHelper.AgeOnDate(birthDateRaw, performanceDate)
+	if err13 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to calculate age", err13)
+		return nil, importJobs.RowFailed("Age could not be calculated")
+	}
+
+	medalStatus, err14 := evaluateMedalStatus(ctx, exercise.ID, performanceDate, age, athlete.Sex, uint64(normalizedResult))
+	if err14 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, "Failed to evaluate result", err14)
+		return nil, importJobs.RowFailed("Could not evaluate medal status")
+	}
 
-		medalStatus, err14 := evaluateMedalStatus(ctx, exercise.ID, performanceDate, age, athlete.Sex, uint64(normalizedResult))
-		if err14 != nil {
-			FlowWatch.GetLogHelper().Debug(ctx, "Failed to evaluate result", err14)
-			failedEntries = append(failedEntries, FailedPerformanceEntry{Row: rowNum, Reason: "Could not evaluate medal status"})
-			continue
+	performanceEntry := databaseUtils.Performance{
+		AthleteId:  athlete.ID,
+		ExerciseId: exercise.ID,
+		Date:       performanceDate,
+		Points:     uint64(normalizedResult),
+		Medal:      medalStatus,
+	}
+
+	FlowWatch.GetLogHelper().Debug(ctx, "Performance entry validated", performanceEntry)
+	return &performanceImportEntry{
+		performance: performanceEntry,
+		newAthlete:  newAthlete,
+		preview: PerformancePreviewBody{
+			AthleteId:    athlete.ID,
+			AthleteName:  athlete.FirstName + " " + athlete.LastName,
+			NewAthlete:   newAthlete != nil,
+			ExerciseId:   exercise.ID,
+			ExerciseName: exercise.Name,
+			Unit:         exercise.Unit,
+			Points:       performanceEntry.Points,
+			Medal:        performanceEntry.Medal,
+			Date:         performanceEntry.Date,
+		},
+	}, nil
+}
+
+// createPerformanceEntries writes the validated performance entries of the import to the database.
+// The new athletes of the batch are created first within the same transaction, so that a failing batch creates no athletes.
+func createPerformanceEntries(ctx context.Context, entries []interface{}) ([]uint, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "CreatePerformanceEntries")
+	defer span.End()
+
+	importEntries := make([]*performanceImportEntry, len(entries))
+	performanceEntries := make([]databaseUtils.Performance, len(entries))
+	for idx, entry := range entries {
+		importEntries[idx] = entry.(*performanceImportEntry)
+		performanceEntries[idx] = importEntries[idx].performance
+	}
+
+	createdAthleteIds := make([]uint, len(entries))
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		// Several rows of the batch can belong to the same new athlete
+		athleteIds := make(map[string]uint)
+		for idx, importEntry := range importEntries {
+			if importEntry.newAthlete == nil {
+				continue
+			}
+
+			key := strings.ToLower(importEntry.newAthlete.FirstName + "|" + importEntry.newAthlete.LastName + "|" + importEntry.newAthlete.BirthDate)
+			athleteId, exists := athleteIds[key]
+			if !exists {
+				var err error
+				athleteId, createdAthleteIds[idx], err = findOrCreateAthlete(tx, *importEntry.newAthlete)
+				if err != nil {
+					return err
+				}
+				athleteIds[key] = athleteId
+			}
+			performanceEntries[idx].AthleteId = athleteId
 		}
 
-		performanceEntries = append(performanceEntries, databaseUtils.Performance{
-			AthleteId:  athlete.ID,
-			ExerciseId: exercise.ID,
-			Date:       performanceDate,
-			Points:     uint64(normalizedResult),
-			Medal:      medalStatus,
-		})
+		return tx.Create(&performanceEntries).Error
+	})
+	err1 = databaseUtils.TranslatePostgresError(err1)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to write the performance entries to the database")
+		return nil, err1
+	}
 
-		FlowWatch.GetLogHelper().Debug(ctx, "Performance entry created", performanceEntries)
+	ids := make([]uint, len(performanceEntries))
+	athleteIds := make([]uint, len(performanceEntries))
+	for idx, performanceEntry := range performanceEntries {
+		ids[idx] = performanceEntry.ID
+		athleteIds[idx] = performanceEntry.AthleteId
+		importEntries[idx].createdAthleteId = createdAthleteIds[idx]
 	}
 
-	//  error: no entries
-	if len(performanceEntries) == 0 {
-		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: "No entries found"})
-		return
+	// The rows have been validated for the trainer of the import
+	recordEarnedBadges(ctx, "", athleteIds)
+
+	return ids, nil
+}
+
+// findOrCreateAthlete returns the id of the athlete and, if it had to be created, the id of the new athlete.
+// The athlete is looked up again, since it might have been created by another import since the validation.
+func findOrCreateAthlete(tx *gorm.DB, athlete databaseUtils.Athlete) (uint, uint, error) {
+	var existingAthlete databaseUtils.Athlete
+	err1 := tx.
+		Where("lower(first_name) = ? AND lower(last_name) = ? AND birth_date = ? AND trainer_email = ?",
+			strings.ToLower(athlete.FirstName), strings.ToLower(athlete.LastName), athlete.BirthDate, athlete.TrainerEmail).
+		First(&existingAthlete).
+		Error
+	if err1 == nil {
+		return existingAthlete.ID, 0, nil
+	} else if !errors.Is(err1, gorm.ErrRecordNotFound) {
+		return 0, 0, errors.Wrap(err1, "Failed to look up the athlete")
 	}
 
-	// Bulk-Insert
-	if err15 := createNewPerformances(ctx, performanceEntries); err15 != nil {
-		endpoints.Logger.Error(ctx, errors.Wrap(err15, "Failed to create performance entries"))
-		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Error while saving"})
-		return
+	if err2 := tx.Create(&athlete).Error; err2 != nil {
+		return 0, 0, errors.Wrap(err2, "Failed to create the athlete")
 	}
+	return athlete.ID, athlete.ID, nil
+}
 
-	// list with wrong rows as response
-	c.JSON(http.StatusCreated, BulkCreatePerformanceResponse{
-		Message:       "Bulk creation successful",
-		FailedEntries: failedEntries,
-	})
+// previewPerformanceEntry returns the preview of a validated performance entry
+func previewPerformanceEntry(entry interface{}) interface{} {
+	return entry.(*performanceImportEntry).preview
+}
+
+// notePerformanceEntry reports the athlete that was created for the performance entry and the remark of the row
+func notePerformanceEntry(entry interface{}) string {
+	importEntry := entry.(*performanceImportEntry)
+	notes := make([]string, 0, 2)
+	if importEntry.createdAthleteId != 0 {
+		notes = append(notes, fmt.Sprintf("Created athlete %s %s (ID %d)", importEntry.newAthlete.FirstName, importEntry.newAthlete.LastName, importEntry.createdAthleteId))
+	}
+	if importEntry.note != "" {
+		notes = append(notes, importEntry.note)
+	}
+	return strings.Join(notes, "; ")
 }
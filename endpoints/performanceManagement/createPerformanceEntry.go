@@ -2,6 +2,7 @@ package performanceManagement
 
 import (
 	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
@@ -112,7 +113,7 @@ func CreatePerformance(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to parse the birth date"})
 		return
 	}
-	age, err6 := athleteManagement.CalculateAge(ctx, birthDate)
+	age, err6 := badgeHelper.AgeOnDate(birthDate, body.Date)
 	if err6 != nil {
 		err6 = errors.Wrap(err6, "Failed to calculate the age of the athlete")
 		endpoints.Logger.Error(ctx, err6)
@@ -149,6 +150,7 @@ func CreatePerformance(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the performance entry"})
 		return
 	}
+	recordEarnedBadges(ctx, trainerEmail, []uint{athlete.ID})
 
 	c.JSON(
 		http.StatusCreated,
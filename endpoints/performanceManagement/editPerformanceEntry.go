@@ -3,6 +3,7 @@ package performanceManagement
 import (
 	"fmt"
 	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
@@ -42,18 +43,37 @@ func EditPerformanceEntry(c *gin.Context) {
 	// Get the user id from the context
 	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
 
+	// Validate the date format
 	if err1 := formatHelper.IsEmpty(body.Date); err1 != nil {
 		endpoints.Logger.Debug(ctx, err1)
 		err1 = errors.Wrap(err1, "Date is empty")
 		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err1.Error()})
 		return
+	} else if err1 := formatHelper.IsDate(body.Date); err1 != nil {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid date format"})
+		return
+	}
+
+	// Check if the date is in past
+	err2 := formatHelper.IsFuture(body.Date)
+	if errors.Is(err2, formatHelper.DateInFutureError) {
+		err2 = errors.Wrap(err2, "Date is in the future")
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Date is in the future"})
+		return
+	} else if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to check the date")
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check the date"})
+		return
 	}
 
 	// Check if the given performance entry is for an athlete of the given trainer
-	exists, err1 := performanceExistsForTrainer(ctx, body.PerformanceId, trainerEmail)
-	if err1 != nil {
-		err1 = errors.Wrap(err1, "Failed to check if the performance entry exists and is assigned to the trainer")
-		endpoints.Logger.Error(ctx, err1)
+	exists, err3 := performanceExistsForTrainer(ctx, body.PerformanceId, trainerEmail)
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to check if the performance entry exists and is assigned to the trainer")
+		endpoints.Logger.Error(ctx, err3)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check if the performance entry exists"})
 		return
 	}
@@ -66,23 +86,23 @@ func EditPerformanceEntry(c *gin.Context) {
 	}
 
 	// Get the athlete for the given trainer
-	athlete, err2 := athleteManagement.GetAthleteFromPerformanceId(ctx, body.PerformanceId, trainerEmail)
-	if errors.Is(err2, gorm.ErrRecordNotFound) {
-		err2 = errors.Wrap(err2, "Athlete does not exist")
-		endpoints.Logger.Debug(ctx, err2)
+	athlete, err4 := athleteManagement.GetAthleteFromPerformanceId(ctx, body.PerformanceId, trainerEmail)
+	if errors.Is(err4, gorm.ErrRecordNotFound) {
+		err4 = errors.Wrap(err4, "Athlete does not exist")
+		endpoints.Logger.Debug(ctx, err4)
 		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete does not exist"})
 		return
-	} else if err2 != nil {
-		err2 = errors.Wrap(err2, "Failed to get the athlete")
-		endpoints.Logger.Error(ctx, err2)
+	} else if err4 != nil {
+		err4 = errors.Wrap(err4, "Failed to get the athlete")
+		endpoints.Logger.Error(ctx, err4)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete"})
 		return
 	}
 
 	// Check if the creation limit is reached
-	count, err3 := countPerformanceEntriesPerDisciplinePerDayEditMode(ctx, athlete.ID, body.ExerciseId, body.PerformanceId, body.Date)
-	if err3 != nil {
-		endpoints.Logger.Error(ctx, err3)
+	count, err5 := countPerformanceEntriesPerDisciplinePerDayEditMode(ctx, athlete.ID, body.ExerciseId, body.PerformanceId, body.Date)
+	if err5 != nil {
+		endpoints.Logger.Error(ctx, err5)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the performance entry"})
 		return
 	}
@@ -94,54 +114,32 @@ func EditPerformanceEntry(c *gin.Context) {
 	}
 
 	// Calculate the age of the athlete
-	birthDate, err4 := formatHelper.FormatDate(athlete.BirthDate)
-	if err4 != nil {
-		err4 = errors.Wrap(err4, "Failed to parse the birth date")
-		endpoints.Logger.Error(ctx, err4)
+	birthDate, err6 := formatHelper.FormatDate(athlete.BirthDate)
+	if err6 != nil {
+		err6 = errors.Wrap(err6, "Failed to parse the birth date")
+		endpoints.Logger.Error(ctx, err6)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to parse the birth date"})
 		return
 	}
-	age, err5 := athleteManagement.CalculateAge(ctx, birthDate)
-	if err5 != nil {
-		err5 = errors.Wrap(err5, "Failed to calculate the age of the athlete")
-		endpoints.Logger.Error(ctx, err5)
+	age, err7 := badgeHelper.AgeOnDate(birthDate, body.Date)
+	if err7 != nil {
+		err7 = errors.Wrap(err7, "Failed to calculate the age of the athlete")
+		endpoints.Logger.Error(ctx, err7)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete's age"})
 		return
 	}
 
 	// Get the corresponding medal status
-	medal, err6 := evaluateMedalStatus(ctx, body.ExerciseId, body.Date, age, athlete.Sex, body.Points)
-	if errors.Is(err6, gorm.ErrRecordNotFound) {
-		err6 = errors.Wrap(err6, "No exercise goals for this athlete found")
-		endpoints.Logger.Debug(ctx, err6)
-		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "No exercise goals found for this athlete"})
-		return
-	} else if err6 != nil {
-		err6 = errors.Wrap(err6, "Failed to calculate the medal status")
-		endpoints.Logger.Error(ctx, err6)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the goals for this athlete"})
-		return
-	}
-
-	//Check if the Date is in Correct Format
-	err7 := formatHelper.IsDate(body.Date)
-	if errors.Is(err7, formatHelper.DateFormatInvalidError) {
-		endpoints.Logger.Debug(ctx, err7)
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid date format"})
-		return
-	}
-
-	//Check if the Date is in the Past
-	err8 := formatHelper.IsFuture(body.Date)
-	if errors.Is(err8, formatHelper.DateInFutureError) {
-		err8 = errors.Wrap(err8, "Date is in the future")
+	medal, err8 := evaluateMedalStatus(ctx, body.ExerciseId, body.Date, age, athlete.Sex, body.Points)
+	if errors.Is(err8, gorm.ErrRecordNotFound) {
+		err8 = errors.Wrap(err8, "No exercise goals for this athlete found")
 		endpoints.Logger.Debug(ctx, err8)
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Date is in the future"})
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "No exercise goals found for this athlete"})
 		return
 	} else if err8 != nil {
-		err8 = errors.Wrap(err8, "Failed to check the date")
+		err8 = errors.Wrap(err8, "Failed to calculate the medal status")
 		endpoints.Logger.Error(ctx, err8)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check the date"})
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the goals for this athlete"})
 		return
 	}
 
@@ -162,6 +160,7 @@ func EditPerformanceEntry(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to update the performance entry"})
 		return
 	}
+	recordEarnedBadges(ctx, trainerEmail, []uint{athlete.ID})
 
 	c.JSON(
 		http.StatusOK,
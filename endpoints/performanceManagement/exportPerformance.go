@@ -1,52 +1,107 @@
 package performanceManagement
 
 import (
+	"bufio"
+	"context"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
-	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/Team-Reissdorf/Backend/authHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/dosbHelper"
 	"github.com/Team-Reissdorf/Backend/endpoints"
-	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/xlsxHelper"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 	"net/http"
-	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
-// ExportRequest defines the athlete IDs to be exported.
+const (
+	BestPerDayExportMode  = "best_per_day"
+	BestPerYearExportMode = "best_per_year"
+	AllExportMode         = "all"
+)
+
+// ExportRequest defines the athlete IDs to be exported and the filters of the export.
 type ExportRequest struct {
-	AthleteIDs []int `json:"athlete_ids" example:"1"`
+	AthleteIDs []int  `json:"athlete_ids" example:"1"`
+	Year       int    `json:"year,omitempty" example:"2025"` // Cannot be combined with from and to
+	From       string `json:"from,omitempty" example:"YYYY-MM-DD"`
+	To         string `json:"to,omitempty" example:"YYYY-MM-DD"`
+	ExerciseId uint   `json:"exercise_id,omitempty" example:"1"`
+	Discipline string `json:"discipline,omitempty" example:"Ausdauer"`
+	Mode       string `json:"mode,omitempty" example:"best_per_day"` // best_per_day (default), best_per_year or all
 }
 
-// PerformanceCSV defines the CSV format of the exported performance data.
+// PerformanceCSV defines the columns of the exported performance data, which are the same for all formats.
 type PerformanceCSV struct {
-	AthleteLastName  string
-	AthleteFirstName string
-	AthleteGender    string
-	AthleteBirthYear string
-	AthleteBirthday  string
-	Exercise         string
-	Discipline       string
-	Date             string
-	Medal            string
-	Points           uint64
+	AthleteLastName  string `json:"last_name" example:"Alice"`
+	AthleteFirstName string `json:"first_name" example:"Bob"`
+	AthleteGender    string `json:"sex" example:"w"`
+	AthleteBirthYear string `json:"birth_year" example:"2012"`
+	AthleteBirthday  string `json:"birth_date" example:"DD.MM.YYYY"`
+	Exercise         string `json:"exercise" example:"Sprint 50m"`
+	Discipline       string `json:"discipline" example:"Schnelligkeit"`
+	Date             string `json:"date" example:"DD.MM.YYYY"`
+	Result           string `json:"result" example:"8"`
+	Medal            string `json:"medal" example:"3"` // 3 gold, 2 silver, 1 bronze, 0 none
+}
+
+type PerformanceExportResponse struct {
+	Message      string           `json:"message" example:"Request successful"`
+	Performances []PerformanceCSV `json:"performances"`
+}
+
+// performanceExportHeader contains the column names of the csv and xlsx export, which match the json keys of PerformanceCSV
+// and the fields of the performance import
+var performanceExportHeader = []string{
+	"last_name", "first_name", "sex", "birth_year", "birth_date", "exercise", "discipline", "date", "result", "medal",
 }
 
-// ExportPerformances exports all performance entries of the specified athletes as a csv file.
-// Only the entry with the best medal is exported per day (gold > silver > bronze).
-// @Summary Exports all performance entries of the specified athletes as a csv file
-// @Description Exports all performance entries of the specified athletes as a csv file
+// performanceExportRow is an exported performance of an athlete (the best one of its day or year, depending on the mode)
+type performanceExportRow struct {
+	athlete  databaseUtils.Athlete
+	exercise databaseUtils.Exercise
+	day      string // YYYY-MM-DD
+	best     PerformanceBodyWithId
+}
+
+// exportFilter restricts the exported performance entries
+type exportFilter struct {
+	from       string // YYYY-MM-DD, inclusive
+	to         string // YYYY-MM-DD, inclusive
+	exerciseId uint
+	discipline string
+	mode       string
+}
+
+// ExportPerformances exports the performance entries of the specified athletes.
+// By default, only the entry with the best medal is exported per exercise and day (gold > silver > bronze).
+// @Summary Exports the performance entries of the specified athletes as a csv file, a xlsx workbook or json
+// @Description Exports the performance entries of the specified athletes. They can be filtered by year or by a date range (from, to), by exercise and by discipline.
+// @Description The mode selects the exported entries: best_per_day (default) exports the best entry per exercise and day, best_per_year the best entry per exercise and year and all every entry.
+// @Description The csv file, the xlsx workbook (with typed date and result cells) and the json response have the same columns. Medal is 3 for gold, 2 for silver, 1 for bronze and 0 otherwise.
+// @Description Results of minors are only exported with an active guardian consent to publish results, otherwise the request is rejected with the ids of the affected athletes.
+// @Description With format=dosb, the performances with a medal are exported as result list of the DOSB Sportabzeichen portal, which is validated against the format and can be imported again with /v1/performance/dosb-import.
 // @Tags Performance Management
 // @Produce text/csv
-// @Param json body ExportRequest true "JSON payload in the format: "athlete_ids": [] "
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce json
+// @Param json body ExportRequest true "Athletes and filters of the export"
+// @Param format query string false "csv (default), xlsx, json or dosb"
 // @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
-// @Success 200 {file} file "CSV file"
+// @Success 200 {file} file "CSV file or XLSX workbook"
+// @Success 200 {object} PerformanceExportResponse "Exported performances (format=json)"
 // @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
 // @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 403 {object} endpoints.ErrorResponse "Guardian consent to publish results is missing for minors"
 // @Failure 404 {object} endpoints.ErrorResponse "One or more athletes do not exist"
+// @Failure 422 {object} endpoints.ErrorResponse "The performances do not match the DOSB format"
 // @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
 // @Router /v1/performance/export [post]
 func ExportPerformances(c *gin.Context) {
@@ -71,139 +126,344 @@ func ExportPerformances(c *gin.Context) {
 		return
 	}
 
-	// Set CSV header
+	// Check the requested file format
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" && format != "json" && format != "dosb" {
+		err := errors.New("Invalid export format " + format)
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'format' query parameter, allowed are csv, xlsx, json and dosb"})
+		return
+	}
+
+	// Validate the filters
+	filter, err1 := getExportFilter(req)
+	if err1 != nil {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err1.Error()})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	pipeline, err2 := newExportPipeline(ctx, req.AthleteIDs, filter, trainerEmail)
+	if errors.Is(err2, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to collect the performances"})
+		return
+	}
+
+	// Results of minors are only published with the consent of a guardian
+	athletesWithoutConsent, err3 := pipeline.getAthletesWithoutConsent(ctx)
+	if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check the consents"})
+		return
+	}
+	if len(athletesWithoutConsent) > 0 {
+		athleteIds := make([]string, len(athletesWithoutConsent))
+		for idx, athleteId := range athletesWithoutConsent {
+			athleteIds[idx] = strconv.FormatUint(uint64(athleteId), 10)
+		}
+		endpoints.Logger.Debug(ctx, "Consent to publish results is missing for the athletes "+strings.Join(athleteIds, ", "))
+		c.AbortWithStatusJSON(http.StatusForbidden, endpoints.ErrorResponse{
+			Error: "Guardian consent to publish results is missing for the athletes " + strings.Join(athleteIds, ", "),
+		})
+		return
+	}
+
+	// The rows are streamed to the response, so errors after the first row can only be logged
+	var err4 error
+	switch format {
+	case "xlsx":
+		err4 = writePerformancesXlsx(ctx, c, pipeline)
+	case "json":
+		err4 = writePerformancesJson(ctx, c, pipeline)
+	case "dosb":
+		err4 = writePerformancesDosb(ctx, c, pipeline)
+	default:
+		err4 = writePerformancesCsv(ctx, c, pipeline)
+	}
+	if err4 != nil {
+		err4 = errors.Wrap(err4, "Failed to write the "+format+" export")
+		endpoints.Logger.Error(ctx, err4)
+		if !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the export"})
+		} else {
+			c.Abort()
+		}
+	}
+}
+
+// getExportFilter validates the filters of the export request
+func getExportFilter(req ExportRequest) (exportFilter, error) {
+	filter := exportFilter{
+		from:       strings.TrimSpace(req.From),
+		to:         strings.TrimSpace(req.To),
+		exerciseId: req.ExerciseId,
+		discipline: strings.TrimSpace(req.Discipline),
+		mode:       req.Mode,
+	}
+
+	switch filter.mode {
+	case "":
+		filter.mode = BestPerDayExportMode
+	case BestPerDayExportMode, BestPerYearExportMode, AllExportMode:
+	default:
+		return filter, errors.New("Invalid mode, allowed are best_per_day, best_per_year and all")
+	}
+
+	if req.Year != 0 {
+		if filter.from != "" || filter.to != "" {
+			return filter, errors.New("The year cannot be combined with from and to")
+		}
+		filter.from = fmt.Sprintf("%04d-01-01", req.Year)
+		filter.to = fmt.Sprintf("%04d-12-31", req.Year)
+	}
+	for _, date := range []string{filter.from, filter.to} {
+		if date == "" {
+			continue
+		}
+		if err := formatHelper.IsDate(date); err != nil {
+			return filter, errors.New("Invalid date format of from or to, expected YYYY-MM-DD")
+		}
+	}
+	if filter.from != "" && filter.to != "" && filter.from > filter.to {
+		return filter, errors.New("From has to be before to")
+	}
+
+	return filter, nil
+}
+
+// toPerformanceCSV formats the exported performance as text
+func (row performanceExportRow) toPerformanceCSV() PerformanceCSV {
+	// prepare athlete and date fields
+	birthRaw := row.athlete.BirthDate // "YYYY-MM-DD"
+	birthYear := birthRaw[:4]
+	birthDate := birthRaw[8:10] + "." + birthRaw[5:7] + "." + birthRaw[:4]
+
+	formattedDate := row.day[8:10] + "." + row.day[5:7] + "." + row.day[:4]
+
+	// format points according to exercise unit
+	var formattedPoints string
+	switch row.exercise.Unit {
+	case "second":
+		// The decimals are only written if there are any (7.85, 12)
+		secs := float64(row.best.Points) / 1_000
+		formattedPoints = strconv.FormatFloat(secs, 'f', -1, 64)
+	case "minute":
+		mins := row.best.Points / 60_000
+		secs := float64(row.best.Points%60_000) / 1_000
+		formattedPoints = fmt.Sprintf("%d:", mins)
+		if secs < 10 {
+			formattedPoints += "0"
+		}
+		formattedPoints += strconv.FormatFloat(secs, 'f', -1, 64)
+	case "meter":
+		meters := float64(row.best.Points) / 100
+		formattedPoints = fmt.Sprintf("%.2f", meters)
+	default:
+		formattedPoints = strconv.FormatUint(row.best.Points, 10)
+	}
+
+	return PerformanceCSV{
+		AthleteLastName:  row.athlete.LastName,
+		AthleteFirstName: row.athlete.FirstName,
+		AthleteGender:    exportSex(row.athlete.Sex),
+		AthleteBirthYear: birthYear,
+		AthleteBirthday:  birthDate,
+		Exercise:         row.exercise.Name,
+		Discipline:       row.exercise.DisciplineName,
+		Date:             formattedDate,
+		Result:           formattedPoints,
+		Medal:            strconv.Itoa(exportMedal(row.best.Medal)),
+	}
+}
+
+// writePerformancesCsv streams the performances as csv file with a header row
+func writePerformancesCsv(ctx context.Context, c *gin.Context, pipeline *exportPipeline) error {
 	c.Header("Content-Type", "text/csv")
 	c.Header("Content-Disposition", "attachment; filename=performances.csv")
 	w := csv.NewWriter(c.Writer)
 	w.Comma = ';'
-	defer w.Flush()
 
-	// Get the user id from the context
-	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+	_ = w.Write(performanceExportHeader)
+	err := pipeline.Each(ctx, func(row performanceExportRow) error {
+		performance := row.toPerformanceCSV()
+		return w.Write([]string{
+			performance.AthleteLastName,
+			performance.AthleteFirstName,
+			performance.AthleteGender,
+			performance.AthleteBirthYear,
+			performance.AthleteBirthday,
+			performance.Exercise,
+			performance.Discipline,
+			performance.Date,
+			performance.Result,
+			performance.Medal,
+		})
+	})
+	w.Flush()
+	if err != nil {
+		return err
+	}
+	return w.Error()
+}
 
-	// iterate over each athlete ID
-	for _, athleteID := range req.AthleteIDs {
-		// fetch athlete information
-		athlete, err := athleteManagement.GetAthlete(ctx, uint(athleteID), trainerEmail)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			endpoints.Logger.Debug(ctx, errors.Wrap(err, "athlete not found"))
-			c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
-			return
-		} else if err != nil {
-			endpoints.Logger.Debug(ctx, errors.Wrap(err, "failed to fetch athlete data"))
-			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to fetch athlete data"})
-			return
-		}
+// writePerformancesJson streams the performances as PerformanceExportResponse
+func writePerformancesJson(ctx context.Context, c *gin.Context, pipeline *exportPipeline) error {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	w := bufio.NewWriter(c.Writer)
 
-		// retrieve all performance entries for this athlete
-		performances, err := getAllPerformanceBodies(ctx, uint(athleteID))
+	_, _ = w.WriteString(`{"message":"Request successful","performances":[`)
+	first := true
+	err1 := pipeline.Each(ctx, func(row performanceExportRow) error {
+		performance, err := json.Marshal(row.toPerformanceCSV())
 		if err != nil {
-			endpoints.Logger.Debug(ctx, errors.Wrap(err, "failed to fetch performances"))
-			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to fetch performances"})
-			return
+			return err
 		}
-
-		// group performances by exercise ID and day
-		type groupKey struct {
-			ExerciseID uint
-			Day        string
+		if !first {
+			_ = w.WriteByte(',')
 		}
-		grouped := make(map[groupKey][]PerformanceBodyWithId)
-		for _, p := range *performances {
-			day := p.Date[:10]
-			key := groupKey{ExerciseID: p.ExerciseId, Day: day}
-			grouped[key] = append(grouped[key], p)
+		first = false
+		_, err = w.Write(performance)
+		return err
+	})
+	if err1 != nil {
+		_ = w.Flush()
+		return err1
+	}
+	_, _ = w.WriteString(`]}`)
+
+	return w.Flush()
+}
+
+// performanceExportWidths are the column widths of the xlsx export, which cannot be measured while streaming
+var performanceExportWidths = []int{20, 20, 6, 12, 12, 30, 18, 12, 10, 8}
+
+// writePerformancesXlsx streams the performances as xlsx workbook with typed date and result cells
+func writePerformancesXlsx(ctx context.Context, c *gin.Context, pipeline *exportPipeline) error {
+	c.Header("Content-Type", xlsxHelper.ContentType)
+	c.Header("Content-Disposition", "attachment; filename=performances.xlsx")
+	streamWriter, err1 := xlsxHelper.NewStreamWriter(c.Writer, "Leistungen", performanceExportHeader, performanceExportWidths)
+	if err1 != nil {
+		return err1
+	}
+
+	err2 := pipeline.Each(ctx, func(row performanceExportRow) error {
+		birthDate, err3 := time.Parse(time.DateOnly, row.athlete.BirthDate[:10])
+		date, err4 := time.Parse(time.DateOnly, row.day)
+		if err3 != nil || err4 != nil {
+			return errors.Errorf("Invalid date of the performance %d", row.best.PerformanceId)
 		}
 
-		// extract and sort group keys for stable output
-		var keys []groupKey
-		for k := range grouped {
-			keys = append(keys, k)
+		// type the result according to the exercise unit
+		var result xlsxHelper.Cell
+		switch row.exercise.Unit {
+		case "second":
+			result = xlsxHelper.Decimal(float64(row.best.Points) / 1_000)
+		case "minute":
+			result = xlsxHelper.Duration(time.Duration(row.best.Points) * time.Millisecond)
+		case "meter":
+			result = xlsxHelper.Decimal(float64(row.best.Points) / 100)
+		default:
+			result = xlsxHelper.Number(float64(row.best.Points))
 		}
-		sort.Slice(keys, func(i, j int) bool {
-			if keys[i].ExerciseID != keys[j].ExerciseID {
-				return keys[i].ExerciseID < keys[j].ExerciseID
-			}
-			return keys[i].Day < keys[j].Day
+
+		return streamWriter.WriteRow([]xlsxHelper.Cell{
+			xlsxHelper.String(row.athlete.LastName),
+			xlsxHelper.String(row.athlete.FirstName),
+			xlsxHelper.String(exportSex(row.athlete.Sex)),
+			xlsxHelper.Number(float64(birthDate.Year())),
+			xlsxHelper.Date(birthDate),
+			xlsxHelper.String(row.exercise.Name),
+			xlsxHelper.String(row.exercise.DisciplineName),
+			xlsxHelper.Date(date),
+			result,
+			xlsxHelper.Number(float64(exportMedal(row.best.Medal))),
 		})
+	})
+	if err2 != nil {
+		return err2
+	}
+
+	return streamWriter.Close()
+}
 
-		// process each group: select best performance and write to CSV
-		for _, k := range keys {
-			entries := grouped[k]
-
-			// determine the best performance entry using goal-based logic
-			bestEntry, err := getBestPerformanceEntry(ctx, &entries)
-			if err != nil {
-				endpoints.Logger.Debug(ctx, errors.Wrap(err, "failed to determine best performance"))
-				c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to determine best performance"})
-				return
-			}
-
-			// fetch exercise metadata
-			var exercise databaseUtils.Exercise
-			if err := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
-				return tx.First(&exercise, k.ExerciseID).Error
-			}); err != nil {
-				endpoints.Logger.Debug(ctx, errors.Wrap(err, "failed to fetch exercise metadata"))
-				c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to fetch exercise metadata"})
-				return
-			}
-
-			// prepare athlete and date fields
-			birthRaw := athlete.BirthDate // "YYYY-MM-DD"
-			birthYear := birthRaw[:4]
-			birthDate := birthRaw[8:10] + "." + birthRaw[5:7] + "." + birthRaw[:4]
-
-			sex := athlete.Sex
-			if sex == "f" {
-				sex = "w"
-			}
-
-			formattedDate := k.Day[8:10] + "." + k.Day[5:7] + "." + k.Day[:4]
-
-			// format points according to exercise unit
-			var formattedPoints string
-			switch exercise.Unit {
-			case "second":
-				secs := bestEntry.Points / 1_000
-				formattedPoints = strconv.FormatUint(secs, 10)
-			case "minute":
-				totalSec := bestEntry.Points / 1_000
-				mins := totalSec / 60
-				secs := totalSec % 60
-				formattedPoints = fmt.Sprintf("%d:%02d", mins, secs)
-			case "meter":
-				meters := float64(bestEntry.Points) / 100
-				formattedPoints = fmt.Sprintf("%.2f", meters)
-			default:
-				formattedPoints = strconv.FormatUint(bestEntry.Points, 10)
-			}
-
-			var medal string
-			switch bestEntry.Medal {
-			case "gold":
-				medal = "3"
-			case "silver":
-				medal = "2"
-			case "bronze":
-				medal = "1"
-			default:
-				medal = "0"
-			}
-
-			// write CSV record for this best performance
-			_ = w.Write([]string{
-				athlete.LastName,
-				athlete.FirstName,
-				sex,
-				birthYear,
-				birthDate,
-				exercise.Name,
-				exercise.DisciplineName,
-				formattedDate,
-				formattedPoints,
-				medal,
-			})
+// writePerformancesDosb writes the performances with a medal as result list of the DOSB Sportabzeichen portal.
+// Every row is validated against the format first, so that no invalid file is sent. Therefore, the records are not streamed.
+func writePerformancesDosb(ctx context.Context, c *gin.Context, pipeline *exportPipeline) error {
+	var records [][]string
+	var invalidRows []string
+	err1 := pipeline.Each(ctx, func(row performanceExportRow) error {
+		entry := dosbHelper.Entry{
+			LastName:   row.athlete.LastName,
+			FirstName:  row.athlete.FirstName,
+			Sex:        row.athlete.Sex,
+			BirthDate:  row.athlete.BirthDate[:10],
+			Exercise:   row.exercise.Name,
+			Discipline: row.exercise.DisciplineName,
+			Date:       row.day,
+			Medal:      row.best.Medal,
+		}
+		record, err := dosbHelper.FormatRecord(entry, row.best.Points, row.exercise.Unit)
+		if errors.Is(err, dosbHelper.NoMedalError) {
+			return nil
+		} else if err != nil {
+			invalidRows = append(invalidRows, fmt.Sprintf("%s %s, %s on %s: %s",
+				row.athlete.FirstName, row.athlete.LastName, row.exercise.Name, row.day, err.Error()))
+			return nil
 		}
+		records = append(records, record)
+		return nil
+	})
+	if err1 != nil {
+		return err1
+	}
+
+	if len(invalidRows) > 0 {
+		err := errors.New("The performances do not match the DOSB format: " + strings.Join(invalidRows, "; "))
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, endpoints.ErrorResponse{Error: err.Error()})
+		return nil
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=sportabzeichen.csv")
+	w := csv.NewWriter(c.Writer)
+	w.Comma = dosbHelper.Delimiter
+	w.UseCRLF = true
+
+	_ = w.Write(dosbHelper.Header)
+	for _, record := range records {
+		_ = w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// exportSex converts the sex to the format of the DOSB (w instead of f)
+func exportSex(sex string) string {
+	if sex == "f" {
+		return "w"
+	}
+	return sex
+}
+
+// exportMedal converts the medal to the points of the DOSB (gold = 3, silver = 2, bronze = 1)
+func exportMedal(medal string) int {
+	switch medal {
+	case "gold":
+		return 3
+	case "silver":
+		return 2
+	case "bronze":
+		return 1
+	default:
+		return 0
 	}
 }
@@ -0,0 +1,248 @@
+package performanceManagement
+
+import (
+	"context"
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// exportBatchSize is the number of athletes whose performances are loaded with one query
+const exportBatchSize = 200
+
+// exportPipeline loads the exported performances in batches of athletes and passes the rows on one by one,
+// so that the memory usage does not grow with the number of athletes.
+// The exercises and the exercise goals are taken from the ruleset cache, which replaces the former per group queries.
+type exportPipeline struct {
+	filter     exportFilter
+	athleteIds []uint
+	athletes   map[uint]databaseUtils.Athlete
+}
+
+// newExportPipeline loads the athletes of the export
+// Throws: gorm.ErrRecordNotFound if an athlete does not exist
+func newExportPipeline(ctx context.Context, athleteIds []int, filter exportFilter, trainerEmail string) (*exportPipeline, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "NewExportPipeline")
+	defer span.End()
+
+	pipeline := exportPipeline{
+		filter:   filter,
+		athletes: make(map[uint]databaseUtils.Athlete, len(athleteIds)),
+	}
+
+	// Remove duplicates but keep the order of the request
+	for _, athleteId := range athleteIds {
+		if _, exists := pipeline.athletes[uint(athleteId)]; exists {
+			continue
+		}
+		pipeline.athletes[uint(athleteId)] = databaseUtils.Athlete{}
+		pipeline.athleteIds = append(pipeline.athleteIds, uint(athleteId))
+	}
+
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		for start := 0; start < len(pipeline.athleteIds); start += exportBatchSize {
+			batch := pipeline.athleteIds[start:min(start+exportBatchSize, len(pipeline.athleteIds))]
+
+			var athletes []databaseUtils.Athlete
+			err := tx.Model(&databaseUtils.Athlete{}).
+				Where("trainer_email = ? AND id IN ?", strings.ToLower(trainerEmail), batch).
+				Find(&athletes).
+				Error
+			if err != nil {
+				return err
+			}
+			if len(athletes) != len(batch) {
+				return gorm.ErrRecordNotFound
+			}
+			for _, athlete := range athletes {
+				pipeline.athletes[athlete.ID] = athlete
+			}
+		}
+		return nil
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the athletes of the export")
+		return nil, err1
+	}
+
+	return &pipeline, nil
+}
+
+// Each calls emit for every exported row in the order of the athletes, exercises and dates
+func (pipeline *exportPipeline) Each(ctx context.Context, emit func(performanceExportRow) error) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "ExportPipelineEach")
+	defer span.End()
+
+	for start := 0; start < len(pipeline.athleteIds); start += exportBatchSize {
+		batch := pipeline.athleteIds[start:min(start+exportBatchSize, len(pipeline.athleteIds))]
+
+		performances, err1 := pipeline.getPerformances(ctx, batch)
+		if err1 != nil {
+			return err1
+		}
+
+		for _, athleteId := range batch {
+			err2 := pipeline.emitAthlete(ctx, pipeline.athletes[athleteId], performances[athleteId], emit)
+			if err2 != nil {
+				return err2
+			}
+		}
+	}
+
+	return nil
+}
+
+// getPerformances gets the filtered performances of the athletes ordered by exercise and date
+func (pipeline *exportPipeline) getPerformances(ctx context.Context, athleteIds []uint) (map[uint][]PerformanceBodyWithId, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetExportPerformancesFromDB")
+	defer span.End()
+
+	var performanceBodies []PerformanceBodyWithId
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		query := tx.Model(&databaseUtils.Performance{}).
+			Select("performances.id AS performance_id, points, exercises.unit AS unit, medal, date, exercise_id, athlete_id").
+			Joins("LEFT JOIN exercises ON performances.exercise_id = exercises.id").
+			Where("athlete_id IN ?", athleteIds)
+		if pipeline.filter.from != "" {
+			query = query.Where("date >= ?", pipeline.filter.from)
+		}
+		if pipeline.filter.to != "" {
+			query = query.Where("date <= ?", pipeline.filter.to)
+		}
+		if pipeline.filter.exerciseId != 0 {
+			query = query.Where("exercise_id = ?", pipeline.filter.exerciseId)
+		}
+		if pipeline.filter.discipline != "" {
+			query = query.Where("LOWER(exercises.discipline_name) = LOWER(?)", pipeline.filter.discipline)
+		}
+
+		err := query.Order("athlete_id, exercise_id, date, performances.id").
+			Find(&performanceBodies).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the performances of the export")
+		return nil, err1
+	}
+
+	performances := make(map[uint][]PerformanceBodyWithId, len(athleteIds))
+	for _, performanceBody := range performanceBodies {
+		var err2 error
+		performanceBody.Date, err2 = formatHelper.FormatDate(performanceBody.Date)
+		if err2 != nil {
+			err2 = errors.Wrap(err2, "Failed to format the date of a performance entry")
+			return nil, err2
+		}
+		performances[performanceBody.AthleteId] = append(performances[performanceBody.AthleteId], performanceBody)
+	}
+
+	return performances, nil
+}
+
+// emitAthlete groups the performances of the athlete according to the mode and emits the best entry of each group.
+// The performances have to be ordered by exercise and date.
+func (pipeline *exportPipeline) emitAthlete(ctx context.Context, athlete databaseUtils.Athlete, performances []PerformanceBodyWithId, emit func(performanceExportRow) error) error {
+	for start := 0; start < len(performances); {
+		// Find the end of the group
+		end := start + 1
+		if pipeline.filter.mode != AllExportMode {
+			for end < len(performances) &&
+				performances[end].ExerciseId == performances[start].ExerciseId &&
+				pipeline.period(performances[end]) == pipeline.period(performances[start]) {
+				end++
+			}
+		}
+		group := performances[start:end]
+		start = end
+
+		exercise, err1 := rulesetCache.GetExercise(ctx, group[0].ExerciseId)
+		if err1 != nil {
+			return err1
+		}
+
+		best := group[0]
+		if len(group) > 1 {
+			smallerBetter, err2 := isSmallerBetterFor(ctx, athlete, group[0])
+			if err2 != nil {
+				return err2
+			}
+			best = pickBestPerformanceEntry(group, smallerBetter)
+		}
+
+		err3 := emit(performanceExportRow{
+			athlete:  athlete,
+			exercise: exercise,
+			day:      best.Date,
+			best:     best,
+		})
+		if err3 != nil {
+			return err3
+		}
+	}
+
+	return nil
+}
+
+// period returns the day or the year of the performance, depending on the mode
+func (pipeline *exportPipeline) period(performance PerformanceBodyWithId) string {
+	if pipeline.filter.mode == BestPerYearExportMode {
+		return performance.Date[:4]
+	}
+	return performance.Date
+}
+
+// isSmallerBetterFor checks with the exercise goal of the athlete if smaller results of the performance are better
+func isSmallerBetterFor(ctx context.Context, athlete databaseUtils.Athlete, performance PerformanceBodyWithId) (bool, error) {
+	if len(athlete.BirthDate) < 10 {
+		return false, errors.New("Invalid BirthDate: must be at least 10 characters long")
+	}
+	performanceYear, err1 := getPerformanceYear(ctx, performance.Date)
+	if err1 != nil {
+		return false, err1
+	}
+
+	age, err2 := badgeHelper.AgeInYear(athlete.BirthDate, performanceYear)
+	if err2 != nil {
+		return false, err2
+	}
+	exerciseGoal, err3 := getExerciseGoal(ctx, performance.ExerciseId, performanceYear, age, athlete.Sex)
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to get the exercise goal")
+		return false, err3
+	}
+
+	return isSmallerBetter(exerciseGoal.Bronze, exerciseGoal.Gold), nil
+}
+
+// getAthletesWithoutConsent returns the ids of the exported minors without the guardian consent to publish their results,
+// in the order of the request
+func (pipeline *exportPipeline) getAthletesWithoutConsent(ctx context.Context) ([]uint, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetExportedAthletesWithoutConsent")
+	defer span.End()
+
+	athletes := make([]databaseUtils.Athlete, len(pipeline.athleteIds))
+	for idx, athleteId := range pipeline.athleteIds {
+		athletes[idx] = pipeline.athletes[athleteId]
+	}
+
+	withoutConsent, err1 := athleteManagement.GetAthletesWithoutConsent(ctx, athletes, athleteManagement.ConsentScopePublishResults)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	athleteIds := make([]uint, 0, len(withoutConsent))
+	for _, athleteId := range pipeline.athleteIds {
+		if withoutConsent[athleteId] {
+			athleteIds = append(athleteIds, athleteId)
+		}
+	}
+	return athleteIds, nil
+}
@@ -0,0 +1,216 @@
+package performanceManagement
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/swimCertificate"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetMissingRequirements returns what an athlete still needs for the badge of a year
+// @Summary Returns what an athlete still needs for the badge
+// @Description Lists the best medal of the year for each discipline and the exercises that can still be attempted in the disciplines without a gold medal, with the results required for bronze, silver and gold in the athlete's age class.
+// @Description Additionally returns the level that has been reached so far, the disciplines without a medal and if a valid swim certificate is missing.
+// @Tags Performance Management
+// @Produce json
+// @Param AthleteId path int true "Get the missing requirements of the given athlete"
+// @Param year query int false "Year of the badge, defaults to the current year"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} MissingRequirementsResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/performance/missing/{AthleteId} [get]
+func GetMissingRequirements(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetMissingRequirements")
+	defer span.End()
+
+	// Get the athlete id from the context
+	athleteIdString := c.Param("AthleteId")
+	if athleteIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid athlete ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid athlete ID"})
+		return
+	}
+	athleteId, err1 := strconv.ParseUint(athleteIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse athlete ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid athlete ID"})
+		return
+	}
+
+	// Get the year of the badge
+	year := time.Now().Year()
+	if yearString := c.Query("year"); yearString != "" {
+		var err error
+		year, err = strconv.Atoi(yearString)
+		if err != nil || year < 1900 || year > 9999 {
+			err = errors.New("Invalid 'year' query parameter: " + yearString)
+			endpoints.Logger.Debug(ctx, err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'year' query parameter"})
+			return
+		}
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	athlete, err2 := athleteManagement.GetAthlete(ctx, uint(athleteId), trainerEmail)
+	if errors.Is(err2, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete"})
+		return
+	}
+
+	// The requirements depend on the age the athlete reaches in the requested year
+	birthDate, err3 := formatHelper.FormatDate(athlete.BirthDate)
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to parse the birth date")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to parse the birth date"})
+		return
+	}
+	age, err4 := badgeHelper.AgeInYear(birthDate, year)
+	if err4 != nil {
+		err4 = errors.Wrap(err4, "Failed to calculate the age of the athlete")
+		endpoints.Logger.Error(ctx, err4)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete's age"})
+		return
+	}
+
+	// Get the best performance entry of each discipline in the year
+	bestPerformances, err5 := getBestPerformanceBodiesBetween(ctx, uint(athleteId),
+		fmt.Sprintf("%04d-12-31", year-1), fmt.Sprintf("%04d-12-31", year))
+	if err5 != nil {
+		endpoints.Logger.Error(ctx, err5)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the best performance entries"})
+		return
+	}
+
+	// Check if the athlete has a valid swim certificate for the year
+	certificates, err6 := swimCertificate.GetSwimCertificatesOfAthlete(ctx, uint(athleteId))
+	if err6 != nil {
+		endpoints.Logger.Error(ctx, err6)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the swim certificates"})
+		return
+	}
+	hasSwimCertificate := false
+	for _, certificate := range certificates {
+		if badgeHelper.IsSwimCertificateValid(certificate.Date, birthDate, year) {
+			hasSwimCertificate = true
+			break
+		}
+	}
+
+	requirements, err7 := getMissingRequirements(ctx, *bestPerformances, year, age, athlete.Sex, hasSwimCertificate)
+	if err7 != nil {
+		endpoints.Logger.Error(ctx, err7)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the required results"})
+		return
+	}
+	requirements.AthleteId = uint(athleteId)
+
+	c.JSON(
+		http.StatusOK,
+		MissingRequirementsResponse{
+			Message:      "Request successful",
+			Requirements: *requirements,
+		},
+	)
+}
+
+// getMissingRequirements evaluates the best performance entries of each discipline and collects the required results
+// of the exercises in the disciplines without a gold medal
+func getMissingRequirements(ctx context.Context, bestPerformances []PerformanceBodyWithId, year int, age int, sex string, hasSwimCertificate bool) (*MissingRequirementsBody, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetMissingRequirementsOfAthlete")
+	defer span.End()
+
+	// Map the best performance entries to their discipline
+	bestPerDiscipline := make(map[string]PerformanceBodyWithId, len(bestPerformances))
+	medals := make(map[string]string, len(bestPerformances))
+	for _, performance := range bestPerformances {
+		exercise, err := rulesetCache.GetExercise(ctx, performance.ExerciseId)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to get the exercise of a performance entry")
+		}
+		bestPerDiscipline[exercise.DisciplineName] = performance
+		medals[exercise.DisciplineName] = performance.Medal
+	}
+
+	result := badgeHelper.Evaluate(medals, hasSwimCertificate)
+	requirements := MissingRequirementsBody{
+		Year:                   year,
+		Age:                    age,
+		Level:                  result.Level,
+		Points:                 result.Points,
+		MissingDisciplines:     make([]string, 0),
+		MissingSwimCertificate: result.MissingSwimCertificate,
+		Disciplines:            make([]DisciplineProgressBody, 0, len(badgeHelper.Disciplines)),
+	}
+	requirements.MissingDisciplines = append(requirements.MissingDisciplines, result.MissingDisciplines...)
+
+	exercises, err1 := rulesetCache.GetExercises(ctx)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	for _, discipline := range badgeHelper.Disciplines {
+		progress := DisciplineProgressBody{
+			Discipline: discipline,
+			Medal:      result.Medals[discipline],
+			Missing:    result.Medals[discipline] == "",
+			Exercises:  make([]RequiredResultBody, 0),
+		}
+		if performance, exists := bestPerDiscipline[discipline]; exists {
+			progress.BestPerformance = &performance
+		}
+
+		// The exercises are only needed as long as gold has not been reached
+		if progress.Medal != GoldStatus {
+			for _, exercise := range exercises {
+				if exercise.DisciplineName != discipline {
+					continue
+				}
+				exerciseGoal, err2 := getExerciseGoal(ctx, exercise.ID, year, age, sex)
+				if errors.Is(err2, gorm.ErrRecordNotFound) {
+					// The exercise is not available for the athlete's age class in this year
+					continue
+				} else if err2 != nil {
+					return nil, err2
+				}
+				progress.Exercises = append(progress.Exercises, RequiredResultBody{
+					ExerciseId:      exercise.ID,
+					Name:            exercise.Name,
+					Unit:            exercise.Unit,
+					AgeSpecifics:    exerciseGoal.Description,
+					SmallerIsBetter: isSmallerBetter(exerciseGoal.Bronze, exerciseGoal.Gold),
+					Bronze:          exerciseGoal.Bronze,
+					Silver:          exerciseGoal.Silver,
+					Gold:            exerciseGoal.Gold,
+				})
+			}
+		}
+
+		requirements.Disciplines = append(requirements.Disciplines, progress)
+	}
+
+	return &requirements, nil
+}
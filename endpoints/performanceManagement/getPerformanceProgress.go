@@ -0,0 +1,156 @@
+package performanceManagement
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetPerformanceProgress returns the progress of an athlete per exercise
+// @Summary Returns the progress of an athlete per exercise
+// @Description Returns a time series of the performance entries per exercise with chartable values (seconds, meters, centimeters or points), the personal best and the entries that were a new personal best when they were achieved.
+// @Description The trend is a linear regression over the days of the entries. The improvement per month is given in the value unit, positive values are improvements also for exercises where smaller results are better.
+// @Description The medal of the personal best is evaluated in the athlete's current age class. If a better medal is possible, the projection contains its threshold and the date on which the trend reaches it, if it does within two years.
+// @Tags Performance Management
+// @Produce json
+// @Param AthleteId path int true "Get the progress of the given athlete"
+// @Param exercise-id query int false "Only return the progress of the given exercise"
+// @Param since query string false "Date in YYYY-MM-DD format to only use the entries since then (including the entries from that day)"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} PerformanceProgressResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete does not exist"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/performance/progress/{AthleteId} [get]
+func GetPerformanceProgress(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetPerformanceProgress")
+	defer span.End()
+
+	// Get the athlete id from the context
+	athleteIdString := c.Param("AthleteId")
+	if athleteIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid athlete ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid athlete ID"})
+		return
+	}
+	athleteId, err1 := strconv.ParseUint(athleteIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse athlete ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid athlete ID"})
+		return
+	}
+
+	// Get the exercise-id query parameter from the context
+	var exerciseId uint64
+	if exerciseIdString := c.Query("exercise-id"); exerciseIdString != "" {
+		var err error
+		exerciseId, err = strconv.ParseUint(exerciseIdString, 10, 32)
+		if err != nil {
+			err = errors.Wrap(err, "Failed to parse 'exercise-id' query parameter")
+			endpoints.Logger.Debug(ctx, err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'exercise-id' query parameter"})
+			return
+		}
+	}
+
+	// Get the since query parameter from the context
+	since := c.Query("since")
+	if since != "" {
+		if err := formatHelper.IsDate(since); err != nil {
+			err = errors.Wrap(err, "Invalid 'since' query parameter")
+			endpoints.Logger.Debug(ctx, err)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'since' query parameter"})
+			return
+		}
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	athlete, err2 := athleteManagement.GetAthlete(ctx, uint(athleteId), trainerEmail)
+	if errors.Is(err2, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete does not exist"})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete"})
+		return
+	}
+
+	// Calculate the age of the athlete, in the same way as for the medals of new performance entries
+	birthDate, err3 := formatHelper.FormatDate(athlete.BirthDate)
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to parse the birth date")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to parse the birth date"})
+		return
+	}
+	age, err4 := badgeHelper.AgeInYear(birthDate, time.Now().Year())
+	if err4 != nil {
+		err4 = errors.Wrap(err4, "Failed to calculate the age of the athlete")
+		endpoints.Logger.Error(ctx, err4)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete's age"})
+		return
+	}
+
+	// Get the performance entries
+	var performanceBodies *[]PerformanceBodyWithId
+	var err5 error
+	if since != "" {
+		performanceBodies, err5 = getPerformanceBodiesSince(ctx, uint(athleteId), since)
+	} else {
+		performanceBodies, err5 = getAllPerformanceBodies(ctx, uint(athleteId))
+	}
+	if err5 != nil {
+		endpoints.Logger.Error(ctx, err5)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the performance entries"})
+		return
+	}
+	performances := make([]PerformanceBodyWithId, 0, len(*performanceBodies))
+	for _, performance := range *performanceBodies {
+		if exerciseId == 0 || performance.ExerciseId == uint(exerciseId) {
+			performances = append(performances, performance)
+		}
+	}
+
+	exercises, grouped, err6 := groupProgressPerExercise(ctx, performances)
+	if err6 != nil {
+		endpoints.Logger.Error(ctx, err6)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the exercises"})
+		return
+	}
+
+	// Analyse the progress of each exercise
+	progressBodies := make([]ExerciseProgressBody, 0, len(exercises))
+	for _, exercise := range exercises {
+		progress, err7 := getExerciseProgress(ctx, exercise, grouped[exercise.ID], age, athlete.Sex)
+		if err7 != nil {
+			err7 = errors.Wrap(err7, "Failed to analyse the progress of exercise "+exercise.Name)
+			endpoints.Logger.Error(ctx, err7)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to analyse the progress"})
+			return
+		}
+		progressBodies = append(progressBodies, *progress)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		PerformanceProgressResponse{
+			Message:   "Request successful",
+			AthleteId: uint(athleteId),
+			Exercises: progressBodies,
+		},
+	)
+}
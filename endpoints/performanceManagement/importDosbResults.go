@@ -0,0 +1,206 @@
+package performanceManagement
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/csvHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/dosbHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/exerciseManagement"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// dosbImporter imports result lists in the format of the DOSB Sportabzeichen portal.
+// The layout of the format is fixed, so it does not support import profiles.
+var dosbImporter = importJobs.Importer{
+	Type:          "dosb_performances",
+	ValidateRow:   validateDosbRow,
+	CreateEntries: createPerformanceEntries,
+	Preview:       previewPerformanceEntry,
+	Note:          notePerformanceEntry,
+}
+
+func init() {
+	importJobs.RegisterImporter(dosbImporter)
+}
+
+// ImportDosbResults creates performance entries from a result list of the DOSB Sportabzeichen portal
+// @Summary Imports a result list in the DOSB format
+// @Description Upload a result list exported by the DOSB Sportabzeichen portal (Name;Vorname;Geschlecht;Geburtsjahr;Geburtsdatum;Übung;Kategorie;Datum;Ergebnis;Punkte). Dates can be written as DD.MM.YYYY, D.M.YYYY or DD.MM.YY, results use a decimal comma and Punkte is the medal code (1 bronze, 2 silver, 3 gold).
+// @Description The medals are evaluated again with the rulesets of this system; rows whose medal differs from the file are imported with a note. Rows without a medal are skipped.
+// @Description The file is imported in the background like the csv imports. With dry_run, only the preview of every row is created in the background and can be polled with the import job.
+// @Tags Performance Management
+// @Accept multipart/form-data
+// @Produce json
+// @Param Performances formData file true "Result list in the DOSB format"
+// @Param dry_run query bool false "Only validate the file, the preview of every row can be polled with the import job"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 202 {object} importJobs.ImportJobResponse "Import started, or the preview with dry_run"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/performance/dosb-import [post]
+func ImportDosbResults(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "ImportDosbResults")
+	defer span.End()
+
+	// Check if only a preview is requested
+	dryRun := false
+	if dryRunString := c.Query("dry_run"); dryRunString != "" {
+		var err0 error
+		dryRun, err0 = strconv.ParseBool(dryRunString)
+		if err0 != nil {
+			err0 = errors.Wrap(err0, "Invalid 'dry_run' query parameter")
+			endpoints.Logger.Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'dry_run' query parameter"})
+			return
+		}
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	file, err1 := c.FormFile("Performances")
+	if err1 != nil || file == nil {
+		err1 = errors.Wrap(err1, "Failed to get the file")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File is missing or invalid"})
+		return
+	}
+	if err2 := csvHelper.CheckFileType(file); err2 != nil {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err2.Error()})
+		return
+	}
+
+	// Read the file with the delimiter and the header of the format
+	csvOptions := csvHelper.Options{Delimiter: dosbHelper.Delimiter, DetectHeader: true, HeaderNames: dosbHelper.Header}
+	csvFile, err3 := csvHelper.ReadUpload(file, csvOptions)
+	if errors.Is(err3, csvHelper.EmptyFileError) {
+		endpoints.Logger.Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err3.Error()})
+		return
+	} else if err3 != nil {
+		endpoints.Logger.Warn(ctx, errors.Wrap(err3, "Failed to read the file"))
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File could not be read. Invalid DOSB format?"})
+		return
+	}
+	endpoints.Logger.Debug(ctx, fmt.Sprintf("Read %s file with encoding %s", csvFile.Format, csvFile.Encoding))
+	records := csvFile.Records
+
+	// Only validate the rows in the background, the preview can be polled with the import job
+	if dryRun {
+		previewJob, errPreview := importJobs.Preview(ctx, dosbImporter, trainerEmail, file.Filename, records)
+		if errPreview != nil {
+			endpoints.Logger.Error(ctx, errPreview)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to preview the import"})
+			return
+		}
+
+		c.JSON(
+			http.StatusAccepted,
+			importJobs.ImportJobResponse{
+				Message:   "Preview started",
+				ImportJob: importJobs.TranslateImportJobToResponse(*previewJob),
+			},
+		)
+		return
+	}
+
+	// Import the rows in the background
+	importJob, err4 := importJobs.Start(ctx, dosbImporter, trainerEmail, file.Filename, records)
+	if err4 != nil {
+		endpoints.Logger.Error(ctx, err4)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to start the import"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, importJobs.ImportJobResponse{
+		Message:   "Import started",
+		ImportJob: importJobs.TranslateImportJobToResponse(*importJob),
+	})
+}
+
+// validateDosbRow validates a single row of a DOSB result list and returns the performance entry to create
+func validateDosbRow(ctx context.Context, trainerEmail string, record []string) (interface{}, *importJobs.RowError) {
+	entry, err1 := dosbHelper.ParseRecord(record)
+	if errors.Is(err1, dosbHelper.NoMedalError) {
+		return nil, importJobs.RowSkipped("No medal achieved")
+	} else if err1 != nil {
+		endpoints.Logger.Debug(ctx, err1)
+		return nil, importJobs.RowFailed(err1.Error())
+	}
+
+	if err2 := formatHelper.IsFuture(entry.Date); err2 != nil {
+		return nil, importJobs.RowFailed("Invalid date. Date is in the future")
+	}
+
+	exercise, err3 := exerciseManagement.GetExerciseByNameAndDiscipline(ctx, entry.Exercise, entry.Discipline)
+	if err3 != nil {
+		endpoints.Logger.Debug(ctx, err3)
+		return nil, importJobs.RowFailed("Exercise not found")
+	}
+
+	points, err4 := dosbHelper.ParseResult(entry.Result, exercise.Unit)
+	if err4 != nil {
+		endpoints.Logger.Debug(ctx, err4)
+		return nil, importJobs.RowFailed(err4.Error())
+	}
+
+	athlete, err5 := athleteManagement.GetAthleteByDetails(ctx, entry.FirstName, entry.LastName, entry.BirthDate, trainerEmail)
+	if err5 != nil {
+		endpoints.Logger.Debug(ctx, err5)
+		return nil, importJobs.RowFailed("Athlete not found")
+	}
+
+	age, err6 := badgeHelper.AgeOnDate(entry.BirthDate, entry.Date)
+	if err6 != nil {
+		endpoints.Logger.Debug(ctx, err6)
+		return nil, importJobs.RowFailed("Age could not be calculated")
+	}
+
+	// The medal is evaluated with the rulesets of this system, a differing medal of the file is reported
+	medalStatus, err7 := evaluateMedalStatus(ctx, exercise.ID, entry.Date, age, athlete.Sex, points)
+	if err7 != nil {
+		endpoints.Logger.Debug(ctx, err7)
+		return nil, importJobs.RowFailed("Could not evaluate medal status")
+	}
+	fileMedal, note := "", ""
+	if medalStatus != entry.Medal {
+		fileMedal = entry.Medal
+		note = fmt.Sprintf("Medal in file: %s, evaluated: %s", entry.Medal, medalStatus)
+	}
+
+	performanceEntry := databaseUtils.Performance{
+		AthleteId:  athlete.ID,
+		ExerciseId: exercise.ID,
+		Date:       entry.Date,
+		Points:     points,
+		Medal:      medalStatus,
+	}
+	return &performanceImportEntry{
+		performance: performanceEntry,
+		note:        note,
+		preview: PerformancePreviewBody{
+			AthleteId:    athlete.ID,
+			AthleteName:  athlete.FirstName + " " + athlete.LastName,
+			ExerciseId:   exercise.ID,
+			ExerciseName: exercise.Name,
+			Unit:         exercise.Unit,
+			Points:       points,
+			Medal:        medalStatus,
+			FileMedal:    fileMedal,
+			Date:         entry.Date,
+		},
+	}, nil
+}
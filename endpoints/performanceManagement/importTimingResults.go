@@ -0,0 +1,355 @@
+package performanceManagement
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/csvHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/Team-Reissdorf/Backend/timingHelper"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// timingResultColumnCount is the number of columns of the records created from the results
+const timingResultColumnCount = 7
+
+// timingResultImporter imports the results of a timing system file, which have been converted to records by ImportTimingResults
+var timingResultImporter = importJobs.Importer{
+	Type:           "timing_results",
+	ValidateRow:    validateTimingResultRow,
+	CreateEntries:  createPerformanceEntries,
+	Fields:         []string{"athlete_id", "bib", "last_name", "first_name", "time", "exercise_id", "date"},
+	RequiredFields: []string{"time", "exercise_id", "date"},
+	DateFields:     []string{"date"},
+	Preview:        previewPerformanceEntry,
+	Note:           notePerformanceEntry,
+}
+
+func init() {
+	importJobs.RegisterImporter(timingResultImporter)
+}
+
+// ImportTimingResults creates performance entries from the result file of an electronic timing system
+// @Summary Imports the results of a timing system file (FinishLynx .lif/.evt)
+// @Description Upload the result file of a heat (.lif) or an event file with times (.evt) to create performance entries for the given exercise and date. The exercise has to be measured in seconds or minutes.
+// @Description The competitors are mapped to athletes by their bib number using bib_numbers, otherwise by their first and last name. Results without a valid time (e.g. DNF, DNS) are skipped.
+// @Description The file is imported in the background like the csv imports. With dry_run, only the preview of every result is created in the background and can be polled with the import job.
+// @Tags Performance Management
+// @Accept multipart/form-data
+// @Produce json
+// @Param Results formData file true "FinishLynx result file (.lif or .evt)"
+// @Param bib_numbers formData string false "JSON object mapping bib numbers to athlete ids, e.g. {\"123\": 1}"
+// @Param exercise_id query int true "Exercise of the results"
+// @Param date query string true "Date of the results (YYYY-MM-DD)"
+// @Param event query string false "Number of the event to import, required if the file contains several events"
+// @Param dry_run query bool false "Only validate the file, the preview of every result can be polled with the import job"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 202 {object} importJobs.ImportJobResponse "Import started, or the preview with dry_run"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Exercise or event not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/performance/timing-import [post]
+func ImportTimingResults(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "ImportTimingResults")
+	defer span.End()
+
+	// Check if only a preview is requested
+	dryRun := false
+	if dryRunString := c.Query("dry_run"); dryRunString != "" {
+		var err0 error
+		dryRun, err0 = strconv.ParseBool(dryRunString)
+		if err0 != nil {
+			err0 = errors.Wrap(err0, "Invalid 'dry_run' query parameter")
+			endpoints.Logger.Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'dry_run' query parameter"})
+			return
+		}
+	}
+
+	// Get the exercise and the date of the results
+	exerciseId, err1 := strconv.ParseUint(c.Query("exercise_id"), 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse exercise ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid exercise ID"})
+		return
+	}
+	date := c.Query("date")
+	if err2 := formatHelper.IsDate(date); err2 != nil {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid date format"})
+		return
+	} else if err2 = formatHelper.IsFuture(date); err2 != nil {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Date is in the future"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Only exercises measured in time can be imported from a timing system
+	exercise, err3 := getExerciseById(ctx, uint(exerciseId))
+	if errors.Is(err3, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Exercise not found"})
+		return
+	} else if err3 != nil {
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the exercise"})
+		return
+	}
+	if !isTimeUnit(exercise.Unit) {
+		err := errors.New("The exercise is not measured in time")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// Get the mapping of bib numbers to athletes
+	bibNumbers := make(map[string]uint)
+	if bibNumbersString := c.PostForm("bib_numbers"); bibNumbersString != "" {
+		if err4 := json.Unmarshal([]byte(bibNumbersString), &bibNumbers); err4 != nil {
+			err4 = errors.Wrap(err4, "Failed to parse the bib numbers")
+			endpoints.Logger.Debug(ctx, err4)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid bib numbers, expected a JSON object mapping bib numbers to athlete ids"})
+			return
+		}
+	}
+
+	// Read the result file
+	file, err5 := c.FormFile("Results")
+	if err5 != nil || file == nil {
+		err5 = errors.Wrap(err5, "Failed to get the file")
+		endpoints.Logger.Debug(ctx, err5)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File is missing or invalid"})
+		return
+	}
+	format, err6 := timingHelper.FormatOf(file.Filename)
+	if err6 != nil {
+		endpoints.Logger.Debug(ctx, err6)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err6.Error()})
+		return
+	}
+	fileContent, err7 := file.Open()
+	if err7 != nil {
+		err7 = errors.Wrap(err7, "Failed to open file")
+		endpoints.Logger.Debug(ctx, err7)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Could not open file"})
+		return
+	}
+	defer fileContent.Close()
+
+	events, err8 := timingHelper.Read(fileContent, format)
+	if errors.Is(err8, timingHelper.InvalidResultFileError) || errors.Is(err8, csvHelper.EmptyFileError) {
+		endpoints.Logger.Debug(ctx, err8)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err8.Error()})
+		return
+	} else if err8 != nil {
+		err8 = errors.Wrap(err8, "Failed to read the result file")
+		endpoints.Logger.Warn(ctx, err8)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File could not be read. Invalid result file?"})
+		return
+	}
+
+	// Select the event to import
+	eventNumber := c.Query("event")
+	if eventNumber == "" && len(events) > 1 {
+		err := errors.New("The file contains several events, select one with the 'event' query parameter")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
+		return
+	}
+	var event *timingHelper.Event
+	for idx := range events {
+		if eventNumber == "" || events[idx].Number == eventNumber {
+			event = &events[idx]
+			break
+		}
+	}
+	if event == nil {
+		err := errors.New("Event " + eventNumber + " not found in the file")
+		endpoints.Logger.Debug(ctx, err)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Event not found"})
+		return
+	}
+
+	// Convert the results to records, so that they are processed like the rows of a csv import
+	records := make([][]string, len(event.Results))
+	for idx, result := range event.Results {
+		athleteId := ""
+		if id, exists := bibNumbers[result.Id]; exists {
+			athleteId = strconv.FormatUint(uint64(id), 10)
+		}
+		records[idx] = []string{athleteId, result.Id, result.LastName, result.FirstName, result.Time, strconv.FormatUint(exerciseId, 10), date}
+	}
+	endpoints.Logger.Debug(ctx, fmt.Sprintf("Read %d results of event %s %s", len(records), event.Number, event.Name))
+
+	// Only validate the results in the background, the preview can be polled with the import job
+	if dryRun {
+		previewJob, errPreview := importJobs.Preview(ctx, timingResultImporter, trainerEmail, file.Filename, records)
+		if errPreview != nil {
+			endpoints.Logger.Error(ctx, errPreview)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to preview the import"})
+			return
+		}
+
+		c.JSON(
+			http.StatusAccepted,
+			importJobs.ImportJobResponse{
+				Message:   "Preview started",
+				ImportJob: importJobs.TranslateImportJobToResponse(*previewJob),
+			},
+		)
+		return
+	}
+
+	// Import the results in the background
+	importJob, err9 := importJobs.Start(ctx, timingResultImporter, trainerEmail, file.Filename, records)
+	if err9 != nil {
+		endpoints.Logger.Error(ctx, err9)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to start the import"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, importJobs.ImportJobResponse{
+		Message:   "Import started",
+		ImportJob: importJobs.TranslateImportJobToResponse(*importJob),
+	})
+}
+
+// validateTimingResultRow validates a single result of a timing system file and returns the performance entry to create
+func validateTimingResultRow(ctx context.Context, trainerEmail string, record []string) (interface{}, *importJobs.RowError) {
+	if len(record) != timingResultColumnCount {
+		return nil, importJobs.RowFailed("Invalid column count")
+	}
+	athleteIdString, bib, lastName, firstName := record[0], record[1], record[2], record[3]
+	timeString, exerciseIdString, date := record[4], record[5], record[6]
+
+	// Results without a time are not imported
+	duration, err1 := timingHelper.ParseTime(timeString)
+	if errors.Is(err1, timingHelper.NoTimeError) {
+		return nil, importJobs.RowSkipped("No valid time: " + strings.ToUpper(timeString))
+	} else if err1 != nil {
+		endpoints.Logger.Debug(ctx, err1)
+		return nil, importJobs.RowFailed("Invalid time: " + timeString)
+	}
+
+	exerciseId, err2 := strconv.ParseUint(exerciseIdString, 10, 32)
+	if err2 != nil {
+		return nil, importJobs.RowFailed("Invalid exercise ID")
+	}
+	exercise, err3 := getExerciseById(ctx, uint(exerciseId))
+	if err3 != nil {
+		endpoints.Logger.Debug(ctx, err3)
+		return nil, importJobs.RowFailed("Exercise not found")
+	}
+	if !isTimeUnit(exercise.Unit) {
+		return nil, importJobs.RowFailed("The exercise is not measured in time")
+	}
+
+	if err4 := formatHelper.IsDate(date); err4 != nil {
+		return nil, importJobs.RowFailed("Invalid date")
+	} else if err4 = formatHelper.IsFuture(date); err4 != nil {
+		return nil, importJobs.RowFailed("Invalid date. Date is in the future")
+	}
+
+	// Find the athlete by the bib number or by the name
+	var athlete *databaseUtils.Athlete
+	if athleteIdString != "" {
+		athleteId, err5 := strconv.ParseUint(athleteIdString, 10, 32)
+		if err5 != nil {
+			return nil, importJobs.RowFailed("Invalid athlete ID of bib " + bib)
+		}
+		var err6 error
+		athlete, err6 = athleteManagement.GetAthlete(ctx, uint(athleteId), trainerEmail)
+		if err6 != nil {
+			endpoints.Logger.Debug(ctx, err6)
+			return nil, importJobs.RowFailed("Athlete of bib " + bib + " not found")
+		}
+	} else {
+		athletes, err7 := athleteManagement.GetAthletesByName(ctx, firstName, lastName, trainerEmail)
+		if err7 != nil {
+			endpoints.Logger.Error(ctx, err7)
+			return nil, importJobs.RowFailed("Could not search the athlete")
+		}
+		if len(athletes) == 0 {
+			return nil, importJobs.RowFailed("Athlete not found")
+		} else if len(athletes) > 1 {
+			return nil, importJobs.RowFailed("Several athletes have this name, map the bib number " + bib + " instead")
+		}
+		athlete = &athletes[0]
+	}
+
+	// Calculate the age of the athlete
+	birthDate, err8 := formatHelper.FormatDate(athlete.BirthDate)
+	if err8 != nil {
+		endpoints.Logger.Debug(ctx, err8)
+		return nil, importJobs.RowFailed("Invalid birth date of the athlete")
+	}
+	age, err9 := badgeHelper.AgeOnDate(birthDate, date)
+	if err9 != nil {
+		endpoints.Logger.Debug(ctx, err9)
+		return nil, importJobs.RowFailed("Age could not be calculated")
+	}
+
+	// The points of time units are milliseconds
+	points := uint64(duration.Milliseconds())
+	medalStatus, err10 := evaluateMedalStatus(ctx, exercise.ID, date, age, athlete.Sex, points)
+	if err10 != nil {
+		endpoints.Logger.Debug(ctx, err10)
+		return nil, importJobs.RowFailed("Could not evaluate medal status")
+	}
+
+	performanceEntry := databaseUtils.Performance{
+		AthleteId:  athlete.ID,
+		ExerciseId: exercise.ID,
+		Date:       date,
+		Points:     points,
+		Medal:      medalStatus,
+	}
+	return &performanceImportEntry{
+		performance: performanceEntry,
+		preview: PerformancePreviewBody{
+			AthleteId:    athlete.ID,
+			AthleteName:  athlete.FirstName + " " + athlete.LastName,
+			ExerciseId:   exercise.ID,
+			ExerciseName: exercise.Name,
+			Unit:         exercise.Unit,
+			Points:       points,
+			Medal:        medalStatus,
+			Date:         date,
+		},
+	}, nil
+}
+
+// getExerciseById returns the exercise with the given id from the ruleset cache
+// Throws: gorm.ErrRecordNotFound if the exercise does not exist
+func getExerciseById(ctx context.Context, exerciseId uint) (*databaseUtils.Exercise, error) {
+	exercise, err1 := rulesetCache.GetExercise(ctx, exerciseId)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the exercise")
+		return nil, err1
+	}
+
+	return &exercise, nil
+}
+
+// isTimeUnit checks if the results of the unit are durations stored in milliseconds
+func isTimeUnit(unit string) bool {
+	return unit == "second" || unit == "minute"
+}
@@ -23,3 +23,122 @@ type PerformanceBodyEdit struct {
 	Date          string `json:"date" example:"YYYY-MM-DD"`
 	ExerciseId    uint   `json:"exercise_id" example:"1"`
 }
+
+type PerformancePreviewBody struct {
+	AthleteId    uint   `json:"athlete_id" example:"1"`
+	AthleteName  string `json:"athlete_name" example:"Bob Alice"`
+	NewAthlete   bool   `json:"new_athlete" example:"false"` // The athlete does not exist yet and is created by the import
+	ExerciseId   uint   `json:"exercise_id" example:"1"`
+	ExerciseName string `json:"exercise_name" example:"Sprint 100m"`
+	Unit         string `json:"unit" example:"second"`
+	Points       uint64 `json:"points" example:"14500"`
+	Medal        string `json:"medal" example:"gold"`
+	FileMedal    string `json:"file_medal,omitempty" example:"silver"` // Medal of the imported file if it differs from the evaluated medal
+	Date         string `json:"date" example:"YYYY-MM-DD"`
+}
+
+type RequiredResultBody struct {
+	ExerciseId      uint   `json:"exercise_id" example:"1"`
+	Name            string `json:"name" example:"Sprint 50m"`
+	Unit            string `json:"unit" example:"second"`
+	AgeSpecifics    string `json:"age_specifics" example:"Hochstart"`
+	SmallerIsBetter bool   `json:"smaller_is_better" example:"true"`
+	Bronze          uint64 `json:"bronze" example:"9800"` // Time: ms; Distance: cm; Points; Bool: <0|1>
+	Silver          uint64 `json:"silver" example:"9000"`
+	Gold            uint64 `json:"gold" example:"8200"`
+}
+
+type DisciplineProgressBody struct {
+	Discipline      string                 `json:"discipline" example:"Schnelligkeit"`
+	Medal           string                 `json:"medal" example:"<bronze|silver|gold>"` // Best medal of the year, empty if none
+	Missing         bool                   `json:"missing" example:"false"`
+	BestPerformance *PerformanceBodyWithId `json:"best_performance,omitempty"`
+	Exercises       []RequiredResultBody   `json:"exercises"` // Exercises to attempt for a better medal, empty for gold
+}
+
+type MissingRequirementsBody struct {
+	AthleteId              uint                     `json:"athlete_id" example:"1"`
+	Year                   int                      `json:"year" example:"2025"`
+	Age                    int                      `json:"age" example:"12"`
+	Level                  string                   `json:"level" example:"<bronze|silver|gold>"` // Empty if the badge has not been earned yet
+	Points                 int                      `json:"points" example:"7"`
+	MissingDisciplines     []string                 `json:"missing_disciplines"`
+	MissingSwimCertificate bool                     `json:"missing_swim_certificate" example:"false"`
+	Disciplines            []DisciplineProgressBody `json:"disciplines"`
+}
+
+type MissingRequirementsResponse struct {
+	Message      string                  `json:"message" example:"Request successful"`
+	Requirements MissingRequirementsBody `json:"requirements"`
+}
+
+type MedalCalculationRequest struct {
+	ExerciseId uint   `json:"exercise_id" example:"1"`
+	Year       int    `json:"year,omitempty" example:"2025"`             // Defaults to the current year
+	Age        *int   `json:"age,omitempty" example:"12"`                // Either the age or the birth date is required
+	BirthDate  string `json:"birth_date,omitempty" example:"YYYY-MM-DD"` // The age is the one reached in the year
+	Sex        string `json:"sex" example:"<m|f|d>"`
+	Result     string `json:"result" example:"3:45"`
+}
+
+type MedalCalculationBody struct {
+	ExerciseId          uint   `json:"exercise_id" example:"1"`
+	Exercise            string `json:"exercise" example:"800 m Lauf"`
+	Unit                string `json:"unit" example:"minute"`
+	Year                int    `json:"year" example:"2025"`
+	Age                 int    `json:"age" example:"12"`
+	Sex                 string `json:"sex" example:"f"`
+	Points              uint64 `json:"points" example:"225000"` // Normalized result, Time: ms; Distance: cm; Points; Bool: <0|1>
+	Medal               string `json:"medal" example:"<bronze|silver|gold>"`
+	FromAge             uint   `json:"from_age" example:"12"`
+	ToAge               uint   `json:"to_age" example:"13"`
+	Bronze              uint64 `json:"bronze" example:"270000"`
+	Silver              uint64 `json:"silver" example:"240000"`
+	Gold                uint64 `json:"gold" example:"210000"`
+	AgeSpecifics        string `json:"age_specifics" example:""`
+	SmallerIsBetter     bool   `json:"smaller_is_better" example:"true"`
+	NextLevel           string `json:"next_level,omitempty" example:"<bronze|silver|gold>"` // Empty for gold
+	DistanceToNextLevel uint64 `json:"distance_to_next_level" example:"15000"`              // In the unit of the points
+}
+
+type MedalCalculationResponse struct {
+	Message     string               `json:"message" example:"Request successful"`
+	Calculation MedalCalculationBody `json:"calculation"`
+}
+
+type ProgressPointBody struct {
+	PerformanceId uint    `json:"performance_id" example:"1"`
+	Date          string  `json:"date" example:"YYYY-MM-DD"`
+	Points        uint64  `json:"points" example:"8400"` // Time: ms; Distance: cm; Points; Bool: <0|1>
+	Value         float64 `json:"value" example:"8.4"`   // Points in the value unit of the series
+	Medal         string  `json:"medal" example:"<bronze|silver|gold>"`
+	PersonalBest  bool    `json:"personal_best" example:"true"` // The entry was a new personal best when it was achieved
+}
+
+type ProgressProjectionBody struct {
+	NextLevel      string  `json:"next_level" example:"<bronze|silver|gold>"`
+	Threshold      uint64  `json:"threshold" example:"8200"`
+	ThresholdValue float64 `json:"threshold_value" example:"8.2"`
+	ProjectedDate  string  `json:"projected_date,omitempty" example:"YYYY-MM-DD"` // Empty if the trend does not reach the threshold within two years
+}
+
+type ExerciseProgressBody struct {
+	ExerciseId          uint                    `json:"exercise_id" example:"1"`
+	Exercise            string                  `json:"exercise" example:"Sprint 50m"`
+	Discipline          string                  `json:"discipline" example:"Schnelligkeit"`
+	Unit                string                  `json:"unit" example:"second"`
+	ValueUnit           string                  `json:"value_unit" example:"<s|m|cm|points|bool>"`
+	SmallerIsBetter     bool                    `json:"smaller_is_better" example:"true"`
+	Series              []ProgressPointBody     `json:"series"` // Ordered by date
+	PersonalBest        ProgressPointBody       `json:"personal_best"`
+	ImprovementPerMonth float64                 `json:"improvement_per_month" example:"0.12"` // In the value unit, positive values are improvements
+	Trend               string                  `json:"trend" example:"<improving|stable|declining|insufficient_data>"`
+	Medal               string                  `json:"medal" example:"<bronze|silver|gold>"` // Medal of the personal best in the current age class
+	Projection          *ProgressProjectionBody `json:"projection,omitempty"`                 // Only set if there is a better medal in the current age class
+}
+
+type PerformanceProgressResponse struct {
+	Message   string                 `json:"message" example:"Request successful"`
+	AthleteId uint                   `json:"athlete_id" example:"1"`
+	Exercises []ExerciseProgressBody `json:"exercises"`
+}
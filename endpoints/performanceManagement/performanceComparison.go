@@ -2,12 +2,12 @@ package performanceManagement
 
 import (
 	"context"
-	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
 	"github.com/pkg/errors"
-	"strconv"
 	"time"
 )
 
@@ -59,22 +59,13 @@ func getPerformanceYear(ctx context.Context, performanceDate string) (int, error
 	return performanceYear, nil
 }
 
-// getExerciseGoal gets the exercise goal from the database based on the given parameters
+// getExerciseGoal gets the exercise goal from the ruleset cache based on the given parameters
+// Throws: gorm.ErrRecordNotFound if there is no matching goal
 func getExerciseGoal(ctx context.Context, exerciseId uint, performanceYear int, age int, sex string) (databaseUtils.ExerciseGoal, error) {
 	ctx, span := endpoints.Tracer.Start(ctx, "GetExerciseGoal")
 	defer span.End()
 
-	var exerciseGoal databaseUtils.ExerciseGoal
-
-	err := DatabaseFlow.GetDB(ctx).Model(&databaseUtils.ExerciseGoal{}).
-		Joins("JOIN exercise_rulesets ON exercise_rulesets.id = exercise_goals.ruleset_id").
-		Where("exercise_rulesets.ruleset_year = ? AND exercise_rulesets.exercise_id = ? AND "+
-			"exercise_goals.from_age <= ? AND exercise_goals.to_age >= ? AND exercise_goals.sex = ?",
-			strconv.Itoa(performanceYear), exerciseId, age, age, sex).
-		First(&exerciseGoal).
-		Error
-
-	return exerciseGoal, err
+	return rulesetCache.GetExerciseGoal(ctx, exerciseId, performanceYear, age, sex)
 }
 
 // getBestPerformanceEntry returns the best performance entry of the given list.
@@ -102,15 +93,16 @@ func getBestPerformanceEntry(ctx context.Context, performances *[]PerformanceBod
 	if len((*athlete).BirthDate) < 10 {
 		return nil, errors.New("Invalid BirthDate: must be at least 10 characters long")
 	}
-	age, err1 := athleteManagement.CalculateAge(ctx, (*athlete).BirthDate[:10])
+	// Parse the performance year
+	performanceYear, err1 := getPerformanceYear(ctx, (*performances)[0].Date)
 	if err1 != nil {
-		err1 = errors.New("Failed to calculate age for best performance entry")
 		return nil, err1
 	}
 
-	// Parse the performance year
-	performanceYear, err2 := getPerformanceYear(ctx, (*performances)[0].Date)
+	// The age class is decided by the age in the year of the performance
+	age, err2 := badgeHelper.AgeInYear((*athlete).BirthDate, performanceYear)
 	if err2 != nil {
+		err2 = errors.New("Failed to calculate age for best performance entry")
 		return nil, err2
 	}
 
@@ -124,13 +116,14 @@ func getBestPerformanceEntry(ctx context.Context, performances *[]PerformanceBod
 	// Check if smaller is better
 	smallerBetter := isSmallerBetter(exerciseGoal.Bronze, exerciseGoal.Gold)
 
-	// Get the best performance entry
-	bestPerformanceEntry := (*performances)[0]
-	for idx, performanceEntry := range *performances {
-		if idx == 0 {
-			continue
-		}
+	bestPerformanceEntry := pickBestPerformanceEntry(*performances, smallerBetter)
+	return &bestPerformanceEntry, nil
+}
 
+// pickBestPerformanceEntry returns the best entry of the given non-empty list, the later one wins a tie
+func pickBestPerformanceEntry(performances []PerformanceBodyWithId, smallerBetter bool) PerformanceBodyWithId {
+	bestPerformanceEntry := performances[0]
+	for _, performanceEntry := range performances[1:] {
 		// Check if this performance entry is better than the last best
 		better := isLeftBetter(performanceEntry.Points, bestPerformanceEntry.Points, smallerBetter)
 		if better {
@@ -138,5 +131,5 @@ func getBestPerformanceEntry(ctx context.Context, performances *[]PerformanceBod
 		}
 	}
 
-	return &bestPerformanceEntry, nil
+	return bestPerformanceEntry
 }
@@ -0,0 +1,216 @@
+package performanceManagement
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	ImprovingTrend        = "improving"
+	StableTrend           = "stable"
+	DecliningTrend        = "declining"
+	InsufficientDataTrend = "insufficient_data"
+)
+
+const (
+	daysPerMonth      = 30.44
+	stableTrendRate   = 0.005 // Monthly improvements below 0.5 % of the average value count as stable
+	maxProjectionDays = 730   // Projections further in the future are not reliable enough to be returned
+	minTrendDays      = 1     // A trend needs entries on at least two different days
+)
+
+// valueOfPoints converts the stored points to a chartable value and returns its unit
+func valueOfPoints(points uint64, unit string) (float64, string) {
+	switch unit {
+	case "second", "minute":
+		return float64(points) / 1000, "s"
+	case "meter":
+		return float64(points) / 100, "m"
+	case "centimeter":
+		return float64(points), "cm"
+	case "bool":
+		return float64(points), "bool"
+	default:
+		return float64(points), "points"
+	}
+}
+
+// getExerciseProgress analyses the performance entries of an exercise, which need to be ordered by date.
+// The medals are evaluated with the exercise goal of the current age class, if there is one.
+func getExerciseProgress(ctx context.Context, exercise databaseUtils.Exercise, performances []PerformanceBodyWithId, age int, sex string) (*ExerciseProgressBody, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetExerciseProgress")
+	defer span.End()
+
+	progress := ExerciseProgressBody{
+		ExerciseId: exercise.ID,
+		Exercise:   exercise.Name,
+		Discipline: exercise.DisciplineName,
+		Unit:       exercise.Unit,
+		Series:     make([]ProgressPointBody, len(performances)),
+	}
+
+	// Get the goal of the current age class, which also decides whether smaller results are better
+	now := time.Now()
+	exerciseGoal, err1 := getExerciseGoal(ctx, exercise.ID, now.Year(), age, sex)
+	hasGoal := err1 == nil
+	if errors.Is(err1, gorm.ErrRecordNotFound) {
+		progress.SmallerIsBetter = exercise.Unit == "second" || exercise.Unit == "minute"
+	} else if err1 != nil {
+		return nil, err1
+	} else {
+		progress.SmallerIsBetter = isSmallerBetter(exerciseGoal.Bronze, exerciseGoal.Gold)
+	}
+
+	// Build the series and mark the personal bests
+	for idx, performance := range performances {
+		point := ProgressPointBody{
+			PerformanceId: performance.PerformanceId,
+			Date:          performance.Date,
+			Points:        performance.Points,
+			Medal:         performance.Medal,
+		}
+		point.Value, progress.ValueUnit = valueOfPoints(performance.Points, exercise.Unit)
+		// Only a strictly better result is a new personal best
+		if idx == 0 || (performance.Points != progress.PersonalBest.Points &&
+			isLeftBetter(performance.Points, progress.PersonalBest.Points, progress.SmallerIsBetter)) {
+			point.PersonalBest = true
+			progress.PersonalBest = point
+		}
+		progress.Series[idx] = point
+	}
+
+	// Calculate the trend with a linear regression of the values over the days
+	firstDay, err2 := time.Parse(time.DateOnly, performances[0].Date)
+	if err2 != nil {
+		return nil, errors.Wrap(err2, "Failed to parse the date of a performance entry")
+	}
+	intercept, slope, hasTrend, err3 := linearRegression(progress.Series, firstDay)
+	if err3 != nil {
+		return nil, err3
+	}
+	direction := 1.0
+	if progress.SmallerIsBetter {
+		direction = -1
+	}
+	progress.Trend = InsufficientDataTrend
+	if hasTrend {
+		progress.ImprovementPerMonth = direction * slope * daysPerMonth
+		average := 0.0
+		for _, point := range progress.Series {
+			average += point.Value
+		}
+		average /= float64(len(progress.Series))
+
+		switch {
+		case math.Abs(progress.ImprovementPerMonth) <= stableTrendRate*math.Abs(average):
+			progress.Trend = StableTrend
+		case progress.ImprovementPerMonth > 0:
+			progress.Trend = ImprovingTrend
+		default:
+			progress.Trend = DecliningTrend
+		}
+	}
+
+	if !hasGoal {
+		return &progress, nil
+	}
+
+	// Evaluate the personal best in the current age class and project the next medal
+	progress.Medal = getMedalStatus(ctx, exerciseGoal, progress.PersonalBest.Points)
+	nextLevel, _ := getDistanceToNextLevel(exerciseGoal, progress.Medal, progress.PersonalBest.Points)
+	if nextLevel == "" {
+		return &progress, nil
+	}
+	projection := ProgressProjectionBody{NextLevel: nextLevel}
+	switch nextLevel {
+	case GoldStatus:
+		projection.Threshold = exerciseGoal.Gold
+	case SilverStatus:
+		projection.Threshold = exerciseGoal.Silver
+	default:
+		projection.Threshold = exerciseGoal.Bronze
+	}
+	projection.ThresholdValue, _ = valueOfPoints(projection.Threshold, exercise.Unit)
+
+	if progress.Trend == ImprovingTrend {
+		// Day on which the regression line reaches the threshold
+		thresholdDay := (projection.ThresholdValue - intercept) / slope
+		today := now.Sub(firstDay).Hours() / 24
+		if thresholdDay < today {
+			thresholdDay = today
+		}
+		if thresholdDay-today <= maxProjectionDays {
+			projection.ProjectedDate = firstDay.AddDate(0, 0, int(math.Ceil(thresholdDay))).Format(time.DateOnly)
+		}
+	}
+	progress.Projection = &projection
+
+	return &progress, nil
+}
+
+// linearRegression fits a line to the values of the series over the days since the first day.
+// Returns false if the entries are not spread over at least two days.
+func linearRegression(series []ProgressPointBody, firstDay time.Time) (float64, float64, bool, error) {
+	days := make([]float64, len(series))
+	var sumDays, sumValues float64
+	for idx, point := range series {
+		day, err := time.Parse(time.DateOnly, point.Date)
+		if err != nil {
+			return 0, 0, false, errors.Wrap(err, "Failed to parse the date of a performance entry")
+		}
+		days[idx] = day.Sub(firstDay).Hours() / 24
+		sumDays += days[idx]
+		sumValues += point.Value
+	}
+	count := float64(len(series))
+	meanDay, meanValue := sumDays/count, sumValues/count
+
+	var covariance, variance float64
+	for idx, point := range series {
+		covariance += (days[idx] - meanDay) * (point.Value - meanValue)
+		variance += (days[idx] - meanDay) * (days[idx] - meanDay)
+	}
+	if days[len(days)-1]-days[0] < minTrendDays || variance == 0 {
+		return meanValue, 0, false, nil
+	}
+
+	slope := covariance / variance
+	return meanValue - slope*meanDay, slope, true, nil
+}
+
+// groupProgressPerExercise groups the performance entries by exercise, ordered by the exercise id and the entries by date
+func groupProgressPerExercise(ctx context.Context, performances []PerformanceBodyWithId) ([]databaseUtils.Exercise, map[uint][]PerformanceBodyWithId, error) {
+	grouped := make(map[uint][]PerformanceBodyWithId)
+	for _, performance := range performances {
+		grouped[performance.ExerciseId] = append(grouped[performance.ExerciseId], performance)
+	}
+
+	exercises := make([]databaseUtils.Exercise, 0, len(grouped))
+	for exerciseId, group := range grouped {
+		exercise, err := rulesetCache.GetExercise(ctx, exerciseId)
+		if err != nil {
+			return nil, nil, err
+		}
+		exercises = append(exercises, exercise)
+
+		sort.SliceStable(group, func(i, j int) bool {
+			if group[i].Date != group[j].Date {
+				return group[i].Date < group[j].Date
+			}
+			return group[i].PerformanceId < group[j].PerformanceId
+		})
+	}
+	sort.Slice(exercises, func(i, j int) bool {
+		return exercises[i].ID < exercises[j].ID
+	})
+
+	return exercises, grouped, nil
+}
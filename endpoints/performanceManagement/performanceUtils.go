@@ -5,6 +5,7 @@ import (
 	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/badgeManagement"
 	"github.com/Team-Reissdorf/Backend/formatHelper"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
@@ -34,6 +35,24 @@ func createNewPerformances(ctx context.Context, performanceEntries []databaseUti
 	return nil
 }
 
+// recordEarnedBadges records the earned badges of the athletes whose performances have changed.
+// The performances are already saved at this point, so a failure is only logged and the badges are recorded with the next change.
+func recordEarnedBadges(ctx context.Context, trainerEmail string, athleteIds []uint) {
+	uniqueAthleteIds := make([]uint, 0, len(athleteIds))
+	isAdded := make(map[uint]bool, len(athleteIds))
+	for _, athleteId := range athleteIds {
+		if !isAdded[athleteId] {
+			isAdded[athleteId] = true
+			uniqueAthleteIds = append(uniqueAthleteIds, athleteId)
+		}
+	}
+
+	if err := badgeManagement.RecordEarnedBadges(ctx, trainerEmail, uniqueAthleteIds); err != nil {
+		err = errors.Wrap(err, "Failed to record the earned badges")
+		endpoints.Logger.Error(ctx, err)
+	}
+}
+
 // translatePerformanceBodies translates the performance body to a performance db entry
 func translatePerformanceBodies(ctx context.Context, performanceBodies []PerformanceBody, age int, sex string) ([]databaseUtils.Performance, error) {
 	ctx, span := endpoints.Tracer.Start(ctx, "TranslatePerformanceBodies")
@@ -94,7 +113,13 @@ func getLatestPerformanceBody(ctx context.Context, athleteId uint) (*Performance
 
 // getBestPerformanceBodiesSince gets the best performance entries of each discipline of an athlete since the given date
 func getBestPerformanceBodiesSince(ctx context.Context, athleteId uint, sinceDate string) (*[]PerformanceBodyWithId, error) {
-	ctx, span := endpoints.Tracer.Start(ctx, "GetBestPerformanceBodiesSinceFromDB")
+	return getBestPerformanceBodiesBetween(ctx, athleteId, sinceDate, "")
+}
+
+// getBestPerformanceBodiesBetween gets the best performance entries of each discipline of an athlete after the since date
+// up to the until date (inclusive). An empty until date does not limit the entries.
+func getBestPerformanceBodiesBetween(ctx context.Context, athleteId uint, sinceDate string, untilDate string) (*[]PerformanceBodyWithId, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetBestPerformanceBodiesBetweenFromDB")
 	defer span.End()
 
 	db := DatabaseFlow.GetDB(ctx)
@@ -115,11 +140,15 @@ func getBestPerformanceBodiesSince(ctx context.Context, athleteId uint, sinceDat
 	var performanceBodies []PerformanceBodyWithId
 	for _, discipline := range disciplines {
 		var performanceBody PerformanceBodyWithId
-		err1 = db.Model(&databaseUtils.Performance{}).
+		query := db.Model(&databaseUtils.Performance{}).
 			Select("performances.id AS performance_id, performances.points, exercises.unit AS unit, performances.medal, performances.date, performances.exercise_id, performances.athlete_id").
 			Joins("LEFT JOIN exercises ON performances.exercise_id = exercises.id").
 			Where("athlete_id = ? AND exercises.discipline_name = ? AND performances.date > ?",
-				athleteId, discipline.Name, sinceDate).
+				athleteId, discipline.Name, sinceDate)
+		if untilDate != "" {
+			query = query.Where("performances.date <= ?", untilDate)
+		}
+		err1 = query.
 			Order("CASE performances.medal " +
 				"WHEN 'gold' THEN 1 " +
 				"WHEN 'silver' THEN 2 " +
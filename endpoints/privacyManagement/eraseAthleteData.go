@@ -0,0 +1,89 @@
+package privacyManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/badgeManagement"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// EraseAthleteData erases or anonymizes all personal data of an athlete
+// @Summary Erases or anonymizes all personal data of an athlete
+// @Description Handles a GDPR erasure request. In the erase mode the athlete is deleted with all performance entries, guardian contacts, consents and swim certificate files. In the anonymize mode the athlete is replaced by a pseudonymous athlete that only keeps the birth year and sex, so the performance entries stay available for the statistics. Guardian contacts, consents and swim certificate files are deleted in both modes.
+// @Tags Privacy Management
+// @Produce json
+// @Param AthleteId path int true "ID of the athlete"
+// @Param mode query string false "erase (default) or anonymize"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} endpoints.SuccessResponse "Erasure successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete not found"
+// @Failure 409 {object} endpoints.ErrorResponse "The athlete has already been anonymized"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/privacy/erase/{AthleteId} [delete]
+func EraseAthleteData(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "EraseAthleteData")
+	defer span.End()
+
+	// Get the athlete id from the context
+	athleteIdString := c.Param("AthleteId")
+	if athleteIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid athlete ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid athlete ID"})
+		return
+	}
+	athleteId, err1 := strconv.ParseUint(athleteIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse athlete ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid athlete ID"})
+		return
+	}
+
+	// Get the erasure mode
+	mode := c.DefaultQuery("mode", EraseMode)
+	if mode != EraseMode && mode != AnonymizeMode {
+		endpoints.Logger.Debug(ctx, "Invalid erasure mode: "+mode)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid mode, expected erase or anonymize"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Erase or anonymize the athlete
+	var err2 error
+	if mode == AnonymizeMode {
+		// The swim certificates are deleted, so the earned badges need to be recorded first
+		err2 = badgeManagement.RecordEarnedBadges(ctx, trainerEmail, []uint{uint(athleteId)})
+		if err2 == nil {
+			err2 = athleteManagement.AnonymizeAthlete(ctx, uint(athleteId), trainerEmail)
+		}
+	} else {
+		err2 = athleteManagement.EraseAthlete(ctx, uint(athleteId), trainerEmail)
+	}
+	if errors.Is(err2, gorm.ErrRecordNotFound) {
+		err2 = errors.Wrap(err2, "Athlete not found")
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	} else if errors.Is(err2, athleteManagement.AlreadyAnonymizedError) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: err2.Error()})
+		return
+	} else if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to erase the athlete data")
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to erase the athlete data"})
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "Erasure successful"})
+}
@@ -0,0 +1,103 @@
+package privacyManagement
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/swimCertificate"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// ExportAthleteData returns all personal data stored about an athlete as a ZIP file
+// @Summary Exports all personal data of an athlete
+// @Description Creates a ZIP file with all data stored about the given athlete (GDPR data access request). It contains the master data, all performance entries with their medals, the guardian contacts, the consents, the historical and the earned badges and the original swim certificate files. The data is included once as data.json and once as one csv file per category.
+// @Tags Privacy Management
+// @Produce application/zip
+// @Param AthleteId path int true "ID of the athlete"
+// @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {file} file "ZIP archive with all personal data of the athlete"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 404 {object} endpoints.ErrorResponse "Athlete not found"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/privacy/export/{AthleteId} [get]
+func ExportAthleteData(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "ExportAthleteData")
+	defer span.End()
+
+	// Get the athlete id from the context
+	athleteIdString := c.Param("AthleteId")
+	if athleteIdString == "" {
+		endpoints.Logger.Debug(ctx, "Missing or invalid athlete ID")
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Missing or invalid athlete ID"})
+		return
+	}
+	athleteId, err1 := strconv.ParseUint(athleteIdString, 10, 32)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to parse athlete ID")
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid athlete ID"})
+		return
+	}
+
+	// Get the user id from the context
+	trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+
+	// Get the athlete of the given trainer
+	athlete, err2 := athleteManagement.GetAthlete(ctx, uint(athleteId), trainerEmail)
+	if errors.Is(err2, gorm.ErrRecordNotFound) {
+		endpoints.Logger.Debug(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Athlete not found"})
+		return
+	} else if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete"})
+		return
+	}
+
+	// Collect all data of the athlete
+	dataExport, certificates, err3 := collectAthleteData(ctx, *athlete)
+	if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to collect the athlete data")
+		endpoints.Logger.Error(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to collect the athlete data"})
+		return
+	}
+
+	// Build the ZIP in memory, so that errors can still be reported to the client
+	var buffer bytes.Buffer
+	zipWriter := zip.NewWriter(&buffer)
+
+	zipPaths := swimCertificate.WriteSwimCertificatesToZip(ctx, zipWriter, certificates, swimCertificateFolder)
+	for idx, zipPath := range zipPaths {
+		dataExport.SwimCertificates[idx].FileName = zipPath
+	}
+
+	if err4 := writeJsonToZip(zipWriter, dataExport); err4 != nil {
+		endpoints.Logger.Error(ctx, err4)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the data export"})
+		return
+	}
+	if err5 := writeCsvFilesToZip(zipWriter, dataExport); err5 != nil {
+		endpoints.Logger.Error(ctx, err5)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the data export"})
+		return
+	}
+	if err6 := zipWriter.Close(); err6 != nil {
+		err6 = errors.Wrap(err6, "Failed to close the zip folder")
+		endpoints.Logger.Error(ctx, err6)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to create the data export"})
+		return
+	}
+
+	fileName := "athlete_" + strconv.FormatUint(athleteId, 10) + "_data_export.zip"
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Data(http.StatusOK, "application/zip", buffer.Bytes())
+}
@@ -0,0 +1,73 @@
+package privacyManagement
+
+// AthleteDataExport holds all personal data stored about one athlete
+type AthleteDataExport struct {
+	ExportedAt       string                  `json:"exported_at" example:"2025-01-31T12:00:00Z"`
+	Athlete          AthleteExport           `json:"athlete"`
+	Performances     []PerformanceExport     `json:"performances"`
+	Guardians        []GuardianExport        `json:"guardians"`
+	Consents         []ConsentExport         `json:"consents"`
+	SwimCertificates []SwimCertificateExport `json:"swim_certificates"`
+	HistoricalBadges []HistoricalBadgeExport `json:"historical_badges"`
+	EarnedBadges     []EarnedBadgeExport     `json:"earned_badges"`
+}
+
+type AthleteExport struct {
+	AthleteId    uint   `json:"athlete_id" example:"1"`
+	FirstName    string `json:"first_name" example:"Bob"`
+	LastName     string `json:"last_name" example:"Alice"`
+	Email        string `json:"email" example:"bob@alice.com"`
+	BirthDate    string `json:"birth_date" example:"2000-01-01"`
+	Sex          string `json:"sex" example:"m"`
+	TrainerEmail string `json:"trainer_email" example:"trainer@example.com"`
+	CreatedAt    string `json:"created_at" example:"2025-01-31T12:00:00Z"`
+	UpdatedAt    string `json:"updated_at" example:"2025-01-31T12:00:00Z"`
+}
+
+type PerformanceExport struct {
+	PerformanceId uint   `json:"performance_id" example:"1"`
+	Date          string `json:"date" example:"2025-01-31"`
+	Exercise      string `json:"exercise" example:"Laufen"`
+	Discipline    string `json:"discipline" example:"Ausdauer"`
+	Unit          string `json:"unit" example:"minute"`
+	Points        uint64 `json:"points" example:"360000"`
+	Medal         string `json:"medal" example:"gold"`
+}
+
+type GuardianExport struct {
+	GuardianId uint   `json:"guardian_id" example:"1"`
+	Name       string `json:"name" example:"Alice Alice"`
+	Email      string `json:"email" example:"alice@alice.com"`
+	Phone      string `json:"phone" example:"+49 170 1234567"`
+	Relation   string `json:"relation" example:"mother"`
+}
+
+type ConsentExport struct {
+	ConsentId uint   `json:"consent_id" example:"1"`
+	Type      string `json:"type" example:"written"`
+	Scope     string `json:"scope" example:"store_documents"`
+	GivenBy   string `json:"given_by" example:"Alice Alice"`
+	GivenAt   string `json:"given_at" example:"2025-01-31"`
+	RevokedAt string `json:"revoked_at" example:""`
+}
+
+type SwimCertificateExport struct {
+	SwimCertificateId uint   `json:"swim_certificate_id" example:"1"`
+	Date              string `json:"date" example:"2025-01-31"`
+	OriginalFileName  string `json:"original_file_name" example:"certificate.pdf"`
+	FileName          string `json:"file_name" example:"swim_certificates/certificate.pdf"`
+}
+
+type HistoricalBadgeExport struct {
+	HistoricalBadgeId uint   `json:"historical_badge_id" example:"1"`
+	Year              int    `json:"year" example:"2015"`
+	Level             string `json:"level" example:"silver"`
+	Note              string `json:"note" example:"Certificate of the old club"`
+}
+
+type EarnedBadgeExport struct {
+	EarnedBadgeId uint   `json:"earned_badge_id" example:"1"`
+	Year          int    `json:"year" example:"2025"`
+	Level         string `json:"level" example:"gold"`
+	Points        int    `json:"points" example:"11"`
+}
@@ -0,0 +1,398 @@
+package privacyManagement
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/consentManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/guardianManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/swimCertificate"
+	"github.com/Team-Reissdorf/Backend/formatHelper"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	swimCertificateFolder = "swim_certificates"
+	dataExportJsonFile    = "data.json"
+
+	EraseMode     = "erase"
+	AnonymizeMode = "anonymize"
+)
+
+// performanceWithExercise is a performance entry joined with its exercise
+type performanceWithExercise struct {
+	ID             uint
+	Date           string
+	Points         uint64
+	Medal          string
+	Name           string
+	DisciplineName string
+	Unit           string
+}
+
+// getPerformancesOfAthlete returns all performance entries of the given athlete including the exercise information
+func getPerformancesOfAthlete(ctx context.Context, athleteId uint) ([]PerformanceExport, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetPerformancesOfAthleteForExport")
+	defer span.End()
+
+	var performances []performanceWithExercise
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Performance{}).
+			Select("performances.id, performances.date, performances.points, performances.medal, exercises.name, exercises.discipline_name, exercises.unit").
+			Joins("INNER JOIN exercises ON performances.exercise_id = exercises.id").
+			Where("performances.athlete_id = ?", athleteId).
+			Order("performances.date ASC, performances.id ASC").
+			Scan(&performances).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the performances")
+		return nil, err1
+	}
+
+	performanceExports := make([]PerformanceExport, len(performances))
+	for idx, performance := range performances {
+		date, err2 := formatHelper.FormatDate(performance.Date)
+		if err2 != nil {
+			err2 = errors.Wrap(err2, "Failed to format the performance date")
+			return nil, err2
+		}
+
+		performanceExports[idx] = PerformanceExport{
+			PerformanceId: performance.ID,
+			Date:          date,
+			Exercise:      performance.Name,
+			Discipline:    performance.DisciplineName,
+			Unit:          performance.Unit,
+			Points:        performance.Points,
+			Medal:         performance.Medal,
+		}
+	}
+
+	return performanceExports, nil
+}
+
+// getBadgesOfAthlete returns the historical and the recorded earned badges of the given athlete, ordered by year
+func getBadgesOfAthlete(ctx context.Context, athleteId uint) ([]databaseUtils.HistoricalBadge, []databaseUtils.EarnedBadge, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetBadgesOfAthleteForExport")
+	defer span.End()
+
+	var historicalBadges []databaseUtils.HistoricalBadge
+	var earnedBadges []databaseUtils.EarnedBadge
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.HistoricalBadge{}).
+			Where("athlete_id = ?", athleteId).
+			Order("year ASC").
+			Find(&historicalBadges).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&databaseUtils.EarnedBadge{}).
+			Where("athlete_id = ?", athleteId).
+			Order("year ASC").
+			Find(&earnedBadges).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the badges")
+		return nil, nil, err1
+	}
+
+	return historicalBadges, earnedBadges, nil
+}
+
+// collectAthleteData gathers all personal data stored about the given athlete.
+// The swim certificates are returned separately, since their files need to be exported as well.
+func collectAthleteData(ctx context.Context, athlete databaseUtils.Athlete) (*AthleteDataExport, []databaseUtils.SwimCertificate, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "CollectAthleteData")
+	defer span.End()
+
+	birthDate, err1 := formatHelper.FormatDate(athlete.BirthDate)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to format the birth date")
+		return nil, nil, err1
+	}
+
+	performances, err2 := getPerformancesOfAthlete(ctx, athlete.ID)
+	if err2 != nil {
+		return nil, nil, err2
+	}
+
+	guardians, err3 := guardianManagement.GetGuardiansOfAthlete(ctx, athlete.ID)
+	if err3 != nil {
+		return nil, nil, err3
+	}
+
+	consents, err4 := consentManagement.GetConsentsOfAthlete(ctx, athlete.ID)
+	if err4 != nil {
+		return nil, nil, err4
+	}
+
+	certificates, err5 := swimCertificate.GetSwimCertificatesOfAthlete(ctx, athlete.ID)
+	if err5 != nil {
+		return nil, nil, err5
+	}
+
+	historicalBadges, earnedBadges, err6 := getBadgesOfAthlete(ctx, athlete.ID)
+	if err6 != nil {
+		return nil, nil, err6
+	}
+
+	dataExport := AthleteDataExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Athlete: AthleteExport{
+			AthleteId:    athlete.ID,
+			FirstName:    athlete.FirstName,
+			LastName:     athlete.LastName,
+			Email:        athlete.Email,
+			BirthDate:    birthDate,
+			Sex:          athlete.Sex,
+			TrainerEmail: athlete.TrainerEmail,
+			CreatedAt:    athlete.CreatedAt.UTC().Format(time.RFC3339),
+			UpdatedAt:    athlete.UpdatedAt.UTC().Format(time.RFC3339),
+		},
+		Performances:     performances,
+		Guardians:        make([]GuardianExport, len(guardians)),
+		Consents:         make([]ConsentExport, len(consents)),
+		SwimCertificates: make([]SwimCertificateExport, len(certificates)),
+		HistoricalBadges: make([]HistoricalBadgeExport, len(historicalBadges)),
+		EarnedBadges:     make([]EarnedBadgeExport, len(earnedBadges)),
+	}
+
+	for idx, guardian := range guardians {
+		dataExport.Guardians[idx] = GuardianExport{
+			GuardianId: guardian.ID,
+			Name:       guardian.Name,
+			Email:      guardian.Email,
+			Phone:      guardian.Phone,
+			Relation:   guardian.Relation,
+		}
+	}
+
+	for idx, consent := range consents {
+		givenAt, errA := formatHelper.FormatDate(consent.GivenAt)
+		if errA != nil {
+			errA = errors.Wrap(errA, "Failed to format the consent date")
+			return nil, nil, errA
+		}
+
+		dataExport.Consents[idx] = ConsentExport{
+			ConsentId: consent.ID,
+			Type:      consent.Type,
+			Scope:     consent.Scope,
+			GivenBy:   consent.GivenBy,
+			GivenAt:   givenAt,
+		}
+		if consent.RevokedAt != nil {
+			dataExport.Consents[idx].RevokedAt = consent.RevokedAt.Format("2006-01-02")
+		}
+	}
+
+	for idx, certificate := range certificates {
+		dataExport.SwimCertificates[idx] = SwimCertificateExport{
+			SwimCertificateId: certificate.ID,
+			Date:              certificate.Date.Format("2006-01-02"),
+			OriginalFileName:  certificate.OriginalFileName,
+		}
+	}
+
+	for idx, historicalBadge := range historicalBadges {
+		dataExport.HistoricalBadges[idx] = HistoricalBadgeExport{
+			HistoricalBadgeId: historicalBadge.ID,
+			Year:              historicalBadge.Year,
+			Level:             historicalBadge.Level,
+			Note:              historicalBadge.Note,
+		}
+	}
+
+	for idx, earnedBadge := range earnedBadges {
+		dataExport.EarnedBadges[idx] = EarnedBadgeExport{
+			EarnedBadgeId: earnedBadge.ID,
+			Year:          earnedBadge.Year,
+			Level:         earnedBadge.Level,
+			Points:        earnedBadge.Points,
+		}
+	}
+
+	return &dataExport, certificates, nil
+}
+
+// writeJsonToZip writes the data export as a single json file into the zip folder
+func writeJsonToZip(zipWriter *zip.Writer, dataExport *AthleteDataExport) error {
+	fileWriter, err1 := zipWriter.Create(dataExportJsonFile)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to create the json file")
+		return err1
+	}
+
+	encoder := json.NewEncoder(fileWriter)
+	encoder.SetIndent("", "  ")
+	if err2 := encoder.Encode(dataExport); err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to write the json file")
+		return err2
+	}
+
+	return nil
+}
+
+// writeCsvToZip writes the given records with a header row as a semicolon separated csv file into the zip folder
+func writeCsvToZip(zipWriter *zip.Writer, fileName string, header []string, records [][]string) error {
+	fileWriter, err1 := zipWriter.Create(fileName)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to create the csv file "+fileName)
+		return err1
+	}
+
+	w := csv.NewWriter(fileWriter)
+	w.Comma = ';'
+	if err2 := w.Write(header); err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to write the csv header of "+fileName)
+		return err2
+	}
+	if err3 := w.WriteAll(records); err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to write the csv file "+fileName)
+		return err3
+	}
+
+	return nil
+}
+
+// writeCsvFilesToZip writes one csv file per data category into the zip folder
+func writeCsvFilesToZip(zipWriter *zip.Writer, dataExport *AthleteDataExport) error {
+	athlete := dataExport.Athlete
+	err1 := writeCsvToZip(zipWriter, "athlete.csv",
+		[]string{"athlete_id", "first_name", "last_name", "email", "birth_date", "sex", "trainer_email", "created_at", "updated_at"},
+		[][]string{{
+			strconv.FormatUint(uint64(athlete.AthleteId), 10),
+			athlete.FirstName,
+			athlete.LastName,
+			athlete.Email,
+			athlete.BirthDate,
+			athlete.Sex,
+			athlete.TrainerEmail,
+			athlete.CreatedAt,
+			athlete.UpdatedAt,
+		}},
+	)
+	if err1 != nil {
+		return err1
+	}
+
+	performanceRecords := make([][]string, len(dataExport.Performances))
+	for idx, performance := range dataExport.Performances {
+		performanceRecords[idx] = []string{
+			strconv.FormatUint(uint64(performance.PerformanceId), 10),
+			performance.Date,
+			performance.Exercise,
+			performance.Discipline,
+			performance.Unit,
+			strconv.FormatUint(performance.Points, 10),
+			performance.Medal,
+		}
+	}
+	err2 := writeCsvToZip(zipWriter, "performances.csv",
+		[]string{"performance_id", "date", "exercise", "discipline", "unit", "points", "medal"},
+		performanceRecords,
+	)
+	if err2 != nil {
+		return err2
+	}
+
+	guardianRecords := make([][]string, len(dataExport.Guardians))
+	for idx, guardian := range dataExport.Guardians {
+		guardianRecords[idx] = []string{
+			strconv.FormatUint(uint64(guardian.GuardianId), 10),
+			guardian.Name,
+			guardian.Email,
+			guardian.Phone,
+			guardian.Relation,
+		}
+	}
+	err3 := writeCsvToZip(zipWriter, "guardians.csv",
+		[]string{"guardian_id", "name", "email", "phone", "relation"},
+		guardianRecords,
+	)
+	if err3 != nil {
+		return err3
+	}
+
+	consentRecords := make([][]string, len(dataExport.Consents))
+	for idx, consent := range dataExport.Consents {
+		consentRecords[idx] = []string{
+			strconv.FormatUint(uint64(consent.ConsentId), 10),
+			consent.Type,
+			consent.Scope,
+			consent.GivenBy,
+			consent.GivenAt,
+			consent.RevokedAt,
+		}
+	}
+	err4 := writeCsvToZip(zipWriter, "consents.csv",
+		[]string{"consent_id", "type", "scope", "given_by", "given_at", "revoked_at"},
+		consentRecords,
+	)
+	if err4 != nil {
+		return err4
+	}
+
+	certificateRecords := make([][]string, len(dataExport.SwimCertificates))
+	for idx, certificate := range dataExport.SwimCertificates {
+		certificateRecords[idx] = []string{
+			strconv.FormatUint(uint64(certificate.SwimCertificateId), 10),
+			certificate.Date,
+			certificate.OriginalFileName,
+			certificate.FileName,
+		}
+	}
+	err5 := writeCsvToZip(zipWriter, "swim_certificates.csv",
+		[]string{"swim_certificate_id", "date", "original_file_name", "file_name"},
+		certificateRecords,
+	)
+	if err5 != nil {
+		return err5
+	}
+
+	historicalBadgeRecords := make([][]string, len(dataExport.HistoricalBadges))
+	for idx, historicalBadge := range dataExport.HistoricalBadges {
+		historicalBadgeRecords[idx] = []string{
+			strconv.FormatUint(uint64(historicalBadge.HistoricalBadgeId), 10),
+			strconv.Itoa(historicalBadge.Year),
+			historicalBadge.Level,
+			historicalBadge.Note,
+		}
+	}
+	err6 := writeCsvToZip(zipWriter, "historical_badges.csv",
+		[]string{"historical_badge_id", "year", "level", "note"},
+		historicalBadgeRecords,
+	)
+	if err6 != nil {
+		return err6
+	}
+
+	earnedBadgeRecords := make([][]string, len(dataExport.EarnedBadges))
+	for idx, earnedBadge := range dataExport.EarnedBadges {
+		earnedBadgeRecords[idx] = []string{
+			strconv.FormatUint(uint64(earnedBadge.EarnedBadgeId), 10),
+			strconv.Itoa(earnedBadge.Year),
+			earnedBadge.Level,
+			strconv.Itoa(earnedBadge.Points),
+		}
+	}
+	err7 := writeCsvToZip(zipWriter, "earned_badges.csv",
+		[]string{"earned_badge_id", "year", "level", "points"},
+		earnedBadgeRecords,
+	)
+	return err7
+}
@@ -0,0 +1,64 @@
+package retentionManagement
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type RetentionAuditResponse struct {
+	Message string               `json:"message" example:"Request successful"`
+	Entries []RetentionAuditBody `json:"entries"`
+}
+
+// GetRetentionAudit returns the audit trail of the retention policies
+// @Summary Returns the audit trail of the retention policies
+// @Description Returns the latest entries that have been deleted or anonymized by the retention policies, newest first.
+// @Tags Retention Management
+// @Produce json
+// @Param run_id query string false "Only return the entries of the given run"
+// @Param limit query int false "Maximum number of entries (default 100, max 1000)"
+// @Param Authorization  header  string  false  "Settings access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} RetentionAuditResponse "Request successful"
+// @Failure 400 {object} endpoints.ErrorResponse "Invalid request parameter"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/retention/audit [get]
+func GetRetentionAudit(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "GetRetentionAudit")
+	defer span.End()
+
+	limit := defaultAuditLimit
+	if limitString := c.Query("limit"); limitString != "" {
+		var err1 error
+		limit, err1 = strconv.Atoi(limitString)
+		if err1 != nil || limit <= 0 || limit > maxAuditLimit {
+			endpoints.Logger.Debug(ctx, errors.New("Invalid limit: "+limitString))
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid limit"})
+			return
+		}
+	}
+
+	entries, err2 := getAuditEntries(ctx, c.Query("run_id"), limit)
+	if err2 != nil {
+		endpoints.Logger.Error(ctx, err2)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the audit trail"})
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		RetentionAuditResponse{
+			Message: "Request successful",
+			Entries: entries,
+		},
+	)
+}
@@ -0,0 +1,45 @@
+package retentionManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type RetentionReportResponse struct {
+	Message string          `json:"message" example:"Request successful"`
+	Report  RetentionReport `json:"report"`
+}
+
+// PreviewRetention returns the entries that would be affected by the retention policies
+// @Summary Dry run of the retention policies
+// @Description Returns all entries that would be deleted or anonymized by the configured retention policies without changing anything.
+// @Tags Retention Management
+// @Produce json
+// @Param Authorization  header  string  false  "Settings access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 200 {object} RetentionReportResponse "Request successful"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/retention/preview [get]
+func PreviewRetention(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "PreviewRetention")
+	defer span.End()
+
+	report, err1 := RunRetentionPolicies(ctx, true)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to run the retention policies in dry-run mode")
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to run the retention policies"})
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		RetentionReportResponse{
+			Message: "Request successful",
+			Report:  *report,
+		},
+	)
+}
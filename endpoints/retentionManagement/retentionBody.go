@@ -0,0 +1,31 @@
+package retentionManagement
+
+// RetentionPolicies holds the configured retention rules. A value of 0 disables the rule.
+type RetentionPolicies struct {
+	SwimCertificateRetentionYears int `json:"swim_certificate_retention_years" example:"1"` // Years after the expiry of a certificate under the badge rules
+	InactiveAthleteYears          int `json:"inactive_athlete_years" example:"3"`           // Years without a performance entry
+}
+
+type RetentionReportEntry struct {
+	Rule       string `json:"rule" example:"expired_swim_certificate"`
+	Action     string `json:"action" example:"delete"`
+	EntityType string `json:"entity_type" example:"swim_certificate"`
+	EntityId   uint   `json:"entity_id" example:"1"`
+	AthleteId  uint   `json:"athlete_id" example:"1"`
+	Reason     string `json:"reason" example:"Expired on 2020-05-01"`
+}
+
+type RetentionReport struct {
+	RunId    string                 `json:"run_id" example:"0b5e3c0e-6c4b-4f4e-9d4b-1c6f1f3e4a2d"`
+	DryRun   bool                   `json:"dry_run"`
+	RunAt    string                 `json:"run_at" example:"2025-01-31T12:00:00Z"`
+	Policies RetentionPolicies      `json:"policies"`
+	Entries  []RetentionReportEntry `json:"entries"`
+	Failed   []RetentionReportEntry `json:"failed"`
+}
+
+type RetentionAuditBody struct {
+	RetentionReportEntry
+	RunId string `json:"run_id" example:"0b5e3c0e-6c4b-4f4e-9d4b-1c6f1f3e4a2d"`
+	RunAt string `json:"run_at" example:"2025-01-31T12:00:00Z"`
+}
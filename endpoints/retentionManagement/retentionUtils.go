@@ -0,0 +1,287 @@
+package retentionManagement
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/badgeHelper"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/badgeManagement"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	ExpiredSwimCertificateRule = "expired_swim_certificate"
+	InactiveAthleteRule        = "inactive_athlete"
+
+	DeleteAction    = "delete"
+	AnonymizeAction = "anonymize"
+)
+
+// retentionCandidate is an entry that is affected by a retention rule and the function to apply the rule
+type retentionCandidate struct {
+	entry RetentionReportEntry
+	apply func(ctx context.Context) error
+}
+
+// RunRetentionPolicies applies all enabled retention rules. In the dry-run mode nothing is changed and
+// only the report of the affected entries is returned. Otherwise, every applied entry is written to the audit trail.
+func RunRetentionPolicies(ctx context.Context, dryRun bool) (*RetentionReport, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "RunRetentionPolicies")
+	defer span.End()
+
+	now := time.Now()
+	report := RetentionReport{
+		RunId:    uuid.New().String(),
+		DryRun:   dryRun,
+		RunAt:    now.UTC().Format(time.RFC3339),
+		Policies: policies,
+		Entries:  []RetentionReportEntry{},
+		Failed:   []RetentionReportEntry{},
+	}
+
+	// Collect the affected entries of all rules
+	var candidates []retentionCandidate
+	finders := []func(context.Context, time.Time) ([]retentionCandidate, error){
+		findExpiredSwimCertificates,
+		findInactiveAthletes,
+	}
+	for _, finder := range finders {
+		found, err1 := finder(ctx, now)
+		if err1 != nil {
+			return nil, err1
+		}
+		candidates = append(candidates, found...)
+	}
+
+	for _, candidate := range candidates {
+		if dryRun {
+			report.Entries = append(report.Entries, candidate.entry)
+			continue
+		}
+
+		// Apply the rule and record it in the audit trail
+		if err2 := candidate.apply(ctx); err2 != nil {
+			err2 = errors.Wrap(err2, fmt.Sprintf("Failed to apply the retention rule %s to %s %d", candidate.entry.Rule, candidate.entry.EntityType, candidate.entry.EntityId))
+			endpoints.Logger.Error(ctx, err2)
+			report.Failed = append(report.Failed, candidate.entry)
+			continue
+		}
+		if err3 := writeAuditEntry(ctx, report.RunId, candidate.entry); err3 != nil {
+			endpoints.Logger.Error(ctx, err3)
+		}
+		report.Entries = append(report.Entries, candidate.entry)
+	}
+
+	return &report, nil
+}
+
+// RunRetentionJob applies the retention policies as a job of the scheduler
+func RunRetentionJob(ctx context.Context) (string, error) {
+	report, err := RunRetentionPolicies(ctx, false)
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("Retention policies applied to %d entries, %d failed (run %s)", len(report.Entries), len(report.Failed), report.RunId)
+	if len(report.Failed) > 0 {
+		return result, errors.New(result)
+	}
+	return result, nil
+}
+
+// findExpiredSwimCertificates returns the swim certificates that expired more than the configured years ago.
+// The expiry follows the badge rules, so the certificates of minors are kept as long as they are valid.
+func findExpiredSwimCertificates(ctx context.Context, now time.Time) ([]retentionCandidate, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "FindExpiredSwimCertificates")
+	defer span.End()
+
+	if policies.SwimCertificateRetentionYears == 0 {
+		return nil, nil
+	}
+
+	// A certificate expires at the earliest after its date, so only older ones need to be checked
+	retentionStart := now.AddDate(-policies.SwimCertificateRetentionYears, 0, 0)
+	var certificates []databaseUtils.SwimCertificate
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.SwimCertificate{}).
+			Preload("Athlete").
+			Where("date < ?", retentionStart).
+			Order("id ASC").
+			Find(&certificates).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the expired swim certificates")
+		return nil, err1
+	}
+
+	candidates := make([]retentionCandidate, 0, len(certificates))
+	for _, certificate := range certificates {
+		expiryYear, err2 := badgeHelper.SwimCertificateExpiryYear(certificate.Date, certificate.Athlete.BirthDate)
+		if err2 != nil {
+			err2 = errors.Wrap(err2, fmt.Sprintf("Failed to get the expiry of the swim certificate %d", certificate.ID))
+			endpoints.Logger.Error(ctx, err2)
+			continue
+		}
+		expiry := time.Date(expiryYear, 1, 1, 0, 0, 0, 0, time.UTC)
+		if !expiry.Before(retentionStart) {
+			continue
+		}
+
+		certificateId := certificate.ID
+		athleteId := certificate.AthleteId
+		trainerEmail := certificate.Athlete.TrainerEmail
+		documentPath := certificate.DocumentPath
+		candidates = append(candidates, retentionCandidate{
+			entry: RetentionReportEntry{
+				Rule:       ExpiredSwimCertificateRule,
+				Action:     DeleteAction,
+				EntityType: "swim_certificate",
+				EntityId:   certificateId,
+				AthleteId:  certificate.AthleteId,
+				Reason:     "Expired on " + expiry.Format("2006-01-02"),
+			},
+			apply: func(ctx context.Context) error {
+				// Keep the badges that have been earned with the certificate
+				if err := badgeManagement.RecordEarnedBadges(ctx, trainerEmail, []uint{athleteId}); err != nil {
+					return err
+				}
+				err := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+					return tx.Delete(&databaseUtils.SwimCertificate{}, "id = ?", certificateId).Error
+				})
+				if err != nil {
+					return err
+				}
+				athleteManagement.RemoveDocumentFiles(ctx, []string{documentPath})
+				return nil
+			},
+		})
+	}
+
+	return candidates, nil
+}
+
+// findInactiveAthletes returns the athletes without a performance entry in the configured years
+func findInactiveAthletes(ctx context.Context, now time.Time) ([]retentionCandidate, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "FindInactiveAthletes")
+	defer span.End()
+
+	if policies.InactiveAthleteYears == 0 {
+		return nil, nil
+	}
+
+	cutoff := now.AddDate(-policies.InactiveAthleteYears, 0, 0)
+	var athletes []databaseUtils.Athlete
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Athlete{}).
+			Where("anonymized_at IS NULL AND created_at < ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM performances WHERE performances.athlete_id = athletes.id AND performances.date >= ?)", cutoff.Format("2006-01-02")).
+			Order("id ASC").
+			Find(&athletes).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the inactive athletes")
+		return nil, err1
+	}
+
+	candidates := make([]retentionCandidate, len(athletes))
+	for idx, athlete := range athletes {
+		athleteId := athlete.ID
+		trainerEmail := athlete.TrainerEmail
+
+		candidates[idx] = retentionCandidate{
+			entry: RetentionReportEntry{
+				Rule:       InactiveAthleteRule,
+				Action:     AnonymizeAction,
+				EntityType: "athlete",
+				EntityId:   athleteId,
+				AthleteId:  athleteId,
+				Reason:     "No performance entry since " + cutoff.Format("2006-01-02"),
+			},
+			apply: func(ctx context.Context) error {
+				// The swim certificates are deleted, so the earned badges need to be recorded first
+				err := badgeManagement.RecordEarnedBadges(ctx, trainerEmail, []uint{athleteId})
+				if err == nil {
+					err = athleteManagement.AnonymizeAthlete(ctx, athleteId, trainerEmail)
+				}
+				if errors.Is(err, athleteManagement.AlreadyAnonymizedError) || errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			},
+		}
+	}
+
+	return candidates, nil
+}
+
+// writeAuditEntry persists an applied retention entry in the audit trail
+func writeAuditEntry(ctx context.Context, runId string, entry RetentionReportEntry) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "WriteRetentionAuditEntry")
+	defer span.End()
+
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Create(&databaseUtils.RetentionAudit{
+			RunId:      runId,
+			Rule:       entry.Rule,
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityId:   entry.EntityId,
+			AthleteId:  entry.AthleteId,
+			Reason:     entry.Reason,
+		}).Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to write the retention audit entry")
+		return err1
+	}
+	return nil
+}
+
+// getAuditEntries returns the latest entries of the audit trail, optionally filtered by the run
+func getAuditEntries(ctx context.Context, runId string, limit int) ([]RetentionAuditBody, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetRetentionAuditEntries")
+	defer span.End()
+
+	var audits []databaseUtils.RetentionAudit
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		query := tx.Model(&databaseUtils.RetentionAudit{})
+		if runId = strings.TrimSpace(runId); runId != "" {
+			query = query.Where("run_id = ?", runId)
+		}
+		return query.Order("id DESC").Limit(limit).Find(&audits).Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the retention audit entries")
+		return nil, err1
+	}
+
+	auditBodies := make([]RetentionAuditBody, len(audits))
+	for idx, audit := range audits {
+		auditBodies[idx] = RetentionAuditBody{
+			RetentionReportEntry: RetentionReportEntry{
+				Rule:       audit.Rule,
+				Action:     audit.Action,
+				EntityType: audit.EntityType,
+				EntityId:   audit.EntityId,
+				AthleteId:  audit.AthleteId,
+				Reason:     audit.Reason,
+			},
+			RunId: audit.RunId,
+			RunAt: audit.CreatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return auditBodies, nil
+}
@@ -0,0 +1,53 @@
+package retentionManagement
+
+import (
+	"net/http"
+
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/jobScheduler"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type RunRetentionResponse struct {
+	Message  string `json:"message" example:"Retention policies started"`
+	JobRunId uint   `json:"job_run_id" example:"1"`
+}
+
+// RunRetention applies the retention policies immediately
+// @Summary Applies the retention policies
+// @Description Starts the retention job in the background, which deletes or anonymizes all entries affected by the configured retention policies and records them in the audit trail.
+// @Description The job holds the same lock as the scheduled runs, so it never runs on two instances at the same time. The result can be followed with /v1/job/runs/retention and the audit trail.
+// @Tags Retention Management
+// @Produce json
+// @Param Authorization  header  string  false  "Settings access JWT is sent in the Authorization header or set as a http-only cookie"
+// @Success 202 {object} RunRetentionResponse "Retention policies started"
+// @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
+// @Failure 409 {object} endpoints.ErrorResponse "The retention policies are already being applied"
+// @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
+// @Router /v1/retention/run [post]
+func RunRetention(c *gin.Context) {
+	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "RunRetention")
+	defer span.End()
+
+	run, err1 := jobScheduler.Trigger(ctx, JobName)
+	if errors.Is(err1, jobScheduler.JobAlreadyRunningError) {
+		endpoints.Logger.Debug(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusConflict, endpoints.ErrorResponse{Error: "The retention policies are already being applied"})
+		return
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to start the retention job")
+		endpoints.Logger.Error(ctx, err1)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to run the retention policies"})
+		return
+	}
+	endpoints.Logger.Info(ctx, "Retention policies started manually")
+
+	c.JSON(
+		http.StatusAccepted,
+		RunRetentionResponse{
+			Message:  "Retention policies started",
+			JobRunId: run.ID,
+		},
+	)
+}
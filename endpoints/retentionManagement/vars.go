@@ -0,0 +1,59 @@
+package retentionManagement
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/joho/godotenv"
+	"github.com/pkg/errors"
+)
+
+// JobName is the name of the retention job in the scheduler
+const JobName = "retention"
+
+var (
+	policies RetentionPolicies
+
+	// Schedule is the cron expression of the retention job
+	Schedule string
+)
+
+func init() {
+	ctx := context.Background()
+
+	// Load the environment variables
+	if err := godotenv.Load(".env"); err != nil {
+		endpoints.Logger.Fatal(ctx, "Failed to load environment variables")
+	}
+
+	policies = RetentionPolicies{
+		SwimCertificateRetentionYears: getIntVariable(ctx, "RETENTION_SWIM_CERTIFICATE_YEARS", 0),
+		InactiveAthleteYears:          getIntVariable(ctx, "RETENTION_INACTIVE_ATHLETE_YEARS", 0),
+	}
+
+	// Get the schedule of the retention job
+	Schedule = os.Getenv("RETENTION_SCHEDULE")
+	if Schedule == "" {
+		endpoints.Logger.Debug(ctx, "RETENTION_SCHEDULE not set, using default")
+		Schedule = "0 3 * * *"
+	}
+}
+
+// getIntVariable parses the given environment variable and falls back to the default if it is not set or invalid
+func getIntVariable(ctx context.Context, name string, defaultValue int) int {
+	valueString := os.Getenv(name)
+	if valueString == "" {
+		endpoints.Logger.Debug(ctx, name+" not set, using default")
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(valueString)
+	if err != nil || value < 0 {
+		err = errors.New("Failed to parse " + name + ", using default")
+		endpoints.Logger.Error(ctx, err)
+		return defaultValue
+	}
+	return value
+}
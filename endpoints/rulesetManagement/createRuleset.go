@@ -1,9 +1,7 @@
 package rulesetManagement
 
 import (
-	"encoding/csv"
 	"fmt"
-	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,8 +9,13 @@ import (
 
 	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/LucaSchmitz2003/FlowWatch"
+	"github.com/Team-Reissdorf/Backend/authHelper"
+	"github.com/Team-Reissdorf/Backend/csvHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/Team-Reissdorf/Backend/xlsxHelper"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
@@ -20,13 +23,17 @@ import (
 
 // CreateRuleset creates new ruleset entries in the db from a csv file
 // @Summary Creates new ruleset entries from csv file
-// @Description Upload a CSV file to create multiple ruleset entries. Needs to contain 11 columns.
+// @Description Upload a CSV file or an XLSX workbook to create multiple ruleset entries. Needs to contain 11 columns. The encoding, the delimiter and a header row are detected automatically. Of workbooks, the first sheet or the sheet selected with sheet is imported.
+// @Description With dry_run, the file is only validated and the preview shows which exercises and exercise goals would be created or updated. The preview is created in the background and can be polled with the import job. It can be imported with /v1/import/commit afterward.
 // @Tags Ruleset Management
 // @Accept multipart/form-data
 // @Produce json
-// @Param RulesetEntries formData file true "CSV file containing details of the ruleset"
+// @Param RulesetEntries formData file true "CSV or XLSX file containing details of the ruleset"
+// @Param dry_run query bool false "Only validate the file, the preview of every row can be polled with the import job"
+// @Param sheet query string false "Name of the sheet of a XLSX workbook, defaults to the first sheet"
 // @Param Authorization  header  string  false  "Access JWT is sent in the Authorization header or set as a http-only cookie"
 // @Success 200 {object} endpoints.SuccessResponse "Creation successful"
+// @Success 202 {object} importJobs.ImportJobResponse "Preview of the ruleset entries started (dry run)"
 // @Failure 400 {object} endpoints.ErrorResponse "Invalid request body"
 // @Failure 401 {object} endpoints.ErrorResponse "The token is invalid"
 // @Failure 409 {object} endpoints.ErrorResponse "All ruleset entries already exist; none have been created"
@@ -36,6 +43,19 @@ func CreateRuleset(c *gin.Context) {
 	ctx, span := endpoints.Tracer.Start(c.Request.Context(), "CreateRulesetEntries")
 	defer span.End()
 
+	// Check if only a preview is requested
+	dryRun := false
+	if dryRunString := c.Query("dry_run"); dryRunString != "" {
+		var err0 error
+		dryRun, err0 = strconv.ParseBool(dryRunString)
+		if err0 != nil {
+			err0 = errors.Wrap(err0, "Invalid 'dry_run' query parameter")
+			endpoints.Logger.Debug(ctx, err0)
+			c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Invalid 'dry_run' query parameter"})
+			return
+		}
+	}
+
 	// Bind body to csv file
 	file, err1 := c.FormFile("RulesetEntries")
 	if err1 != nil || file == nil {
@@ -45,10 +65,8 @@ func CreateRuleset(c *gin.Context) {
 		return
 	}
 
-	// Check MIME type
-	fileHeader := file.Header.Get("Content-Type")
-	if !strings.HasPrefix(fileHeader, "text/csv") && !strings.HasPrefix(fileHeader, "application/vnd.ms-excel") {
-		err := errors.New("Invalid file type, only CSV files are allowed")
+	// Check the file type
+	if err := csvHelper.CheckFileType(file); err != nil {
 		endpoints.Logger.Debug(ctx, err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err.Error()})
 		return
@@ -57,30 +75,39 @@ func CreateRuleset(c *gin.Context) {
 	// Get the user id from the context
 	// trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
 
-	// Open the CSV file
-	fileContent, err2 := file.Open()
-	if err2 != nil {
-		err2 = errors.Wrap(err2, "Failed to open file")
-		endpoints.Logger.Debug(ctx, err2)
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "Could not open file"})
+	// Read the CSV file or the workbook
+	csvFile, err3 := csvHelper.ReadUpload(file, csvHelper.Options{DetectHeader: true, Sheet: c.Query("sheet")})
+	if errors.Is(err3, csvHelper.EmptyFileError) || errors.Is(err3, xlsxHelper.SheetNotFoundError) {
+		endpoints.Logger.Debug(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: err3.Error()})
+		return
+	} else if err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to read the file. Invalid CSV or XLSX format?")
+		endpoints.Logger.Warn(ctx, err3)
+		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File could not be read. Invalid CSV or XLSX format?"})
 		return
 	}
-	defer func(fileContent multipart.File) {
-		err := fileContent.Close()
-		if err != nil {
-			err = errors.Wrap(err, "Failed to close file")
-			endpoints.Logger.Error(ctx, err)
+
+	endpoints.Logger.Debug(ctx, fmt.Sprintf("Read %s file with encoding %s and delimiter %q", csvFile.Format, csvFile.Encoding, csvFile.Delimiter))
+	records := csvFile.Records
+
+	// Only validate the rows in the background, the preview can be polled with the import job
+	if dryRun {
+		trainerEmail := authHelper.GetUserIdFromContext(ctx, c)
+		previewJob, errPreview := importJobs.Preview(ctx, rulesetImporter, trainerEmail, file.Filename, records)
+		if errPreview != nil {
+			endpoints.Logger.Error(ctx, errPreview)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to preview the ruleset entries"})
+			return
 		}
-	}(fileContent)
-
-	// Read the CSV file
-	reader := csv.NewReader(fileContent)
-	reader.Comma = ';'
-	records, err3 := reader.ReadAll()
-	if err3 != nil {
-		err3 = errors.Wrap(err3, "Failed to read csv. Invalid CSV format?")
-		endpoints.Logger.Warn(ctx, err3)
-		c.AbortWithStatusJSON(http.StatusBadRequest, endpoints.ErrorResponse{Error: "File could not be read. Invalid CSV format?"})
+
+		c.JSON(
+			http.StatusAccepted,
+			importJobs.ImportJobResponse{
+				Message:   "Preview started",
+				ImportJob: importJobs.TranslateImportJobToResponse(*previewJob),
+			},
+		)
 		return
 	}
 
@@ -179,7 +206,8 @@ func CreateRuleset(c *gin.Context) {
 		rulesets = append(rulesets, rulesetBody)
 	}
 
-	// Write ruleset data to the database
+	// Write ruleset data to the database, the cache is invalidated even if only a part has been written
+	defer rulesetCache.Invalidate(ctx, "ruleset creation")
 	db := DatabaseFlow.GetDB(ctx)
 	for idx, ruleset := range rulesets {
 		// Ensure the ruleset year exists
@@ -2,12 +2,11 @@ package rulesetManagement
 
 import (
 	"fmt"
-	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
-	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 )
@@ -64,24 +63,17 @@ func GetRulesets(c *gin.Context) {
 		return
 	}
 
-	// Query the database for the exercise goals
-	var rulesets []databaseUtils.ExerciseGoal
-	err = DatabaseFlow.GetDB(ctx).
-		Model(&databaseUtils.ExerciseGoal{}).
-		Joins("JOIN exercise_rulesets ON exercise_goals.ruleset_id = exercise_rulesets.id").
-		Joins("JOIN exercises ON exercise_rulesets.exercise_id = exercises.id").
-		Where("exercise_rulesets.ruleset_year = ? AND exercise_rulesets.exercise_id = ?", strconv.Itoa(int(year)), strconv.Itoa(int(exerciseId))).
-		Find(&rulesets).
-		Error
-	if errors.Is(err, gorm.ErrRecordNotFound) || len(rulesets) == 0 {
-		endpoints.Logger.Debug(ctx, "Rulesets not found")
-		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: fmt.Sprintf("Ruleset not found for year: %d and exercise Id: %d", year, exerciseId)})
-		return
-	} else if err != nil {
+	// Get the exercise goals from the ruleset cache
+	rulesets, err := rulesetCache.GetExerciseGoals(ctx, uint(exerciseId), int(year))
+	if err != nil {
 		err = errors.Wrap(err, "Failed to retrieve rulesets")
 		endpoints.Logger.Error(ctx, err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to retrieve rulesets"})
 		return
+	} else if len(rulesets) == 0 {
+		endpoints.Logger.Debug(ctx, "Rulesets not found")
+		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: fmt.Sprintf("Ruleset not found for year: %d and exercise Id: %d", year, exerciseId)})
+		return
 	}
 
 	c.JSON(
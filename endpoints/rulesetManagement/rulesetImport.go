@@ -0,0 +1,263 @@
+package rulesetManagement
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	CreateGoalAction = "create"
+	UpdateGoalAction = "update"
+)
+
+// rulesetImporter validates ruleset entries for a dry run and writes them when the preview is committed
+var rulesetImporter = importJobs.Importer{
+	Type:          "rulesets",
+	ValidateRow:   validateRulesetRow,
+	CreateEntries: createRulesetEntries,
+}
+
+func init() {
+	importJobs.RegisterImporter(rulesetImporter)
+}
+
+// parseRulesetRecord converts a csv record to a ruleset body and normalizes the sex attribute
+func parseRulesetRecord(record []string) (RulesetBody, error) {
+	// Ensure the column count is correct
+	if len(record) != CSVCOLUMNCOUNT {
+		return RulesetBody{}, errors.New("Inconsistent number of columns")
+	}
+
+	// Parse age and goal values
+	var values [5]int
+	valueNames := []string{"from age", "to age", "Bronze", "Silver", "Gold"}
+	for idx := range values {
+		value, err := strconv.Atoi(record[5+idx])
+		if err != nil {
+			return RulesetBody{}, errors.New(fmt.Sprintf("Invalid %s value: %s", valueNames[idx], record[5+idx]))
+		}
+		values[idx] = value
+	}
+
+	sex := strings.TrimSpace(strings.ToLower(record[4]))
+	if len(sex) == 0 {
+		return RulesetBody{}, errors.New("Sex attribute cannot be empty")
+	}
+	sex = sex[:1]
+
+	// Normalize the sex attribute
+	switch sex {
+	case "m", "f", "d":
+
+	case "w":
+		sex = "f"
+	default:
+		return RulesetBody{}, errors.New(fmt.Sprintf("Invalid sex attribute: %s", sex))
+	}
+
+	return RulesetBody{
+		RulesetYear:    record[0],
+		DisciplineName: record[1],
+		ExerciseName:   record[2],
+		Unit:           record[3],
+		Sex:            sex,
+		FromAge:        uint(values[0]),
+		ToAge:          uint(values[1]),
+		Bronze:         uint64(values[2]),
+		Silver:         uint64(values[3]),
+		Gold:           uint64(values[4]),
+		Description:    record[10],
+	}, nil
+}
+
+// validateRulesetRow parses a ruleset record and determines which entries would be created or updated
+func validateRulesetRow(ctx context.Context, _ string, record []string) (interface{}, *importJobs.RowError) {
+	ruleset, err1 := parseRulesetRecord(record)
+	if err1 != nil {
+		FlowWatch.GetLogHelper().Debug(ctx, err1)
+		return nil, importJobs.RowFailed(err1.Error())
+	}
+
+	preview := RulesetPreviewBody{
+		RulesetBody: ruleset,
+		Action:      CreateGoalAction,
+	}
+	disciplineName := CapitalizeFirst(ruleset.DisciplineName)
+
+	err2 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		// Check if the ruleset year exists
+		var rulesetYearCount int64
+		if err := tx.Model(&databaseUtils.Ruleset{}).
+			Where("year = ?", ruleset.RulesetYear).
+			Count(&rulesetYearCount).
+			Error; err != nil {
+			return err
+		}
+		preview.NewRulesetYear = rulesetYearCount == 0
+
+		// Check if the exercise exists
+		var exercise databaseUtils.Exercise
+		err := tx.Model(&databaseUtils.Exercise{}).
+			Where("name = ? AND discipline_name = ?", ruleset.ExerciseName, disciplineName).
+			First(&exercise).
+			Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			preview.NewExercise = true
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// Check if the exercise goal already exists
+		var exerciseGoalCount int64
+		if err := tx.Model(&databaseUtils.ExerciseGoal{}).
+			Joins("JOIN exercise_rulesets ON exercise_rulesets.id = exercise_goals.ruleset_id").
+			Where("exercise_rulesets.ruleset_year = ? AND exercise_rulesets.exercise_id = ?", ruleset.RulesetYear, exercise.ID).
+			Where("exercise_goals.from_age = ? AND exercise_goals.to_age = ? AND exercise_goals.sex = ?", ruleset.FromAge, ruleset.ToAge, ruleset.Sex).
+			Count(&exerciseGoalCount).
+			Error; err != nil {
+			return err
+		}
+		if exerciseGoalCount > 0 {
+			preview.Action = UpdateGoalAction
+		}
+		return nil
+	})
+	if err2 != nil {
+		FlowWatch.GetLogHelper().Error(ctx, errors.Wrap(err2, "Failed to check the ruleset entry"))
+		return nil, importJobs.RowFailed("Could not check the ruleset entry")
+	}
+
+	// New exercises need a valid unit and an existing discipline
+	if preview.NewExercise {
+		if !Contains(POSSIBLEUNITS, strings.ToLower(ruleset.Unit)) {
+			return nil, importJobs.RowFailed(fmt.Sprintf("Invalid unit: %s", ruleset.Unit))
+		}
+
+		var disciplineCount int64
+		err3 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+			return tx.Model(&databaseUtils.Discipline{}).
+				Where("name = ?", disciplineName).
+				Count(&disciplineCount).
+				Error
+		})
+		if err3 != nil {
+			FlowWatch.GetLogHelper().Error(ctx, errors.Wrap(err3, "Failed to check the discipline"))
+			return nil, importJobs.RowFailed("Could not check the discipline")
+		}
+		if disciplineCount == 0 {
+			return nil, importJobs.RowFailed(fmt.Sprintf("Discipline does not exist: %s", disciplineName))
+		}
+	}
+
+	return preview, nil
+}
+
+// createRulesetEntries writes the ruleset entries of a committed preview and returns the ids of the exercise goals
+func createRulesetEntries(ctx context.Context, entries []interface{}) ([]uint, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "CreateRulesetEntries")
+	defer span.End()
+
+	ids := make([]uint, len(entries))
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		for idx, entry := range entries {
+			goalId, err := writeRulesetEntry(tx, entry.(RulesetPreviewBody).RulesetBody)
+			if err != nil {
+				return err
+			}
+			ids[idx] = goalId
+		}
+		return nil
+	})
+	err1 = databaseUtils.TranslatePostgresError(err1)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to write the ruleset entries")
+		return nil, err1
+	}
+	rulesetCache.Invalidate(ctx, "ruleset import")
+
+	return ids, nil
+}
+
+// writeRulesetEntry creates the ruleset year, the exercise and the exercise ruleset if needed
+// and creates or updates the exercise goal of the given ruleset entry
+func writeRulesetEntry(tx *gorm.DB, ruleset RulesetBody) (uint, error) {
+	disciplineName := CapitalizeFirst(ruleset.DisciplineName)
+
+	rulesetYear := databaseUtils.Ruleset{Year: ruleset.RulesetYear}
+	if err := tx.Where(&rulesetYear).FirstOrCreate(&rulesetYear).Error; err != nil {
+		return 0, errors.Wrap(err, "Failed to create the ruleset year")
+	}
+
+	var exercise databaseUtils.Exercise
+	err1 := tx.Where("name = ? AND discipline_name = ?", ruleset.ExerciseName, disciplineName).
+		Attrs(databaseUtils.Exercise{
+			Name:           ruleset.ExerciseName,
+			Unit:           strings.ToLower(ruleset.Unit),
+			DisciplineName: disciplineName,
+		}).
+		FirstOrCreate(&exercise).
+		Error
+	if err1 != nil {
+		return 0, errors.Wrap(err1, "Failed to create the exercise")
+	}
+
+	var exerciseRuleset databaseUtils.ExerciseRuleset
+	err2 := tx.Where("ruleset_year = ? AND exercise_id = ?", ruleset.RulesetYear, exercise.ID).
+		Attrs(databaseUtils.ExerciseRuleset{RulesetYear: ruleset.RulesetYear, ExerciseId: exercise.ID}).
+		FirstOrCreate(&exerciseRuleset).
+		Error
+	if err2 != nil {
+		return 0, errors.Wrap(err2, "Failed to create the exercise ruleset")
+	}
+
+	var exerciseGoal databaseUtils.ExerciseGoal
+	err3 := tx.Where("ruleset_id = ? AND from_age = ? AND to_age = ? AND sex = ?",
+		exerciseRuleset.ID, ruleset.FromAge, ruleset.ToAge, ruleset.Sex).
+		First(&exerciseGoal).
+		Error
+	if errors.Is(err3, gorm.ErrRecordNotFound) {
+		// Create the exercise goal
+		exerciseGoal = databaseUtils.ExerciseGoal{
+			RulesetId:   exerciseRuleset.ID,
+			FromAge:     ruleset.FromAge,
+			ToAge:       ruleset.ToAge,
+			Sex:         ruleset.Sex,
+			Bronze:      ruleset.Bronze,
+			Silver:      ruleset.Silver,
+			Gold:        ruleset.Gold,
+			Description: ruleset.Description,
+		}
+		if err := tx.Create(&exerciseGoal).Error; err != nil {
+			return 0, errors.Wrap(err, "Failed to create the exercise goal")
+		}
+	} else if err3 != nil {
+		return 0, errors.Wrap(err3, "Failed to get the exercise goal")
+	} else {
+		// Update the existing exercise goal
+		err4 := tx.Model(&exerciseGoal).
+			Updates(map[string]interface{}{
+				"bronze":      ruleset.Bronze,
+				"silver":      ruleset.Silver,
+				"gold":        ruleset.Gold,
+				"description": ruleset.Description,
+			}).
+			Error
+		if err4 != nil {
+			return 0, errors.Wrap(err4, "Failed to update the exercise goal")
+		}
+	}
+
+	return exerciseGoal.ID, nil
+}
@@ -17,3 +17,10 @@ type RulesetBody struct {
 	Gold           uint64 `json:"gold"`
 	Description    string `json:"description"`
 }
+
+type RulesetPreviewBody struct {
+	RulesetBody
+	Action         string `json:"action" example:"create"` // create or update of the exercise goal
+	NewRulesetYear bool   `json:"new_ruleset_year"`
+	NewExercise    bool   `json:"new_exercise"`
+}
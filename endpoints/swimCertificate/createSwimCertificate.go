@@ -13,6 +13,7 @@ import (
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints"
 	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/badgeManagement"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -24,7 +25,7 @@ import (
 
 // CreateSwimCertificate handles the upload of a swim certificate for an athlete
 // @Summary Uploads a swim certificate for an athlete.
-// @Description A Trainer can upload a swim certificate as a file for an specified athlete.
+// @Description A Trainer can upload a swim certificate as a file for an specified athlete. For minors, a guardian consent with the store_documents scope is required.
 // @Tags Swim Certificate
 // @Accept multipart/form-data
 // @Produce json
@@ -34,6 +35,7 @@ import (
 // @Success 200 {object} endpoints.SuccessResponse "Upload successful"
 // @Failure 400 {object} endpoints.ErrorResponse "Invalid request"
 // @Failure 401 {object} endpoints.ErrorResponse "Unauthorized"
+// @Failure 403 {object} endpoints.ErrorResponse "Guardian consent to store documents is missing"
 // @Failure 500 {object} endpoints.ErrorResponse "Internal server error"
 // @Router /v1/swimCertificate/create/{AthleteId} [post]
 func CreateSwimCertificate(c *gin.Context) {
@@ -82,6 +84,27 @@ func CreateSwimCertificate(c *gin.Context) {
 		endpoints.Logger.Debug(ctx, fmt.Sprintf("Athlete with id %d exists and is assigned to the given trainer", athleteID))
 	}
 
+	// Check if the guardian consented to store documents of a minor
+	athlete, errGetAthlete := athleteManagement.GetAthlete(ctx, uint(athleteID), trainerEmail)
+	if errGetAthlete != nil {
+		errGetAthlete = errors.Wrap(errGetAthlete, "Failed to get the athlete")
+		endpoints.Logger.Error(ctx, errGetAthlete)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to get the athlete"})
+		return
+	}
+	consentMissing, errCheckConsent := athleteManagement.IsConsentMissing(ctx, *athlete, athleteManagement.ConsentScopeStoreDocuments)
+	if errCheckConsent != nil {
+		errCheckConsent = errors.Wrap(errCheckConsent, "Failed to check the consent of the athlete")
+		endpoints.Logger.Error(ctx, errCheckConsent)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, endpoints.ErrorResponse{Error: "Failed to check the consent"})
+		return
+	}
+	if consentMissing {
+		endpoints.Logger.Debug(ctx, fmt.Sprintf("Consent to store documents is missing for athlete with id %d", athleteID))
+		c.AbortWithStatusJSON(http.StatusForbidden, endpoints.ErrorResponse{Error: "Guardian consent to store documents is missing"})
+		return
+	}
+
 	//create directory to store files
 	uploadDir := filepath.Join("uploads", "swimCertificates", "athlete_"+strconv.Itoa(athleteID))
 	if errCreateDir := os.MkdirAll(uploadDir, os.ModePerm); errCreateDir != nil {
@@ -119,6 +142,13 @@ func CreateSwimCertificate(c *gin.Context) {
 		return
 	}
 
+	// The swim certificate can complete the badges of the athlete
+	errRecordBadges := badgeManagement.RecordEarnedBadges(ctx, trainerEmail, []uint{uint(athleteID)})
+	if errRecordBadges != nil { // the certificate is saved, the badges are recorded with the next change
+		errRecordBadges = errors.Wrap(errRecordBadges, "Failed to record the earned badges")
+		endpoints.Logger.Error(ctx, errRecordBadges)
+	}
+
 	//return succesful response
 	c.JSON(http.StatusOK, endpoints.SuccessResponse{Message: "File uploaded successfully"})
 }
@@ -2,11 +2,8 @@ package swimCertificate
 
 import (
 	"archive/zip"
-	"io"
 	"net/http"
-	"os"
-	"strconv"	
-	"path/filepath"
+	"strconv"
 
 	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/Team-Reissdorf/Backend/authHelper"
@@ -77,61 +74,24 @@ func DownloadAllSwimCertificates(c *gin.Context) {
 			Order("date DESC").
 			Find(&certificates).Error
 	})
-	if errGetSCFromDatabase != nil{
+	if errGetSCFromDatabase != nil {
 		errGetSCFromDatabase = errors.Wrap(errGetSCFromDatabase, "Failed to get swim certificates")
 		endpoints.Logger.Error(ctx, errGetSCFromDatabase)
 		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "Failed to get swim certificates"})
 		return
 	}
 	if len(certificates) == 0 {
-		endpoints.Logger.Debug(ctx, "No swim certificates found for athlete with ID: " + athleteIdString)
+		endpoints.Logger.Debug(ctx, "No swim certificates found for athlete with ID: "+athleteIdString)
 		c.AbortWithStatusJSON(http.StatusNotFound, endpoints.ErrorResponse{Error: "No swim certificates found for this athlete"})
 		return
 	}
-	
 
-	// create ZIP for response 
+	// create ZIP for response
 	c.Writer.Header().Set("Content-Type", "application/zip")
 	c.Writer.Header().Set("Content-Disposition", "attachment; filename=swim_certificates.zip")
 
 	zipWriter := zip.NewWriter(c.Writer)
 	defer zipWriter.Close()
 
-	// copy swim certificates to ZIP & rename to original 
-	usedNames := make(map[string]int) // map to find duplicates  
-	for _, cert := range certificates {
-		originalName := cert.OriginalFileName
-		baseName := originalName
-		counter := 1
-
-		// change file name if duplicate name: file.txt -> file (1).txt
-		for {
-			if _, exists := usedNames[baseName]; !exists {
-				break
-			}
-			ext := filepath.Ext(originalName)
-			nameOnly := originalName[:len(originalName)-len(ext)]
-			baseName = nameOnly + " (" + strconv.Itoa(counter) + ")" + ext
-			counter++
-		}
-		usedNames[baseName] = 1
-
-		file, errOpenFile := os.Open(cert.DocumentPath)
-		if errOpenFile != nil {
-			endpoints.Logger.Warn(ctx, "Error opening swim certificate file: "+cert.DocumentPath)
-			continue
-		}
-		defer file.Close()
-
-		fw, errCreateZIP := zipWriter.Create(baseName)
-		if errCreateZIP != nil {
-			endpoints.Logger.Warn(ctx, "Failed to add swim certificate file to zip folder "+baseName)
-			continue
-		}
-		_, errCopyToZIP := io.Copy(fw, file)
-		if errCopyToZIP != nil {
-			endpoints.Logger.Warn(ctx, errors.Wrap(errCopyToZIP, "Failed to write file content to ZIP for: "+baseName))
-			continue
-		}
-	}
-}
\ No newline at end of file
+	_ = WriteSwimCertificatesToZip(ctx, zipWriter, certificates, "")
+}
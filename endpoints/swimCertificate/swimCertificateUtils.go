@@ -0,0 +1,96 @@
+package swimCertificate
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetSwimCertificatesOfAthlete returns all swim certificates of the given athlete, the newest first
+func GetSwimCertificatesOfAthlete(ctx context.Context, athleteId uint) ([]databaseUtils.SwimCertificate, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetSwimCertificatesOfAthleteFromDB")
+	defer span.End()
+
+	var certificates []databaseUtils.SwimCertificate
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.SwimCertificate{}).
+			Where("athlete_id = ?", athleteId).
+			Order("date DESC").
+			Find(&certificates).Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get swim certificates")
+		return nil, err1
+	}
+
+	return certificates, nil
+}
+
+// WriteSwimCertificatesToZip copies the files of the given swim certificates into the zip folder
+// and renames them to their original file names. Files that cannot be read are skipped.
+// Returns the path inside the zip folder for every certificate (empty if the certificate was skipped).
+func WriteSwimCertificatesToZip(ctx context.Context, zipWriter *zip.Writer, certificates []databaseUtils.SwimCertificate, folder string) []string {
+	ctx, span := endpoints.Tracer.Start(ctx, "WriteSwimCertificatesToZip")
+	defer span.End()
+
+	// copy swim certificates to ZIP & rename to original
+	usedNames := make(map[string]int) // map to find duplicates
+	zipPaths := make([]string, len(certificates))
+	for idx, cert := range certificates {
+		originalName := cert.OriginalFileName
+		baseName := originalName
+		counter := 1
+
+		// change file name if duplicate name: file.txt -> file (1).txt
+		for {
+			if _, exists := usedNames[baseName]; !exists {
+				break
+			}
+			ext := filepath.Ext(originalName)
+			nameOnly := originalName[:len(originalName)-len(ext)]
+			baseName = nameOnly + " (" + strconv.Itoa(counter) + ")" + ext
+			counter++
+		}
+		usedNames[baseName] = 1
+
+		zipPath := path.Join(folder, baseName)
+		if writeFileToZip(ctx, zipWriter, cert.DocumentPath, zipPath) {
+			zipPaths[idx] = zipPath
+		}
+	}
+
+	return zipPaths
+}
+
+// writeFileToZip copies a single file from the disk into the zip folder and reports if it was successful
+func writeFileToZip(ctx context.Context, zipWriter *zip.Writer, filePath string, zipPath string) bool {
+	file, errOpenFile := os.Open(filePath)
+	if errOpenFile != nil {
+		endpoints.Logger.Warn(ctx, "Error opening swim certificate file: "+filePath)
+		return false
+	}
+	defer file.Close()
+
+	fw, errCreateZIP := zipWriter.Create(zipPath)
+	if errCreateZIP != nil {
+		endpoints.Logger.Warn(ctx, "Failed to add swim certificate file to zip folder "+zipPath)
+		return false
+	}
+	_, errCopyToZIP := io.Copy(fw, file)
+	if errCopyToZIP != nil {
+		endpoints.Logger.Warn(ctx, errors.Wrap(errCopyToZIP, "Failed to write file content to ZIP for: "+zipPath))
+		return false
+	}
+
+	return true
+}
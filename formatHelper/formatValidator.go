@@ -18,12 +18,14 @@ import (
 const (
 	localEmailCheckRegexString = ".*\\.[a-zA-Z]{2,}(\\.)?$"
 	dateFormatCheckRegexString = "^\\d{4}\\-[0-1][0-9]\\-[0-3][0-9]$"
+	phoneNumberRegexString     = "^\\+?[0-9 ()/\\-]{6,25}$"
 )
 
 var (
 	possibleSexValues    = []string{"m", "f", "d"}
 	localEmailCheckRegex *regexp.Regexp
 	dateFormatCheckRegex *regexp.Regexp
+	phoneNumberRegex     *regexp.Regexp
 
 	InvalidSexLengthError          = errors.New("Sex should be one character only")
 	InvalidSexValue                = errors.New("Sex can only be <m|f|d>")
@@ -33,6 +35,7 @@ var (
 	DateFormatInvalidError         = errors.New("Date format is invalid")
 	DateInFutureError              = errors.New("Date is in the future")
 	EmptyStringError               = errors.New("Empty String")
+	InvalidPhoneNumberError        = errors.New("Phone number format is invalid")
 )
 
 func init() {
@@ -49,6 +52,12 @@ func init() {
 	if err2 != nil {
 		endpoints.Logger.Fatal(ctx, "Unable to compile date format regex", err2)
 	}
+
+	var err3 error
+	phoneNumberRegex, err3 = regexp.Compile(phoneNumberRegexString)
+	if err3 != nil {
+		endpoints.Logger.Fatal(ctx, "Unable to compile phone number regex", err3)
+	}
 }
 
 // IsEmail validates the email address using the mail.ParseAddress function
@@ -135,6 +144,15 @@ func IsSex(sex string) error {
 	return nil
 }
 
+// IsPhoneNumber checks if the given phone number only contains digits, spaces and the usual separators.
+// Throws: InvalidPhoneNumberError
+func IsPhoneNumber(phoneNumber string) error {
+	if !phoneNumberRegex.MatchString(phoneNumber) {
+		return InvalidPhoneNumberError
+	}
+	return nil
+}
+
 func IsEmpty(bodyPart string) error {
 	if len(bodyPart) == 0 {
 		return EmptyStringError
@@ -144,7 +162,7 @@ func IsEmpty(bodyPart string) error {
 
 // IsDuration checks whether s is a valid duration in the format S, SS, M:SS or MM:SS.
 // Seconds must be 0-59; any non-negative number can be used for minutes,
-// but only 1 or 2 digits in the string.
+// but only 1 or 2 digits in the string. The seconds can have up to three decimals (e.g. 7,85 or 1:05.4).
 func IsDuration(s string) error {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -155,7 +173,10 @@ func IsDuration(s string) error {
 	switch len(parts) {
 	case 1:
 		// "S" or "SS"
-		seg := parts[0]
+		seg, _, errF := splitSecondFraction(parts[0])
+		if errF != nil {
+			return errF
+		}
 		if len(seg) < 1 || len(seg) > 2 {
 			return fmt.Errorf("seconds must be 1 or 2 digits")
 		}
@@ -171,6 +192,10 @@ func IsDuration(s string) error {
 	case 2:
 		// "M:SS" or "MM:SS"
 		minSeg, secSeg := parts[0], parts[1]
+		secSeg, _, errF := splitSecondFraction(secSeg)
+		if errF != nil {
+			return errF
+		}
 
 		// Minutes: 1–2 digits, >=0
 		if len(minSeg) < 1 || len(minSeg) > 2 {
@@ -256,7 +281,7 @@ func FormatToCentimeters(input string) (int, error) {
 }
 
 // NormalizeResult standardizes the result into ms or cm depending on the unit.
-// For "second" and "minute", accepts S, SS, M:SS or MM:SS and converts to milliseconds.
+// For "second" and "minute", accepts S, SS, M:SS or MM:SS (optionally with decimals) and converts to milliseconds.
 func NormalizeResult(raw string, unit string) (int, error) {
 	unit = strings.ToLower(strings.TrimSpace(unit))
 	FlowWatch.GetLogHelper().Debug(context.Background(), "Normal input", raw, unit)
@@ -315,19 +340,27 @@ func parseToMilliseconds(raw string) (int, error) {
 	switch len(seg) {
 	case 1:
 		// "S" or "SS"
-		sec, err := strconv.Atoi(seg[0])
+		secSeg, ms, errF := splitSecondFraction(seg[0])
+		if errF != nil {
+			return 0, errF
+		}
+		sec, err := strconv.Atoi(secSeg)
 		if err != nil {
 			return 0, fmt.Errorf("invalid seconds: %q", seg[0])
 		}
 		if sec < 0 || sec > 59 {
 			return 0, fmt.Errorf("seconds must be 0-59: %d", sec)
 		}
-		return sec * 1000, nil
+		return sec*1000 + ms, nil
 
 	case 2:
 		// "M:SS" or "MM:SS"
+		secSeg, ms, errF := splitSecondFraction(seg[1])
+		if errF != nil {
+			return 0, errF
+		}
 		min, errMin := strconv.Atoi(seg[0])
-		sec, errSec := strconv.Atoi(seg[1])
+		sec, errSec := strconv.Atoi(secSeg)
 		if errMin != nil {
 			return 0, fmt.Errorf("invalid minutes: %q", seg[0])
 		}
@@ -340,9 +373,27 @@ func parseToMilliseconds(raw string) (int, error) {
 		if sec < 0 || sec > 59 {
 			return 0, fmt.Errorf("seconds must be 0-59: %d", sec)
 		}
-		return (min*60 + sec) * 1000, nil
+		return (min*60+sec)*1000 + ms, nil
 
 	default:
 		return 0, fmt.Errorf("invalid time format, use S, SS, M:SS or MM:SS: %q", raw)
 	}
 }
+
+// splitSecondFraction splits the decimals (separated by . or ,) from the seconds and converts them to milliseconds
+func splitSecondFraction(secSeg string) (string, int, error) {
+	idx := strings.IndexAny(secSeg, ".,")
+	if idx < 0 {
+		return secSeg, 0, nil
+	}
+
+	fraction := secSeg[idx+1:]
+	if len(fraction) < 1 || len(fraction) > 3 {
+		return "", 0, fmt.Errorf("seconds can have 1 to 3 decimals: %q", secSeg)
+	}
+	ms, err := strconv.Atoi((fraction + "00")[:3])
+	if err != nil || ms < 0 {
+		return "", 0, fmt.Errorf("decimals of the seconds must be numeric: %q", secSeg)
+	}
+	return secSeg[:idx], ms, nil
+}
@@ -0,0 +1,70 @@
+package formatHelper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// germanTranscriptions maps special german characters to their common ascii transcription
+var germanTranscriptions = strings.NewReplacer(
+	"ä", "ae",
+	"ö", "oe",
+	"ü", "ue",
+	"ß", "ss",
+	"é", "e",
+	"è", "e",
+	"á", "a",
+	"à", "a",
+)
+
+// NormalizeName lowercases the given name, transcribes umlauts (e.g. "Müller" -> "mueller")
+// and removes all characters that are neither letters nor digits.
+func NormalizeName(name string) string {
+	name = germanTranscriptions.Replace(strings.ToLower(strings.TrimSpace(name)))
+
+	var builder strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// LevenshteinDistance returns the minimum number of single character edits (insertions, deletions or substitutions)
+// required to change one string into the other.
+func LevenshteinDistance(a, b string) int {
+	runesA := []rune(a)
+	runesB := []rune(b)
+
+	if len(runesA) == 0 {
+		return len(runesB)
+	} else if len(runesB) == 0 {
+		return len(runesA)
+	}
+
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			cost := 1
+			if runesA[i-1] == runesB[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(runesB)]
+}
+
+// NameDistance compares two names after normalizing them and returns their levenshtein distance
+func NameDistance(a, b string) int {
+	return LevenshteinDistance(NormalizeName(a), NormalizeName(b))
+}
@@ -0,0 +1,61 @@
+package importJobs
+
+import "encoding/json"
+
+// RowResult is the result of a single row of an import. It extends the row number and reason of the
+// performanceManagement.FailedPerformanceEntry with the status of the row, the id of the created entry, a note and the preview.
+type RowResult struct {
+	Row      int             `json:"row" example:"2"`
+	Status   string          `json:"status" example:"failed"`
+	Reason   string          `json:"reason,omitempty" example:"Athlete not found"`
+	EntityId uint            `json:"entity_id,omitempty" example:"1"`
+	Note     string          `json:"note,omitempty" example:"Created athlete Bob Alice (ID 12)"`
+	Preview  json.RawMessage `json:"preview,omitempty" swaggertype:"object"` // Entry that would be created by a dry run
+}
+
+type ImportJobBody struct {
+	ImportJobId     uint   `json:"import_job_id" example:"1"`
+	Type            string `json:"type" example:"performances"`
+	FileName        string `json:"file_name" example:"results.csv"`
+	Status          string `json:"status" example:"running"`
+	DryRun          bool   `json:"dry_run"`
+	TotalRows       int    `json:"total_rows" example:"250"`
+	ProcessedRows   int    `json:"processed_rows" example:"100"`
+	CreatedRows     int    `json:"created_rows" example:"95"`
+	SkippedRows     int    `json:"skipped_rows" example:"2"`
+	FailedRows      int    `json:"failed_rows" example:"3"`
+	ValidRows       int    `json:"valid_rows" example:"0"` // Rows of a dry run that would be created
+	Progress        int    `json:"progress" example:"40"`  // Percentage of the processed rows
+	CancelRequested bool   `json:"cancel_requested"`
+	Error           string `json:"error,omitempty" example:""`
+	CreatedAt       string `json:"created_at" example:"2025-01-31T12:00:00Z"`
+	StartedAt       string `json:"started_at,omitempty" example:"2025-01-31T12:00:01Z"`
+	FinishedAt      string `json:"finished_at,omitempty" example:"2025-01-31T12:00:30Z"`
+	CommittedJobId  uint   `json:"committed_job_id,omitempty" example:"2"` // Import job that committed this preview
+}
+
+type ImportJobResponse struct {
+	Message   string        `json:"message" example:"Import started"`
+	ImportJob ImportJobBody `json:"import_job"`
+}
+
+type ImportJobWithRowsResponse struct {
+	Message   string        `json:"message" example:"Request successful"`
+	ImportJob ImportJobBody `json:"import_job"`
+	Rows      []RowResult   `json:"rows"`
+}
+
+type ImportProfileBody struct {
+	Name       string            `json:"name" example:"School export"`
+	Type       string            `json:"type" example:"performances"`
+	Delimiter  string            `json:"delimiter" example:","`            // Detected if empty
+	SkipRows   int               `json:"skip_rows" example:"1"`            // Rows before the data, the last one is used as header
+	DateFormat string            `json:"date_format" example:"DD.MM.YYYY"` // Tokens: YYYY, YY, MM, M, DD, D
+	Columns    map[string]string `json:"columns"`                          // Field -> column name of the header or 1-based column index
+	SexValues  map[string]string `json:"sex_values"`                       // Value in the file -> <m|f|d>
+}
+
+type ImportProfileBodyWithId struct {
+	ImportProfileId uint `json:"import_profile_id" example:"1"`
+	ImportProfileBody
+}
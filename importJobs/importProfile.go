@@ -0,0 +1,195 @@
+package importJobs
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/pkg/errors"
+)
+
+var (
+	InvalidImportProfileError      = errors.New("The import profile is invalid")
+	MappingNotSupportedError       = errors.New("The import type does not support mapping profiles")
+	ImportProfileTypeMismatchError = errors.New("The import profile belongs to another import type")
+	HeaderColumnNotFoundError      = errors.New("Column not found in the header")
+
+	possibleSexValues = []string{"m", "f", "d"}
+
+	// dateFormatTokens translates the date tokens of a profile to the go layout, longest tokens first
+	dateFormatTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02", "M", "1", "D", "2")
+
+	// layoutTokens are the year, month and day tokens of a go layout, longest tokens first
+	layoutTokens = [][]string{{"2006", "06"}, {"01", "1"}, {"02", "2"}}
+)
+
+// ValidateImportProfile checks the profile against the fields of its import type and normalizes it
+// Throws: InvalidImportProfileError, MappingNotSupportedError, UnknownImportTypeError
+func ValidateImportProfile(profile *databaseUtils.ImportProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return errors.Wrap(InvalidImportProfileError, "The name is missing")
+	}
+
+	importer, err1 := getImporter(profile.Type)
+	if err1 != nil {
+		return err1
+	}
+	if !importer.AcceptsProfiles || len(importer.Fields) == 0 {
+		return MappingNotSupportedError
+	}
+
+	// An empty delimiter is detected for every file
+	if profile.Delimiter != "" && utf8.RuneCountInString(profile.Delimiter) != 1 {
+		return errors.Wrap(InvalidImportProfileError, "The delimiter has to be a single character")
+	}
+
+	if profile.SkipRows < 0 {
+		return errors.Wrap(InvalidImportProfileError, "The number of rows to skip cannot be negative")
+	}
+
+	if profile.DateFormat != "" {
+		layout := dateFormatTokens.Replace(profile.DateFormat)
+		if !hasDateTokens(layout) {
+			return errors.Wrap(InvalidImportProfileError, "The date format needs a day, a month and a year")
+		}
+	}
+
+	// Check the columns of the fields
+	columns := make(map[string]string, len(profile.Columns))
+	for field, column := range profile.Columns {
+		field = strings.ToLower(strings.TrimSpace(field))
+		column = strings.TrimSpace(column)
+		if !slices.Contains(importer.Fields, field) {
+			return errors.Wrap(InvalidImportProfileError, fmt.Sprintf("Unknown field %s, possible fields are %s", field, strings.Join(importer.Fields, ", ")))
+		}
+		if column == "" {
+			return errors.Wrap(InvalidImportProfileError, fmt.Sprintf("The column of the field %s is missing", field))
+		}
+		if index, err := strconv.Atoi(column); err == nil && index < 1 {
+			return errors.Wrap(InvalidImportProfileError, fmt.Sprintf("The column index of the field %s has to start at 1", field))
+		} else if err != nil && profile.SkipRows == 0 {
+			return errors.Wrap(InvalidImportProfileError, fmt.Sprintf("The field %s uses a column name, but no header row is skipped", field))
+		}
+		columns[field] = column
+	}
+	for _, field := range importer.RequiredFields {
+		if _, exists := columns[field]; !exists {
+			return errors.Wrap(InvalidImportProfileError, fmt.Sprintf("The required field %s is not mapped", field))
+		}
+	}
+	profile.Columns = columns
+
+	// Check the sex values
+	sexValues := make(map[string]string, len(profile.SexValues))
+	for value, sex := range profile.SexValues {
+		sex = strings.ToLower(strings.TrimSpace(sex))
+		if !slices.Contains(possibleSexValues, sex) {
+			return errors.Wrap(InvalidImportProfileError, fmt.Sprintf("The sex value %s has to be mapped to <m|f|d>", value))
+		}
+		sexValues[strings.ToLower(strings.TrimSpace(value))] = sex
+	}
+	profile.SexValues = sexValues
+
+	return nil
+}
+
+// hasDateTokens checks if the layout contains a year, a month and a day.
+// Every found token is removed before the next one is searched, so that e.g. the 2 of 2006 is not taken for the day.
+func hasDateTokens(layout string) bool {
+	for _, tokens := range layoutTokens {
+		idx := slices.IndexFunc(tokens, func(token string) bool {
+			return strings.Contains(layout, token)
+		})
+		if idx < 0 {
+			return false
+		}
+		layout = strings.Replace(layout, tokens[idx], "", 1)
+	}
+	return true
+}
+
+// GetDelimiter returns the delimiter of the csv files of the profile, zero if it should be detected
+func GetDelimiter(profile *databaseUtils.ImportProfile) rune {
+	if profile.Delimiter == "" {
+		return 0
+	}
+	delimiter, _ := utf8.DecodeRuneInString(profile.Delimiter)
+	return delimiter
+}
+
+// ApplyImportProfile skips the leading rows and rearranges the records to the fixed column layout of the importer.
+// Dates are converted to YYYY-MM-DD and the sex values are mapped, invalid values are kept for the row validation.
+// Throws: HeaderColumnNotFoundError, ImportProfileTypeMismatchError, UnknownImportTypeError
+func ApplyImportProfile(profile *databaseUtils.ImportProfile, importType string, records [][]string) ([][]string, error) {
+	if profile.Type != importType {
+		return nil, ImportProfileTypeMismatchError
+	}
+	importer, err1 := getImporter(profile.Type)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	var header []string
+	if profile.SkipRows > 0 && len(records) >= profile.SkipRows {
+		header = records[profile.SkipRows-1]
+	}
+
+	// Resolve the column indices of the fields
+	indices := make([]int, len(importer.Fields))
+	for idx, field := range importer.Fields {
+		indices[idx] = -1
+
+		column, exists := profile.Columns[field]
+		if !exists {
+			continue
+		}
+		if index, err := strconv.Atoi(column); err == nil {
+			indices[idx] = index - 1
+			continue
+		}
+		headerIndex := slices.IndexFunc(header, func(name string) bool {
+			return strings.EqualFold(strings.TrimSpace(name), column)
+		})
+		if headerIndex < 0 {
+			return nil, errors.Wrap(HeaderColumnNotFoundError, column)
+		}
+		indices[idx] = headerIndex
+	}
+
+	var layout string
+	if profile.DateFormat != "" {
+		layout = dateFormatTokens.Replace(profile.DateFormat)
+	}
+
+	var mappedRecords [][]string
+	for rowIdx := profile.SkipRows; rowIdx < len(records); rowIdx++ {
+		record := records[rowIdx]
+		mappedRecord := make([]string, len(importer.Fields))
+		for idx, field := range importer.Fields {
+			if indices[idx] < 0 || indices[idx] >= len(record) {
+				continue
+			}
+			value := strings.TrimSpace(record[indices[idx]])
+
+			if layout != "" && slices.Contains(importer.DateFields, field) {
+				if date, err := time.Parse(layout, value); err == nil {
+					value = date.Format("2006-01-02")
+				}
+			} else if field == "sex" {
+				if sex, exists := profile.SexValues[strings.ToLower(value)]; exists {
+					value = sex
+				}
+			}
+
+			mappedRecord[idx] = value
+		}
+		mappedRecords = append(mappedRecords, mappedRecord)
+	}
+
+	return mappedRecords, nil
+}
@@ -0,0 +1,154 @@
+package importJobs
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ImportProfileNotFoundError      = errors.New("Import profile not found")
+	ImportProfileAlreadyExistsError = errors.New("An import profile with this name already exists")
+)
+
+// GetImportProfile returns the import profile with the given id of the given trainer
+// Throws: ImportProfileNotFoundError and other
+func GetImportProfile(ctx context.Context, profileId uint, trainerEmail string) (*databaseUtils.ImportProfile, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetImportProfileFromDB")
+	defer span.End()
+
+	var profile databaseUtils.ImportProfile
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportProfile{}).
+			Where("id = ? AND trainer_email = ?", profileId, strings.ToLower(trainerEmail)).
+			First(&profile).
+			Error
+	})
+	if errors.Is(err1, gorm.ErrRecordNotFound) {
+		return nil, ImportProfileNotFoundError
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the import profile")
+		return nil, err1
+	}
+
+	return &profile, nil
+}
+
+// GetImportProfilesOfTrainer returns all import profiles of the given trainer ordered by their name
+func GetImportProfilesOfTrainer(ctx context.Context, trainerEmail string) ([]databaseUtils.ImportProfile, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetImportProfilesOfTrainerFromDB")
+	defer span.End()
+
+	var profiles []databaseUtils.ImportProfile
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportProfile{}).
+			Where("trainer_email = ?", strings.ToLower(trainerEmail)).
+			Order("name ASC").
+			Find(&profiles).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the import profiles")
+		return nil, err1
+	}
+
+	return profiles, nil
+}
+
+// CreateImportProfile validates and writes a new import profile to the database
+// Throws: ImportProfileAlreadyExistsError, errors of ValidateImportProfile and other
+func CreateImportProfile(ctx context.Context, profile *databaseUtils.ImportProfile) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "CreateImportProfile")
+	defer span.End()
+
+	if err := ValidateImportProfile(profile); err != nil {
+		return err
+	}
+	profile.TrainerEmail = strings.ToLower(profile.TrainerEmail)
+
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Create(profile).Error
+	})
+	err1 = databaseUtils.TranslatePostgresError(err1)
+	if errors.Is(err1, databaseUtils.ErrForeignKeyViolation) {
+		return ImportProfileAlreadyExistsError
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to create the import profile")
+		return err1
+	}
+
+	return nil
+}
+
+// UpdateImportProfile validates and overwrites an existing import profile
+// Throws: ImportProfileAlreadyExistsError, errors of ValidateImportProfile and other
+func UpdateImportProfile(ctx context.Context, profile *databaseUtils.ImportProfile) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "UpdateImportProfile")
+	defer span.End()
+
+	if err := ValidateImportProfile(profile); err != nil {
+		return err
+	}
+
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportProfile{}).
+			Where("id = ? AND trainer_email = ?", profile.ID, strings.ToLower(profile.TrainerEmail)).
+			Select("name", "type", "delimiter", "skip_rows", "date_format", "columns", "sex_values").
+			Updates(profile).
+			Error
+	})
+	err1 = databaseUtils.TranslatePostgresError(err1)
+	if errors.Is(err1, databaseUtils.ErrForeignKeyViolation) {
+		return ImportProfileAlreadyExistsError
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to update the import profile")
+		return err1
+	}
+
+	return nil
+}
+
+// DeleteImportProfile deletes the import profile with the given id of the given trainer
+// Throws: ImportProfileNotFoundError and other
+func DeleteImportProfile(ctx context.Context, profileId uint, trainerEmail string) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "DeleteImportProfile")
+	defer span.End()
+
+	var affectedRows int64
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND trainer_email = ?", profileId, strings.ToLower(trainerEmail)).
+			Delete(&databaseUtils.ImportProfile{})
+		affectedRows = result.RowsAffected
+		return result.Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to delete the import profile")
+		return err1
+	}
+	if affectedRows == 0 {
+		return ImportProfileNotFoundError
+	}
+
+	return nil
+}
+
+// TranslateImportProfileToResponse converts an import profile database object to the response type
+func TranslateImportProfileToResponse(profile databaseUtils.ImportProfile) ImportProfileBodyWithId {
+	return ImportProfileBodyWithId{
+		ImportProfileId: profile.ID,
+		ImportProfileBody: ImportProfileBody{
+			Name:       profile.Name,
+			Type:       profile.Type,
+			Delimiter:  profile.Delimiter,
+			SkipRows:   profile.SkipRows,
+			DateFormat: profile.DateFormat,
+			Columns:    profile.Columns,
+			SexValues:  profile.SexValues,
+		},
+	}
+}
@@ -0,0 +1,561 @@
+package importJobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	QueuedStatus    = "queued"
+	RunningStatus   = "running"
+	SucceededStatus = "succeeded"
+	FailedStatus    = "failed"
+	CancelledStatus = "cancelled"
+
+	CreatedRowStatus = "created"
+	SkippedRowStatus = "skipped"
+	FailedRowStatus  = "failed"
+	ValidRowStatus   = "valid" // The row would be created by a dry run
+
+	// batchSize is the number of rows that are written to the database at once
+	batchSize = 50
+)
+
+var (
+	ImportJobNotFoundError  = errors.New("Import job not found")
+	ImportJobFinishedError  = errors.New("The import job has already finished")
+	EntryCountMismatchError = errors.New("The number of created ids does not match the number of entries")
+
+	ImportJobNotPreviewError     = errors.New("The import job is not a dry run")
+	PreviewNotCommittableError   = errors.New("Only dry runs that finished successfully can be committed")
+	PreviewAlreadyCommittedError = errors.New("The dry run has already been committed")
+	UnknownImportTypeError       = errors.New("No importer is registered for the import type")
+)
+
+var (
+	// staleImportJobTimeout is the time after which a job without progress is treated as interrupted
+	staleImportJobTimeout = 15 * time.Minute
+
+	// runningImportJobs holds the cancel functions of the jobs running on this instance
+	runningImportJobs      = make(map[uint]context.CancelFunc)
+	runningImportJobsMutex sync.Mutex
+
+	// importers holds the registered importers by their type, so that previews can be committed
+	importers      = make(map[string]Importer)
+	importersMutex sync.RWMutex
+)
+
+// RowError describes why a row is not imported
+type RowError struct {
+	Status string
+	Reason string
+}
+
+func (err *RowError) Error() string {
+	return err.Reason
+}
+
+// RowFailed marks a row as invalid
+func RowFailed(reason string) *RowError {
+	return &RowError{Status: FailedRowStatus, Reason: reason}
+}
+
+// RowSkipped marks a row that does not need to be imported (e.g. the entry already exists)
+func RowSkipped(reason string) *RowError {
+	return &RowError{Status: SkippedRowStatus, Reason: reason}
+}
+
+// Importer defines how the rows of an import type are processed
+type Importer struct {
+	Type string
+
+	// ValidateRow checks a single record and returns the entry that will be created.
+	// If the row cannot be imported, a RowError is returned.
+	ValidateRow func(ctx context.Context, trainerEmail string, record []string) (interface{}, *RowError)
+
+	// CreateEntries writes the valid entries of one batch to the database and returns their ids in the same order
+	CreateEntries func(ctx context.Context, entries []interface{}) ([]uint, error)
+
+	// Preview converts a valid entry to the body shown by a dry run (optional, the entry itself is shown otherwise)
+	Preview func(entry interface{}) interface{}
+
+	// Note describes side effects of a created entry in its row result (optional). It is called after CreateEntries succeeded.
+	Note func(entry interface{}) string
+
+	// Fields are the names of the columns in the order expected by ValidateRow. They can be mapped by import profiles.
+	Fields         []string
+	RequiredFields []string
+	DateFields     []string // Fields that are converted to YYYY-MM-DD by import profiles
+
+	// AcceptsProfiles is set if the upload endpoint of the import type applies import profiles (see ApplyImportProfile)
+	AcceptsProfiles bool
+}
+
+// RegisterImporter makes the importer available for committing previews of its type.
+// It is meant to be called from the init function of the package defining the importer.
+func RegisterImporter(importer Importer) {
+	importersMutex.Lock()
+	defer importersMutex.Unlock()
+
+	importers[importer.Type] = importer
+}
+
+// getImporter returns the registered importer of the given type
+// Throws: UnknownImportTypeError
+func getImporter(importType string) (Importer, error) {
+	importersMutex.RLock()
+	defer importersMutex.RUnlock()
+
+	importer, exists := importers[importType]
+	if !exists {
+		return Importer{}, errors.Wrap(UnknownImportTypeError, importType)
+	}
+	return importer, nil
+}
+
+// pendingEntry is a valid row waiting to be written to the database
+type pendingEntry struct {
+	row   int
+	entry interface{}
+}
+
+// Start creates an import job for the given records and processes it in the background
+func Start(ctx context.Context, importer Importer, trainerEmail string, fileName string, records [][]string) (*databaseUtils.ImportJob, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "StartImportJob")
+	defer span.End()
+
+	job, err1 := createJob(ctx, importer, trainerEmail, fileName, records, false)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	runInBackground(ctx, importer, job, records)
+	return job, nil
+}
+
+// Preview creates a dry run for the given records, which validates them in the background without creating any entries.
+// The rows that would be created get the valid status and a preview of the entry.
+// The records are kept with the job, so that the preview can be committed afterward.
+func Preview(ctx context.Context, importer Importer, trainerEmail string, fileName string, records [][]string) (*databaseUtils.ImportJob, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "PreviewImportJob")
+	defer span.End()
+
+	job, err1 := createJob(ctx, importer, trainerEmail, fileName, records, true)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	// Large files take longer than the clients wait for a response, so the preview is polled like an import
+	runInBackground(ctx, importer, job, records)
+	return job, nil
+}
+
+// runInBackground processes the import job in the background, so that it can be cancelled on this instance
+func runInBackground(ctx context.Context, importer Importer, job *databaseUtils.ImportJob, records [][]string) {
+	// The import needs to outlive the http request. The cancel context only signals a cancellation,
+	// so that database operations of the current batch are not aborted.
+	runCtx := context.WithoutCancel(ctx)
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	runningImportJobsMutex.Lock()
+	runningImportJobs[job.ID] = cancel
+	runningImportJobsMutex.Unlock()
+
+	runningJob := *job
+	go func() {
+		defer func() {
+			runningImportJobsMutex.Lock()
+			delete(runningImportJobs, runningJob.ID)
+			runningImportJobsMutex.Unlock()
+			cancel()
+		}()
+		run(runCtx, cancelCtx, importer, &runningJob, records)
+	}()
+}
+
+// Commit imports the records of a finished dry run for real and returns the new import job.
+// The rows are validated again, since the data might have changed since the preview.
+// Throws: ImportJobNotFoundError, ImportJobNotPreviewError, PreviewNotCommittableError, PreviewAlreadyCommittedError,
+// UnknownImportTypeError and other
+func Commit(ctx context.Context, previewJobId uint, trainerEmail string) (*databaseUtils.ImportJob, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "CommitImportJob")
+	defer span.End()
+
+	previewJob, err1 := GetImportJob(ctx, previewJobId, trainerEmail)
+	if err1 != nil {
+		return nil, err1
+	}
+	if !previewJob.DryRun {
+		return nil, ImportJobNotPreviewError
+	}
+	if previewJob.Status != SucceededStatus {
+		return nil, PreviewNotCommittableError
+	}
+
+	importer, err2 := getImporter(previewJob.Type)
+	if err2 != nil {
+		return nil, err2
+	}
+
+	var records [][]string
+	if err3 := json.Unmarshal([]byte(previewJob.Records), &records); err3 != nil {
+		err3 = errors.Wrap(err3, "Failed to decode the records of the dry run")
+		return nil, err3
+	}
+
+	// Claim the preview first, so that it cannot be committed twice by concurrent requests
+	var affectedRows int64
+	err4 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&databaseUtils.ImportJob{}).
+			Where("id = ? AND committed_at IS NULL", previewJob.ID).
+			Update("committed_at", time.Now())
+		affectedRows = result.RowsAffected
+		return result.Error
+	})
+	if err4 != nil {
+		err4 = errors.Wrap(err4, "Failed to claim the dry run")
+		return nil, err4
+	}
+	if affectedRows == 0 {
+		return nil, PreviewAlreadyCommittedError
+	}
+
+	job, err5 := Start(ctx, importer, trainerEmail, previewJob.FileName, records)
+	if err5 != nil {
+		// Release the preview again, so that the commit can be retried
+		errRelease := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+			return tx.Model(&databaseUtils.ImportJob{}).
+				Where("id = ?", previewJob.ID).
+				Update("committed_at", nil).
+				Error
+		})
+		if errRelease != nil {
+			endpoints.Logger.Error(ctx, errors.Wrap(errRelease, "Failed to release the dry run"))
+		}
+		return nil, err5
+	}
+
+	err6 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportJob{}).
+			Where("id = ?", previewJob.ID).
+			Update("committed_job_id", job.ID).
+			Error
+	})
+	if err6 != nil {
+		endpoints.Logger.Error(ctx, errors.Wrap(err6, "Failed to link the dry run to its import job"))
+	}
+
+	return job, nil
+}
+
+// createJob writes a new queued import job for the given records to the database
+func createJob(ctx context.Context, importer Importer, trainerEmail string, fileName string, records [][]string, dryRun bool) (*databaseUtils.ImportJob, error) {
+	job := databaseUtils.ImportJob{
+		Type:         importer.Type,
+		FileName:     fileName,
+		Status:       QueuedStatus,
+		TotalRows:    len(records),
+		DryRun:       dryRun,
+		TrainerEmail: strings.ToLower(trainerEmail),
+	}
+
+	// Keep the records of a dry run for the commit
+	if dryRun {
+		encodedRecords, err1 := json.Marshal(records)
+		if err1 != nil {
+			err1 = errors.Wrap(err1, "Failed to encode the records")
+			return nil, err1
+		}
+		job.Records = string(encodedRecords)
+	}
+
+	err2 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Create(&job).Error
+	})
+	if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to create the import job")
+		return nil, err2
+	}
+
+	return &job, nil
+}
+
+// run processes all records of the import job and keeps the progress in the database up to date
+func run(ctx context.Context, cancelCtx context.Context, importer Importer, job *databaseUtils.ImportJob, records [][]string) {
+	ctx, span := endpoints.Tracer.Start(ctx, "RunImportJob")
+	defer span.End()
+
+	startedAt := time.Now()
+	job.StartedAt = &startedAt
+	job.Status = RunningStatus
+	if err := updateJob(ctx, job); err != nil {
+		endpoints.Logger.Error(ctx, err)
+	}
+
+	var pending []pendingEntry
+	var results []RowResult
+	for idx, record := range records {
+		if cancelCtx.Err() != nil || (idx%batchSize == 0 && isCancelled(ctx, job.ID)) {
+			finishJob(ctx, job, CancelledStatus, "")
+			return
+		}
+
+		row := idx + 1
+		entry, rowErr := validateRow(ctx, importer, job.TrainerEmail, record)
+		if rowErr != nil {
+			results = append(results, RowResult{Row: row, Status: rowErr.Status, Reason: rowErr.Reason})
+		} else if job.DryRun {
+			results = append(results, previewEntry(ctx, importer, row, entry))
+		} else {
+			pending = append(pending, pendingEntry{row: row, entry: entry})
+		}
+
+		// Write the batch to the database
+		if len(pending)+len(results) >= batchSize || row == len(records) {
+			results = append(results, createEntries(ctx, importer, pending)...)
+			if err := writeResults(ctx, job, results); err != nil {
+				finishJob(ctx, job, FailedStatus, err.Error())
+				return
+			}
+			job.ProcessedRows = row
+			if err := updateJob(ctx, job); err != nil {
+				endpoints.Logger.Error(ctx, err)
+			}
+			pending, results = nil, nil
+		}
+	}
+
+	finishJob(ctx, job, SucceededStatus, "")
+	endpoints.Logger.Info(ctx, fmt.Sprintf("Import job %d finished: %d created, %d valid, %d skipped, %d failed",
+		job.ID, job.CreatedRows, job.ValidRows, job.SkippedRows, job.FailedRows))
+}
+
+// previewEntry returns the result of a valid row of a dry run with the entry that would be created
+func previewEntry(ctx context.Context, importer Importer, row int, entry interface{}) RowResult {
+	preview := entry
+	if importer.Preview != nil {
+		preview = importer.Preview(entry)
+	}
+
+	encodedPreview, err := json.Marshal(preview)
+	if err != nil {
+		endpoints.Logger.Warn(ctx, errors.Wrap(err, fmt.Sprintf("Failed to encode the preview of row %d", row)))
+		return RowResult{Row: row, Status: ValidRowStatus}
+	}
+	return RowResult{Row: row, Status: ValidRowStatus, Preview: encodedPreview}
+}
+
+// validateRow validates a single row and converts a panic into a failed row
+func validateRow(ctx context.Context, importer Importer, trainerEmail string, record []string) (entry interface{}, rowErr *RowError) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			endpoints.Logger.Error(ctx, fmt.Sprintf("Import row panicked: %v", recovered))
+			entry, rowErr = nil, RowFailed("Row could not be processed")
+		}
+	}()
+
+	return importer.ValidateRow(ctx, trainerEmail, record)
+}
+
+// createEntries writes the pending entries of a batch to the database.
+// If the batch fails (e.g. duplicates within the file), the entries are written one by one to find the failing rows.
+func createEntries(ctx context.Context, importer Importer, pending []pendingEntry) []RowResult {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	entries := make([]interface{}, len(pending))
+	for idx, pendingRow := range pending {
+		entries[idx] = pendingRow.entry
+	}
+
+	results := make([]RowResult, 0, len(pending))
+	ids, err1 := importer.CreateEntries(ctx, entries)
+	if err1 == nil && len(ids) != len(pending) {
+		err1 = EntryCountMismatchError
+	}
+	if err1 == nil {
+		for idx, pendingRow := range pending {
+			results = append(results, RowResult{Row: pendingRow.row, Status: CreatedRowStatus, EntityId: ids[idx], Note: entryNote(importer, pendingRow.entry)})
+		}
+		return results
+	}
+	endpoints.Logger.Debug(ctx, errors.Wrap(err1, "Failed to write the batch, retrying row by row"))
+
+	for _, pendingRow := range pending {
+		rowIds, err2 := importer.CreateEntries(ctx, []interface{}{pendingRow.entry})
+		if err2 != nil || len(rowIds) != 1 {
+			endpoints.Logger.Debug(ctx, errors.Wrap(err2, fmt.Sprintf("Failed to write row %d", pendingRow.row)))
+			reason := "Entry could not be saved"
+			if errors.Is(err2, databaseUtils.ErrForeignKeyViolation) {
+				reason = "Entry already exists or references a missing entry"
+			}
+			results = append(results, RowResult{Row: pendingRow.row, Status: FailedRowStatus, Reason: reason})
+			continue
+		}
+		results = append(results, RowResult{Row: pendingRow.row, Status: CreatedRowStatus, EntityId: rowIds[0], Note: entryNote(importer, pendingRow.entry)})
+	}
+	return results
+}
+
+// entryNote returns the note of a created entry if the importer defines one
+func entryNote(importer Importer, entry interface{}) string {
+	if importer.Note == nil {
+		return ""
+	}
+	return importer.Note(entry)
+}
+
+// writeResults persists the row results and updates the counters of the job
+func writeResults(ctx context.Context, job *databaseUtils.ImportJob, results []RowResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	rows := make([]databaseUtils.ImportJobRow, len(results))
+	for idx, result := range results {
+		rows[idx] = databaseUtils.ImportJobRow{
+			Row:         result.Row,
+			Status:      result.Status,
+			Reason:      result.Reason,
+			EntityId:    result.EntityId,
+			Note:        result.Note,
+			Preview:     string(result.Preview),
+			ImportJobId: job.ID,
+		}
+
+		switch result.Status {
+		case CreatedRowStatus:
+			job.CreatedRows++
+		case SkippedRowStatus:
+			job.SkippedRows++
+		case ValidRowStatus:
+			job.ValidRows++
+		default:
+			job.FailedRows++
+		}
+	}
+
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Create(&rows).Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to write the import results")
+		return err1
+	}
+	return nil
+}
+
+// updateJob writes the progress of the job to the database
+func updateJob(ctx context.Context, job *databaseUtils.ImportJob) error {
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportJob{}).
+			Where("id = ?", job.ID).
+			Select("status", "processed_rows", "created_rows", "skipped_rows", "failed_rows", "valid_rows", "error", "started_at", "finished_at").
+			Updates(job).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to update the import job")
+		return err1
+	}
+	return nil
+}
+
+// finishJob marks the job as finished with the given status
+func finishJob(ctx context.Context, job *databaseUtils.ImportJob, status string, errorMessage string) {
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = status
+	job.Error = errorMessage
+	if err := updateJob(ctx, job); err != nil {
+		endpoints.Logger.Error(ctx, err)
+	}
+}
+
+// isCancelled checks if the import job got cancelled on another instance of the backend
+func isCancelled(ctx context.Context, jobId uint) bool {
+	var cancelRequested bool
+	err := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportJob{}).
+			Where("id = ?", jobId).
+			Pluck("cancel_requested", &cancelRequested).
+			Error
+	})
+	if err != nil {
+		endpoints.Logger.Warn(ctx, errors.Wrap(err, "Failed to check if the import job got cancelled"))
+		return false
+	}
+	return cancelRequested
+}
+
+// Cancel requests the cancellation of an import job of the given trainer.
+// The job stops before the next batch, so already written entries are kept.
+// Throws: ImportJobNotFoundError, ImportJobFinishedError and other
+func Cancel(ctx context.Context, jobId uint, trainerEmail string) error {
+	ctx, span := endpoints.Tracer.Start(ctx, "CancelImportJob")
+	defer span.End()
+
+	job, err1 := GetImportJob(ctx, jobId, trainerEmail)
+	if err1 != nil {
+		return err1
+	}
+	if job.Status != QueuedStatus && job.Status != RunningStatus {
+		return ImportJobFinishedError
+	}
+
+	err2 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportJob{}).
+			Where("id = ?", jobId).
+			Update("cancel_requested", true).
+			Error
+	})
+	if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to cancel the import job")
+		return err2
+	}
+
+	// Stop the job immediately if it runs on this instance
+	runningImportJobsMutex.Lock()
+	if cancel, exists := runningImportJobs[jobId]; exists {
+		cancel()
+	}
+	runningImportJobsMutex.Unlock()
+
+	return nil
+}
+
+// MarkStaleImportJobs marks import jobs as failed that have not made progress for a while,
+// e.g. because the instance running them was restarted. It is executed as a job of the scheduler.
+func MarkStaleImportJobs(ctx context.Context) (string, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "MarkStaleImportJobs")
+	defer span.End()
+
+	var affectedRows int64
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&databaseUtils.ImportJob{}).
+			Where("status IN ? AND updated_at < ?", []string{QueuedStatus, RunningStatus}, time.Now().Add(-staleImportJobTimeout)).
+			Updates(map[string]interface{}{
+				"status":      FailedStatus,
+				"error":       "The import was interrupted",
+				"finished_at": time.Now(),
+			})
+		affectedRows = result.RowsAffected
+		return result.Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to mark the stale import jobs")
+		return "", err1
+	}
+
+	return fmt.Sprintf("%d stale import jobs marked as failed", affectedRows), nil
+}
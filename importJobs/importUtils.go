@@ -0,0 +1,134 @@
+package importJobs
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// GetImportJob returns the import job with the given id of the given trainer
+// Throws: ImportJobNotFoundError and other
+func GetImportJob(ctx context.Context, jobId uint, trainerEmail string) (*databaseUtils.ImportJob, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetImportJobFromDB")
+	defer span.End()
+
+	var job databaseUtils.ImportJob
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportJob{}).
+			Where("id = ? AND trainer_email = ?", jobId, strings.ToLower(trainerEmail)).
+			First(&job).
+			Error
+	})
+	if errors.Is(err1, gorm.ErrRecordNotFound) {
+		return nil, ImportJobNotFoundError
+	} else if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the import job")
+		return nil, err1
+	}
+
+	return &job, nil
+}
+
+// GetImportJobsOfTrainer returns the latest import jobs of the given trainer, newest first
+func GetImportJobsOfTrainer(ctx context.Context, trainerEmail string, limit int) ([]databaseUtils.ImportJob, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetImportJobsOfTrainerFromDB")
+	defer span.End()
+
+	var jobs []databaseUtils.ImportJob
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.ImportJob{}).
+			Where("trainer_email = ?", strings.ToLower(trainerEmail)).
+			Order("id DESC").
+			Limit(limit).
+			Find(&jobs).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the import jobs")
+		return nil, err1
+	}
+
+	return jobs, nil
+}
+
+// GetRowResults returns the row results of the given import job, optionally filtered by their status.
+// A negative limit returns all rows.
+func GetRowResults(ctx context.Context, jobId uint, status string, offset int, limit int) ([]RowResult, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetImportRowResultsFromDB")
+	defer span.End()
+
+	var rows []databaseUtils.ImportJobRow
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		query := tx.Model(&databaseUtils.ImportJobRow{}).
+			Where("import_job_id = ?", jobId)
+		if status != "" {
+			query = query.Where("status = ?", status)
+		}
+		return query.Order("import_job_rows.row ASC").
+			Offset(offset).
+			Limit(limit).
+			Find(&rows).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the import results")
+		return nil, err1
+	}
+
+	results := make([]RowResult, len(rows))
+	for idx, row := range rows {
+		results[idx] = RowResult{
+			Row:      row.Row,
+			Status:   row.Status,
+			Reason:   row.Reason,
+			EntityId: row.EntityId,
+			Note:     row.Note,
+		}
+		if row.Preview != "" {
+			results[idx].Preview = json.RawMessage(row.Preview)
+		}
+	}
+	return results, nil
+}
+
+// TranslateImportJobToResponse converts an import job database object to the response type
+func TranslateImportJobToResponse(job databaseUtils.ImportJob) ImportJobBody {
+	jobBody := ImportJobBody{
+		ImportJobId:     job.ID,
+		Type:            job.Type,
+		FileName:        job.FileName,
+		Status:          job.Status,
+		DryRun:          job.DryRun,
+		TotalRows:       job.TotalRows,
+		ProcessedRows:   job.ProcessedRows,
+		CreatedRows:     job.CreatedRows,
+		SkippedRows:     job.SkippedRows,
+		FailedRows:      job.FailedRows,
+		ValidRows:       job.ValidRows,
+		Progress:        100,
+		CancelRequested: job.CancelRequested,
+		Error:           job.Error,
+		CreatedAt:       job.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if job.TotalRows > 0 {
+		jobBody.Progress = job.ProcessedRows * 100 / job.TotalRows
+	}
+	if job.StartedAt != nil {
+		jobBody.StartedAt = job.StartedAt.UTC().Format(time.RFC3339)
+	}
+	if job.FinishedAt != nil {
+		jobBody.FinishedAt = job.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	if job.CommittedJobId != nil {
+		jobBody.CommittedJobId = *job.CommittedJobId
+	}
+
+	return jobBody
+}
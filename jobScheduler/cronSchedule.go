@@ -0,0 +1,179 @@
+package jobScheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	InvalidScheduleError = errors.New("Invalid schedule")
+)
+
+// cronField holds the allowed values of one field of a cron expression
+type cronField struct {
+	values     map[int]bool
+	restricted bool // False if the field is a wildcard
+}
+
+// Schedule calculates the next execution time of a job
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// cronSchedule is a schedule defined by a cron expression with the fields minute, hour, day of month, month and day of week
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek cronField
+}
+
+// intervalSchedule is a schedule with a fixed interval (@every 1h)
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+var scheduleDescriptors = map[string]string{
+	"@yearly":  "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly":  "0 0 * * 0",
+	"@daily":   "0 0 * * *",
+	"@hourly":  "0 * * * *",
+}
+
+// ParseSchedule parses a cron expression (e.g. "30 3 * * 1-5"), a descriptor (e.g. "@daily")
+// or a fixed interval (e.g. "@every 6h").
+// Throws: InvalidScheduleError
+func ParseSchedule(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+
+	if intervalString, found := strings.CutPrefix(expression, "@every "); found {
+		interval, err := time.ParseDuration(strings.TrimSpace(intervalString))
+		if err != nil || interval < time.Minute {
+			return nil, errors.Wrap(InvalidScheduleError, "The interval needs to be at least one minute: "+expression)
+		}
+		return intervalSchedule{interval: interval}, nil
+	}
+	if descriptor, found := scheduleDescriptors[expression]; found {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, errors.Wrap(InvalidScheduleError, "A cron expression needs five fields: "+expression)
+	}
+
+	var schedule cronSchedule
+	var err error
+	if schedule.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, errors.Wrap(err, "Minute")
+	}
+	if schedule.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, errors.Wrap(err, "Hour")
+	}
+	if schedule.dayOfMonth, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, errors.Wrap(err, "Day of month")
+	}
+	if schedule.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, errors.Wrap(err, "Month")
+	}
+	if schedule.dayOfWeek, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, errors.Wrap(err, "Day of week")
+	}
+
+	// Sunday can be written as 0 or 7
+	if schedule.dayOfWeek.values[7] {
+		schedule.dayOfWeek.values[0] = true
+	}
+
+	return schedule, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges (1-5) and steps (*/15, 1-30/5)
+func parseCronField(field string, minValue int, maxValue int) (cronField, error) {
+	parsed := cronField{values: make(map[int]bool), restricted: field != "*"}
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepString, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepString)
+			if err != nil || step <= 0 {
+				return parsed, errors.Wrap(InvalidScheduleError, "Invalid step: "+part)
+			}
+		}
+
+		start, end := minValue, maxValue
+		if rangePart != "*" {
+			startString, endString, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = strconv.Atoi(startString); err != nil {
+				return parsed, errors.Wrap(InvalidScheduleError, "Invalid value: "+part)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endString); err != nil {
+					return parsed, errors.Wrap(InvalidScheduleError, "Invalid range: "+part)
+				}
+			} else if hasStep {
+				end = maxValue
+			}
+		}
+		if start < minValue || end > maxValue || start > end {
+			return parsed, errors.Wrap(InvalidScheduleError, "Value out of range: "+part)
+		}
+
+		for value := start; value <= end; value += step {
+			parsed.values[value] = true
+		}
+	}
+
+	return parsed, nil
+}
+
+// Next returns the next time after the given time that matches the interval
+func (schedule intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(schedule.interval)
+}
+
+// Next returns the next time after the given time that matches the cron expression
+func (schedule cronSchedule) Next(after time.Time) time.Time {
+	next := after.Truncate(time.Minute).Add(time.Minute)
+
+	// Every matching time is found within a few years (e.g. the 29th of February)
+	limit := next.AddDate(5, 0, 0)
+	for next.Before(limit) {
+		if !schedule.month.values[int(next.Month())] {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !schedule.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !schedule.hour.values[next.Hour()] {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if !schedule.minute.values[next.Minute()] {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+
+	return time.Time{}
+}
+
+// matchesDay checks the day of month and the day of week like cron does:
+// If both fields are restricted, one of them needs to match.
+func (schedule cronSchedule) matchesDay(t time.Time) bool {
+	dayOfMonth := schedule.dayOfMonth.values[t.Day()]
+	dayOfWeek := schedule.dayOfWeek.values[int(t.Weekday())]
+
+	if schedule.dayOfMonth.restricted && schedule.dayOfWeek.restricted {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
@@ -0,0 +1,365 @@
+package jobScheduler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const (
+	ScheduleTrigger = "schedule"
+	StartupTrigger  = "startup"
+	ManualTrigger   = "manual"
+
+	RunningStatus     = "running"
+	SucceededStatus   = "succeeded"
+	FailedStatus      = "failed"
+	InterruptedStatus = "interrupted"
+)
+
+var (
+	JobNotFoundError       = errors.New("Job not found")
+	JobAlreadyRunningError = errors.New("The job is already running")
+	DuplicateJobError      = errors.New("A job with this name is already registered")
+)
+
+// Job is a task that is executed in the background.
+// Run returns a short human-readable result that is stored with the job run.
+type Job struct {
+	Name         string
+	Description  string
+	Schedule     string // Cron expression, descriptor or interval, empty to only run manually or on startup
+	RunOnStartup bool
+	Run          func(ctx context.Context) (string, error)
+
+	schedule Schedule
+}
+
+var (
+	jobs      = make(map[string]*Job)
+	jobsMutex sync.RWMutex
+)
+
+// Register adds a job to the scheduler. It needs to be called before Start.
+// Throws: DuplicateJobError, InvalidScheduleError
+func Register(job Job) error {
+	if job.Schedule != "" {
+		schedule, err := ParseSchedule(job.Schedule)
+		if err != nil {
+			return errors.Wrap(err, "Failed to parse the schedule of job "+job.Name)
+		}
+		job.schedule = schedule
+	}
+
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	if _, exists := jobs[job.Name]; exists {
+		return errors.Wrap(DuplicateJobError, job.Name)
+	}
+	jobs[job.Name] = &job
+	return nil
+}
+
+// Start marks interrupted runs of previous instances, runs the startup jobs and schedules all jobs until the context is cancelled
+func Start(ctx context.Context) {
+	ctx, span := endpoints.Tracer.Start(ctx, "StartJobScheduler")
+	defer span.End()
+
+	for _, job := range GetJobs() {
+		markInterruptedRuns(ctx, job)
+
+		if job.RunOnStartup {
+			go func() {
+				if _, err := runJob(ctx, job, StartupTrigger); err != nil && !errors.Is(err, JobAlreadyRunningError) {
+					endpoints.Logger.Error(ctx, err)
+				}
+			}()
+		}
+		if job.schedule != nil {
+			go scheduleJob(ctx, job)
+		}
+	}
+
+	endpoints.Logger.Info(ctx, "Job scheduler started")
+}
+
+// GetJobs returns all registered jobs sorted by their name
+func GetJobs() []*Job {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+
+	jobList := make([]*Job, 0, len(jobs))
+	for _, job := range jobs {
+		jobList = append(jobList, job)
+	}
+	sort.Slice(jobList, func(i, j int) bool {
+		return jobList[i].Name < jobList[j].Name
+	})
+	return jobList
+}
+
+// GetJob returns the registered job with the given name
+// Throws: JobNotFoundError
+func GetJob(name string) (*Job, error) {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+
+	job, exists := jobs[name]
+	if !exists {
+		return nil, errors.Wrap(JobNotFoundError, name)
+	}
+	return job, nil
+}
+
+// NextRun returns the next scheduled execution of the job or nil if the job has no schedule
+func (job *Job) NextRun(after time.Time) *time.Time {
+	if job.schedule == nil {
+		return nil
+	}
+	next := job.schedule.Next(after)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// Trigger starts the given job in the background and returns the created job run
+// Throws: JobNotFoundError, JobAlreadyRunningError and other
+func Trigger(ctx context.Context, name string) (*databaseUtils.JobRun, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "TriggerJob")
+	defer span.End()
+
+	job, err1 := GetJob(name)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	// The run needs to outlive the http request
+	runCtx := context.WithoutCancel(ctx)
+
+	started := make(chan error, 1)
+	var run *databaseUtils.JobRun
+	go func() {
+		_, err := runJobWithCallback(runCtx, job, ManualTrigger, func(startedRun *databaseUtils.JobRun, err error) {
+			run = startedRun
+			started <- err
+		})
+		if err != nil && !errors.Is(err, JobAlreadyRunningError) {
+			endpoints.Logger.Error(runCtx, err)
+		}
+	}()
+
+	// Only wait until the run has been started
+	select {
+	case err2 := <-started:
+		if err2 != nil {
+			return nil, err2
+		}
+		return run, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// scheduleJob runs the job at every scheduled time until the context is cancelled
+func scheduleJob(ctx context.Context, job *Job) {
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			endpoints.Logger.Warn(ctx, "Job "+job.Name+" has no upcoming execution")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			_, err := runJob(ctx, job, ScheduleTrigger)
+			if errors.Is(err, JobAlreadyRunningError) {
+				endpoints.Logger.Debug(ctx, "Job "+job.Name+" is already running on another instance, skipping")
+			} else if err != nil {
+				endpoints.Logger.Error(ctx, err)
+			}
+		}
+	}
+}
+
+// runJob executes the job and persists the run in the database
+func runJob(ctx context.Context, job *Job, trigger string) (*databaseUtils.JobRun, error) {
+	return runJobWithCallback(ctx, job, trigger, func(*databaseUtils.JobRun, error) {})
+}
+
+// runJobWithCallback executes the job while holding a postgres advisory lock, so that only one instance
+// of the backend runs the job at the same time. The callback is called as soon as the run was started or failed to start.
+// Throws: JobAlreadyRunningError and other
+func runJobWithCallback(ctx context.Context, job *Job, trigger string, onStarted func(*databaseUtils.JobRun, error)) (*databaseUtils.JobRun, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "RunJob "+job.Name)
+	defer span.End()
+
+	// The transaction holds the advisory lock until it is finished
+	lockTx := DatabaseFlow.GetDB(ctx).WithContext(ctx).Begin()
+	if lockTx.Error != nil {
+		err := errors.Wrap(lockTx.Error, "Failed to begin the lock transaction")
+		onStarted(nil, err)
+		return nil, err
+	}
+	defer lockTx.Rollback()
+
+	locked, err1 := tryAdvisoryLock(lockTx, job.Name)
+	if err1 != nil {
+		onStarted(nil, err1)
+		return nil, err1
+	}
+	if !locked {
+		err := errors.Wrap(JobAlreadyRunningError, job.Name)
+		onStarted(nil, err)
+		return nil, err
+	}
+
+	// Persist the start of the run
+	run := databaseUtils.JobRun{
+		JobName:   job.Name,
+		Trigger:   trigger,
+		Status:    RunningStatus,
+		StartedAt: time.Now(),
+	}
+	err2 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Create(&run).Error
+	})
+	if err2 != nil {
+		err2 = errors.Wrap(err2, "Failed to create the job run")
+		onStarted(nil, err2)
+		return nil, err2
+	}
+	onStarted(&run, nil)
+	endpoints.Logger.Info(ctx, fmt.Sprintf("Job %s started (%s)", job.Name, trigger))
+
+	// Execute the job
+	result, err3 := executeJob(ctx, job)
+
+	// Persist the result of the run
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Result = result
+	run.Status = SucceededStatus
+	if err3 != nil {
+		run.Status = FailedStatus
+		run.Error = err3.Error()
+		endpoints.Logger.Error(ctx, errors.Wrap(err3, "Job "+job.Name+" failed"))
+	} else {
+		endpoints.Logger.Info(ctx, fmt.Sprintf("Job %s finished: %s", job.Name, result))
+	}
+	err4 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.JobRun{}).
+			Where("id = ?", run.ID).
+			Select("status", "finished_at", "result", "error").
+			Updates(&run).
+			Error
+	})
+	if err4 != nil {
+		err4 = errors.Wrap(err4, "Failed to update the job run")
+		return &run, err4
+	}
+
+	return &run, nil
+}
+
+// executeJob runs the job and converts a panic into an error
+func executeJob(ctx context.Context, job *Job) (result string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.New(fmt.Sprintf("Job panicked: %v", recovered))
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+// markInterruptedRuns marks runs of the job as interrupted that are still running, but whose lock is not held anymore
+func markInterruptedRuns(ctx context.Context, job *Job) {
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		locked, err := tryAdvisoryLock(tx, job.Name)
+		if err != nil || !locked {
+			return err
+		}
+
+		return tx.Model(&databaseUtils.JobRun{}).
+			Where("job_name = ? AND status = ?", job.Name, RunningStatus).
+			Update("status", InterruptedStatus).
+			Error
+	})
+	if err1 != nil {
+		endpoints.Logger.Warn(ctx, errors.Wrap(err1, "Failed to mark interrupted runs of job "+job.Name))
+	}
+}
+
+// tryAdvisoryLock tries to acquire the transaction level advisory lock of the job
+func tryAdvisoryLock(tx *gorm.DB, jobName string) (bool, error) {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte("job:" + jobName))
+	lockKey := int64(hash.Sum64())
+
+	var locked bool
+	err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", lockKey).Scan(&locked).Error
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to acquire the advisory lock")
+	}
+	return locked, nil
+}
+
+// GetJobRuns returns the latest runs of the given job, newest first
+func GetJobRuns(ctx context.Context, jobName string, limit int) ([]databaseUtils.JobRun, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetJobRuns")
+	defer span.End()
+
+	var runs []databaseUtils.JobRun
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Model(&databaseUtils.JobRun{}).
+			Where("job_name = ?", jobName).
+			Order("started_at DESC").
+			Limit(limit).
+			Find(&runs).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the job runs")
+		return nil, err1
+	}
+
+	return runs, nil
+}
+
+// GetLastJobRuns returns the latest run of every job
+func GetLastJobRuns(ctx context.Context) (map[string]databaseUtils.JobRun, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "GetLastJobRuns")
+	defer span.End()
+
+	var runs []databaseUtils.JobRun
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		return tx.Raw("SELECT DISTINCT ON (job_name) * FROM job_runs ORDER BY job_name, started_at DESC").
+			Scan(&runs).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the last job runs")
+		return nil, err1
+	}
+
+	lastRuns := make(map[string]databaseUtils.JobRun, len(runs))
+	for _, run := range runs {
+		lastRuns[run.JobName] = run
+	}
+	return lastRuns, nil
+}
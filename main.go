@@ -34,12 +34,21 @@ import (
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/backendSettings"
+	"github.com/Team-Reissdorf/Backend/endpoints/badgeManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/consentManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/disciplineManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/exerciseManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/guardianManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/importManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/jobManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/performanceManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/ping"
+	"github.com/Team-Reissdorf/Backend/endpoints/privacyManagement"
+	"github.com/Team-Reissdorf/Backend/endpoints/retentionManagement"
 	"github.com/Team-Reissdorf/Backend/endpoints/swimCertificate"
 	"github.com/Team-Reissdorf/Backend/endpoints/userManagement"
+	"github.com/Team-Reissdorf/Backend/importJobs"
+	"github.com/Team-Reissdorf/Backend/jobScheduler"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
@@ -81,6 +90,10 @@ func init() {
 		frontendUrl = "http://localhost:8081"
 	}
 
+	// Prepare the existing data for the migration of the models
+	setup.DropLegacyIndexes(ctx)
+	setup.MergeDuplicateAthletes(ctx)
+
 	// Register the models for the database
 	DatabaseFlow.RegisterModels(ctx,
 		databaseUtils.Trainer{},
@@ -92,6 +105,15 @@ func init() {
 		databaseUtils.ExerciseGoal{},
 		databaseUtils.Performance{},
 		databaseUtils.SwimCertificate{},
+		databaseUtils.Guardian{},
+		databaseUtils.Consent{},
+		databaseUtils.HistoricalBadge{},
+		databaseUtils.EarnedBadge{},
+		databaseUtils.RetentionAudit{},
+		databaseUtils.JobRun{},
+		databaseUtils.ImportJob{},
+		databaseUtils.ImportJobRow{},
+		databaseUtils.ImportProfile{},
 	)
 	DatabaseFlow.GetDB(ctx) // Initialize the database connection
 
@@ -122,7 +144,46 @@ func main() {
 	// Create standard disciplines in the database on startup
 	setup.CreateStandardDisciplines(ctx)
 
-	go setup.CreateStandardRulesets(ctx)
+	// Register and start the background jobs
+	registerJobs(ctx)
+	jobScheduler.Start(ctx)
+}
+
+// registerJobs registers all background jobs of the scheduler
+func registerJobs(ctx context.Context) {
+	jobList := []jobScheduler.Job{
+		{
+			Name:         "create_standard_rulesets",
+			Description:  "Writes the standard rulesets of the RULESET_DIR to the database",
+			RunOnStartup: true,
+			Run:          setup.CreateStandardRulesets,
+		},
+		{
+			Name:        retentionManagement.JobName,
+			Description: "Applies the data retention policies",
+			Schedule:    retentionManagement.Schedule,
+			Run:         retentionManagement.RunRetentionJob,
+		},
+		{
+			Name:         "record_earned_badges",
+			Description:  "Records the badges that the athletes have earned with performances",
+			Schedule:     "30 2 * * *",
+			RunOnStartup: true,
+			Run:          badgeManagement.RecordAllEarnedBadges,
+		},
+		{
+			Name:        "mark_stale_imports",
+			Description: "Marks import jobs as failed that have been interrupted",
+			Schedule:    "*/15 * * * *",
+			Run:         importJobs.MarkStaleImportJobs,
+		},
+	}
+
+	for _, job := range jobList {
+		if err := jobScheduler.Register(job); err != nil {
+			logger.Fatal(ctx, err)
+		}
+	}
 }
 
 func defineRoutes(ctx context.Context, router *gin.Engine) {
@@ -139,6 +200,21 @@ func defineRoutes(ctx context.Context, router *gin.Engine) {
 		settings := v1.Group("/backendSettings", authHelper.GetAuthMiddlewareFor(authHelper.SettingsAccessToken))
 		{
 			settings.POST("/change-log-level", backendSettings.ChangeLogLevel) // ToDo: Add auth
+			settings.GET("/cache-stats", backendSettings.GetCacheStats)
+		}
+
+		job := v1.Group("/job", authHelper.GetAuthMiddlewareFor(authHelper.SettingsAccessToken))
+		{
+			job.GET("/get-all", jobManagement.GetJobs)
+			job.GET("/runs/:JobName", jobManagement.GetJobRuns)
+			job.POST("/trigger/:JobName", jobManagement.TriggerJob)
+		}
+
+		retention := v1.Group("/retention", authHelper.GetAuthMiddlewareFor(authHelper.SettingsAccessToken))
+		{
+			retention.GET("/preview", retentionManagement.PreviewRetention)
+			retention.POST("/run", retentionManagement.RunRetention)
+			retention.GET("/audit", retentionManagement.GetRetentionAudit)
 		}
 
 		user := v1.Group("/user")
@@ -154,9 +230,27 @@ func defineRoutes(ctx context.Context, router *gin.Engine) {
 			athlete.POST("/create", athleteManagement.CreateAthlete)
 			athlete.POST("/bulk-create", athleteManagement.CreateAthleteCSV)
 			athlete.GET("/get-all", athleteManagement.GetAllAthletes)
+			athlete.GET("/export", athleteManagement.ExportAthletes)
+			athlete.GET("/get-duplicates", athleteManagement.GetDuplicateAthletes)
 			athlete.GET("/get/:AthleteId", athleteManagement.GetAthleteByID)
 			athlete.PUT("/edit", athleteManagement.EditAthlete)
 			athlete.DELETE("/delete/:AthleteId", athleteManagement.DeleteAthlete)
+			athlete.POST("/merge", athleteManagement.MergeAthletes)
+		}
+
+		guardian := v1.Group("/guardian", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
+		{
+			guardian.POST("/create", guardianManagement.CreateGuardian)
+			guardian.GET("/get/:AthleteId", guardianManagement.GetGuardians)
+			guardian.PUT("/edit", guardianManagement.EditGuardian)
+			guardian.DELETE("/delete/:GuardianId", guardianManagement.DeleteGuardian)
+		}
+
+		consent := v1.Group("/consent", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
+		{
+			consent.POST("/create", consentManagement.CreateConsent)
+			consent.GET("/get/:AthleteId", consentManagement.GetConsents)
+			consent.PUT("/revoke/:ConsentId", consentManagement.RevokeConsent)
 		}
 
 		performance := v1.Group("/performance", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
@@ -164,11 +258,26 @@ func defineRoutes(ctx context.Context, router *gin.Engine) {
 			performance.POST("/create", performanceManagement.CreatePerformance)
 			performance.POST("/export", performanceManagement.ExportPerformances)
 			performance.POST("/bulk-create", performanceManagement.BulkCreatePerformanceEntries)
+			performance.POST("/timing-import", performanceManagement.ImportTimingResults)
+			performance.POST("/dosb-import", performanceManagement.ImportDosbResults)
 			performance.GET("/get-latest/:AthleteId", performanceManagement.GetLatestPerformanceEntry)
 			performance.GET("/get/:AthleteId", performanceManagement.GetPerformanceEntries)
+			performance.GET("/progress/:AthleteId", performanceManagement.GetPerformanceProgress)
+			performance.GET("/missing/:AthleteId", performanceManagement.GetMissingRequirements)
+			performance.POST("/calculate-medal", performanceManagement.CalculateMedal)
 			performance.PUT("/edit", performanceManagement.EditPerformanceEntry)
 		}
 
+		badge := v1.Group("/badge", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
+		{
+			badge.GET("/dashboard", badgeManagement.GetDashboard)
+			badge.GET("/annual-report", badgeManagement.GetAnnualReport)
+			badge.POST("/order", badgeManagement.CreateBadgeOrder)
+			badge.POST("/history/create", badgeManagement.CreateHistoricalBadge)
+			badge.GET("/history/get/:AthleteId", badgeManagement.GetBadgeHistory)
+			badge.DELETE("/history/delete/:BadgeId", badgeManagement.DeleteHistoricalBadge)
+		}
+
 		discipline := v1.Group("/discipline", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
 		{
 			discipline.GET("/get-all", disciplineManagement.GetAllDisciplines)
@@ -185,6 +294,25 @@ func defineRoutes(ctx context.Context, router *gin.Engine) {
 			swimCert.GET("/download-all/:AthleteId", swimCertificate.DownloadAllSwimCertificates)
 		}
 
+		importGroup := v1.Group("/import", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
+		{
+			importGroup.GET("/get-all", importManagement.GetImportJobs)
+			importGroup.GET("/get/:ImportJobId", importManagement.GetImportJob)
+			importGroup.PUT("/cancel/:ImportJobId", importManagement.CancelImportJob)
+			importGroup.POST("/commit/:ImportJobId", importManagement.CommitImportJob)
+
+			importGroup.POST("/profile/create", importManagement.CreateImportProfile)
+			importGroup.GET("/profile/get-all", importManagement.GetImportProfiles)
+			importGroup.PUT("/profile/edit", importManagement.EditImportProfile)
+			importGroup.DELETE("/profile/delete/:ImportProfileId", importManagement.DeleteImportProfile)
+		}
+
+		privacy := v1.Group("/privacy", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
+		{
+			privacy.GET("/export/:AthleteId", privacyManagement.ExportAthleteData)
+			privacy.DELETE("/erase/:AthleteId", privacyManagement.EraseAthleteData)
+		}
+
 		ruleset := v1.Group("/ruleset", authHelper.GetAuthMiddlewareFor(authHelper.AccessToken))
 		{
 			ruleset.POST("/create", rulesetManagement.CreateRuleset)
@@ -0,0 +1,119 @@
+// Package pdfHelper writes simple text documents with tables as PDF files.
+// It only supports the standard fonts Helvetica and Helvetica-Bold with the WinAnsi encoding, which covers the German
+// umlauts, so no fonts have to be embedded.
+package pdfHelper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Size of an A4 page in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Document is a PDF document that is built page by page. The coordinates are given in points from the top left corner.
+type Document struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page, following calls draw on this page
+func (document *Document) AddPage() {
+	document.current = &bytes.Buffer{}
+	document.pages = append(document.pages, document.current)
+}
+
+// Text draws the text with its baseline at the given position
+func (document *Document) Text(x, y, size float64, bold bool, text string) {
+	if document.current == nil {
+		document.AddPage()
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(document.current, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, PageHeight-y, escape(text))
+}
+
+// Line draws a thin line between the given points
+func (document *Document) Line(x1, y1, x2, y2 float64) {
+	if document.current == nil {
+		document.AddPage()
+	}
+	fmt.Fprintf(document.current, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Write writes the document with all pages. A document without pages gets an empty page.
+func (document *Document) Write(writer io.Writer) error {
+	if len(document.pages) == 0 {
+		document.AddPage()
+	}
+
+	var output bytes.Buffer
+	var offsets []int
+	addObject := func(content string) {
+		offsets = append(offsets, output.Len())
+		fmt.Fprintf(&output, "%d 0 obj\n%s\nendobj\n", len(offsets), content)
+	}
+
+	output.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-4 are the catalog, the page tree and the fonts, followed by a page and its content for every page
+	pageIds := make([]string, len(document.pages))
+	for idx := range document.pages {
+		pageIds[idx] = fmt.Sprintf("%d 0 R", 5+2*idx)
+	}
+	addObject("<< /Type /Catalog /Pages 2 0 R >>")
+	addObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageIds, " "), len(document.pages)))
+	addObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	addObject("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for idx, page := range document.pages {
+		addObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", PageWidth, PageHeight, 6+2*idx))
+		addObject(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xrefOffset := output.Len()
+	fmt.Fprintf(&output, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&output, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&output, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	if _, err := output.WriteTo(writer); err != nil {
+		return errors.Wrap(err, "Failed to write the pdf document")
+	}
+	return nil
+}
+
+// escape encodes the text as WinAnsi and escapes the special characters of PDF strings.
+// Characters that cannot be encoded are replaced by a question mark.
+func escape(text string) string {
+	var escaped strings.Builder
+	for _, char := range text {
+		encoded, ok := charmap.Windows1252.EncodeRune(char)
+		if !ok {
+			encoded = '?'
+		}
+		switch encoded {
+		case '\\', '(', ')':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(encoded)
+		default:
+			escaped.WriteByte(encoded)
+		}
+	}
+	return escaped.String()
+}
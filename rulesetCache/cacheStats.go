@@ -0,0 +1,80 @@
+package rulesetCache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// cacheStats counts the accesses of a part of the cache
+type cacheStats struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+var (
+	exerciseStats cacheStats
+	rulesetStats  cacheStats
+	goalStats     cacheStats
+
+	loads         atomic.Uint64
+	invalidations atomic.Uint64
+)
+
+func (stats *cacheStats) hit() {
+	stats.hits.Add(1)
+}
+
+func (stats *cacheStats) miss() {
+	stats.misses.Add(1)
+}
+
+// HitRate contains the accesses of a part of the cache since the start of the server
+type HitRate struct {
+	Hits    uint64  `json:"hits" example:"980"`
+	Misses  uint64  `json:"misses" example:"20"`
+	HitRate float64 `json:"hit_rate" example:"0.98"` // Share of the accesses that were answered from the cache
+}
+
+// Stats contains the metrics of the cache since the start of the server
+type Stats struct {
+	Exercises     HitRate `json:"exercises"`
+	Rulesets      HitRate `json:"rulesets"`
+	Goals         HitRate `json:"goals"` // Lookups by exercise, year, age and sex
+	Loads         uint64  `json:"loads" example:"3"`
+	Invalidations uint64  `json:"invalidations" example:"2"`
+	Loaded        bool    `json:"loaded"`
+	LoadedAt      string  `json:"loaded_at,omitempty" example:"2025-01-31T12:00:00Z"`
+	CachedGoals   int     `json:"cached_goals" example:"150"` // Resolved goal lookups of the current data
+}
+
+// GetStats returns the metrics of the cache
+func GetStats() Stats {
+	stats := Stats{
+		Exercises:     exerciseStats.hitRate(),
+		Rulesets:      rulesetStats.hitRate(),
+		Goals:         goalStats.hitRate(),
+		Loads:         loads.Load(),
+		Invalidations: invalidations.Load(),
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if current != nil && time.Since(current.loadedAt) < maxAge {
+		stats.Loaded = true
+		stats.LoadedAt = current.loadedAt.UTC().Format(time.RFC3339)
+		stats.CachedGoals = len(current.goals)
+	}
+	return stats
+}
+
+// hitRate returns the current counters and the hit rate
+func (stats *cacheStats) hitRate() HitRate {
+	rate := HitRate{
+		Hits:   stats.hits.Load(),
+		Misses: stats.misses.Load(),
+	}
+	if total := rate.Hits + rate.Misses; total > 0 {
+		rate.HitRate = float64(rate.Hits) / float64(total)
+	}
+	return rate
+}
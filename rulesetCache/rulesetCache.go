@@ -0,0 +1,288 @@
+// Package rulesetCache keeps the exercises, the ruleset years and the exercise goals in memory.
+// This data changes about once a year, but is needed for every performance that is created, edited or compared.
+// The cache is loaded on the first access and invalidated whenever rulesets are written. As other instances of the
+// backend do not notice the invalidation, the cache also expires after maxAge.
+package rulesetCache
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// maxAge is the time after which the cache is reloaded, even if it has not been invalidated
+const maxAge = 10 * time.Minute
+
+// GoalKey identifies the exercise goal of an athlete for an exercise in a ruleset year
+type GoalKey struct {
+	ExerciseId uint
+	Year       int
+	Age        int
+	Sex        string
+}
+
+// snapshot is the cached ruleset data at a point in time
+type snapshot struct {
+	loadedAt  time.Time
+	exercises map[uint]databaseUtils.Exercise
+	order     []uint                                           // Exercise ids in ascending order
+	rulesets  map[string]map[uint][]databaseUtils.ExerciseGoal // Year -> exercise id -> goals
+	goals     map[GoalKey]*databaseUtils.ExerciseGoal          // Resolved goals, nil if there is none
+}
+
+var (
+	mutex   sync.RWMutex
+	current *snapshot
+)
+
+// GetExercise returns the exercise with the given id
+// Throws: gorm.ErrRecordNotFound if the exercise does not exist
+func GetExercise(ctx context.Context, exerciseId uint) (databaseUtils.Exercise, error) {
+	data, err1 := getSnapshot(ctx, &exerciseStats)
+	if err1 != nil {
+		return databaseUtils.Exercise{}, err1
+	}
+
+	exercise, exists := data.exercises[exerciseId]
+	if !exists {
+		return databaseUtils.Exercise{}, errors.Wrap(gorm.ErrRecordNotFound, "Exercise "+strconv.FormatUint(uint64(exerciseId), 10)+" not found")
+	}
+	return exercise, nil
+}
+
+// GetExerciseByNameAndDiscipline returns the exercise with the given name of the given discipline
+// Throws: gorm.ErrRecordNotFound if the exercise does not exist
+func GetExerciseByNameAndDiscipline(ctx context.Context, name string, disciplineName string) (databaseUtils.Exercise, error) {
+	data, err1 := getSnapshot(ctx, &exerciseStats)
+	if err1 != nil {
+		return databaseUtils.Exercise{}, err1
+	}
+
+	for _, exerciseId := range data.order {
+		exercise := data.exercises[exerciseId]
+		if exercise.Name == name && exercise.DisciplineName == disciplineName {
+			return exercise, nil
+		}
+	}
+	return databaseUtils.Exercise{}, errors.Wrap(gorm.ErrRecordNotFound, "Exercise "+name+" of "+disciplineName+" not found")
+}
+
+// GetExercises returns all exercises ordered by their id
+func GetExercises(ctx context.Context) ([]databaseUtils.Exercise, error) {
+	data, err1 := getSnapshot(ctx, &exerciseStats)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	exercises := make([]databaseUtils.Exercise, len(data.order))
+	for idx, exerciseId := range data.order {
+		exercises[idx] = data.exercises[exerciseId]
+	}
+	return exercises, nil
+}
+
+// GetRulesetYears returns the years that have a ruleset for at least one exercise in ascending order
+func GetRulesetYears(ctx context.Context) ([]string, error) {
+	data, err1 := getSnapshot(ctx, &rulesetStats)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	years := make([]string, 0, len(data.rulesets))
+	for year := range data.rulesets {
+		years = append(years, year)
+	}
+	sort.Strings(years)
+	return years, nil
+}
+
+// HasRuleset checks if the exercise has a ruleset in the given year
+func HasRuleset(ctx context.Context, exerciseId uint, year int) (bool, error) {
+	data, err1 := getSnapshot(ctx, &rulesetStats)
+	if err1 != nil {
+		return false, err1
+	}
+
+	_, exists := data.rulesets[strconv.Itoa(year)][exerciseId]
+	return exists, nil
+}
+
+// GetExerciseGoals returns all goals of the exercise in the given year ordered by sex and age
+func GetExerciseGoals(ctx context.Context, exerciseId uint, year int) ([]databaseUtils.ExerciseGoal, error) {
+	data, err1 := getSnapshot(ctx, &rulesetStats)
+	if err1 != nil {
+		return nil, err1
+	}
+
+	goals := data.rulesets[strconv.Itoa(year)][exerciseId]
+	return append([]databaseUtils.ExerciseGoal(nil), goals...), nil
+}
+
+// GetExerciseGoal returns the goal of the exercise for an athlete of the given age and sex in the given year
+// Throws: gorm.ErrRecordNotFound if there is no matching goal
+func GetExerciseGoal(ctx context.Context, exerciseId uint, year int, age int, sex string) (databaseUtils.ExerciseGoal, error) {
+	key := GoalKey{ExerciseId: exerciseId, Year: year, Age: age, Sex: sex}
+
+	// Use the resolved goal if possible
+	mutex.RLock()
+	data := current
+	var goal *databaseUtils.ExerciseGoal
+	resolved := false
+	if data != nil && time.Since(data.loadedAt) < maxAge {
+		goal, resolved = data.goals[key]
+	}
+	mutex.RUnlock()
+	if resolved {
+		goalStats.hit()
+		return resolveGoal(goal, key)
+	}
+	goalStats.miss()
+
+	// Resolve the goal from the rulesets
+	data, err1 := getSnapshot(ctx, nil)
+	if err1 != nil {
+		return databaseUtils.ExerciseGoal{}, err1
+	}
+	goal = nil
+	for _, candidate := range data.rulesets[strconv.Itoa(year)][exerciseId] {
+		if candidate.FromAge <= uint(max(age, 0)) && candidate.ToAge >= uint(max(age, 0)) && candidate.Sex == sex {
+			goal = &candidate
+			break
+		}
+	}
+
+	mutex.Lock()
+	data.goals[key] = goal
+	mutex.Unlock()
+
+	return resolveGoal(goal, key)
+}
+
+// resolveGoal returns a copy of the goal or gorm.ErrRecordNotFound if there is none
+func resolveGoal(goal *databaseUtils.ExerciseGoal, key GoalKey) (databaseUtils.ExerciseGoal, error) {
+	if goal == nil {
+		return databaseUtils.ExerciseGoal{}, errors.Wrapf(gorm.ErrRecordNotFound,
+			"No goal for exercise %d in %d for age %d and sex %s", key.ExerciseId, key.Year, key.Age, key.Sex)
+	}
+	return *goal, nil
+}
+
+// Invalidate drops the cached data, so that it is loaded again on the next access.
+// It has to be called after rulesets, exercises or goals have been written.
+func Invalidate(ctx context.Context, reason string) {
+	mutex.Lock()
+	current = nil
+	mutex.Unlock()
+
+	invalidations.Add(1)
+	endpoints.Logger.Debug(ctx, "Ruleset cache invalidated: "+reason)
+}
+
+// getSnapshot returns the cached data and loads it if needed. The access is counted in the given stats.
+func getSnapshot(ctx context.Context, stats *cacheStats) (*snapshot, error) {
+	mutex.RLock()
+	data := current
+	mutex.RUnlock()
+	if data != nil && time.Since(data.loadedAt) < maxAge {
+		if stats != nil {
+			stats.hit()
+		}
+		return data, nil
+	}
+	if stats != nil {
+		stats.miss()
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// Another request might have loaded the data in the meantime
+	if current != nil && time.Since(current.loadedAt) < maxAge {
+		return current, nil
+	}
+
+	data, err1 := load(ctx)
+	if err1 != nil {
+		return nil, err1
+	}
+	current = data
+	loads.Add(1)
+	return data, nil
+}
+
+// load reads the exercises, rulesets and goals from the database
+func load(ctx context.Context) (*snapshot, error) {
+	ctx, span := endpoints.Tracer.Start(ctx, "LoadRulesetCache")
+	defer span.End()
+
+	var exercises []databaseUtils.Exercise
+	var exerciseRulesets []databaseUtils.ExerciseRuleset
+	var goals []databaseUtils.ExerciseGoal
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&databaseUtils.Exercise{}).
+			Order("id").
+			Find(&exercises).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&databaseUtils.ExerciseRuleset{}).
+			Find(&exerciseRulesets).
+			Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&databaseUtils.ExerciseGoal{}).
+			Order("sex, from_age, id").
+			Find(&goals).
+			Error
+		return err
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to load the ruleset cache")
+		return nil, err1
+	}
+
+	data := snapshot{
+		loadedAt:  time.Now(),
+		exercises: make(map[uint]databaseUtils.Exercise, len(exercises)),
+		order:     make([]uint, len(exercises)),
+		rulesets:  make(map[string]map[uint][]databaseUtils.ExerciseGoal),
+		goals:     make(map[GoalKey]*databaseUtils.ExerciseGoal),
+	}
+	for idx, exercise := range exercises {
+		data.exercises[exercise.ID] = exercise
+		data.order[idx] = exercise.ID
+	}
+	exerciseRulesetsById := make(map[uint]databaseUtils.ExerciseRuleset, len(exerciseRulesets))
+	for _, exerciseRuleset := range exerciseRulesets {
+		exerciseRulesetsById[exerciseRuleset.ID] = exerciseRuleset
+		if data.rulesets[exerciseRuleset.RulesetYear] == nil {
+			data.rulesets[exerciseRuleset.RulesetYear] = make(map[uint][]databaseUtils.ExerciseGoal)
+		}
+		if _, exists := data.rulesets[exerciseRuleset.RulesetYear][exerciseRuleset.ExerciseId]; !exists {
+			data.rulesets[exerciseRuleset.RulesetYear][exerciseRuleset.ExerciseId] = []databaseUtils.ExerciseGoal{}
+		}
+	}
+	for _, goal := range goals {
+		exerciseRuleset, exists := exerciseRulesetsById[goal.RulesetId]
+		if !exists {
+			continue
+		}
+		yearRulesets := data.rulesets[exerciseRuleset.RulesetYear]
+		yearRulesets[exerciseRuleset.ExerciseId] = append(yearRulesets[exerciseRuleset.ExerciseId], goal)
+	}
+
+	endpoints.Logger.Debug(ctx, "Ruleset cache loaded with "+strconv.Itoa(len(exercises))+" exercises and "+strconv.Itoa(len(goals))+" goals")
+	return &data, nil
+}
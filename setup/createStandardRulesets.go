@@ -2,7 +2,6 @@ package setup
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
 	"fmt"
 	"os"
@@ -12,61 +11,68 @@ import (
 
 	"github.com/LucaSchmitz2003/DatabaseFlow"
 	"github.com/LucaSchmitz2003/FlowWatch"
+	"github.com/Team-Reissdorf/Backend/csvHelper"
 	"github.com/Team-Reissdorf/Backend/databaseUtils"
 	"github.com/Team-Reissdorf/Backend/endpoints/rulesetManagement"
+	"github.com/Team-Reissdorf/Backend/rulesetCache"
 	"gorm.io/gorm"
 )
 
-func CreateStandardRulesets(ctx context.Context) {
+// CreateStandardRulesets writes all rulesets of the csv files in the RULESET_DIR to the database.
+// It is executed as a job of the scheduler and returns a short summary.
+func CreateStandardRulesets(ctx context.Context) (string, error) {
 
 	// read files in
 	path := os.Getenv("RULESET_DIR")
 	if path == "" {
-		FlowWatch.GetLogHelper().Fatal(ctx, "ruleset dir unset")
+		return "", errors.New("ruleset dir unset")
 	}
 	FlowWatch.GetLogHelper().Info(ctx, "got ruleset dir "+path)
 
 	rulesCSVpath, err := filepath.Glob(path + "*.csv")
 	if err != nil {
-		FlowWatch.GetLogHelper().Fatal(ctx, err)
+		return "", err
 	}
 
+	// The cache is invalidated even if only a part of the rulesets has been written
+	defer rulesetCache.Invalidate(ctx, "standard rulesets")
+
+	rulesetCount := 0
 	for _, f := range rulesCSVpath {
 		file, err := os.Open(f)
 		if err != nil {
-			FlowWatch.GetLogHelper().Fatal(ctx, err)
+			return "", err
 		}
 
 		FlowWatch.GetLogHelper().Info(ctx, "writing ruleset "+file.Name()+" to db")
 
 		rulesets, errRS := read_csv_to_struct(ctx, file)
+		_ = file.Close()
 		if errRS != nil {
-			FlowWatch.GetLogHelper().Fatal(ctx, err)
+			return "", fmt.Errorf("failed to read %s: %w", f, errRS)
 		}
 
 		for _, set := range rulesets {
 			err := write_db(set, ctx)
 			if err != nil {
-				FlowWatch.GetLogHelper().Fatal(ctx, err)
+				return "", err
 			}
 		}
+		rulesetCount += len(rulesets)
 
 	}
 
 	FlowWatch.GetLogHelper().Info(ctx, "done creating default rulesets.")
+	return fmt.Sprintf("%d ruleset entries of %d files written", rulesetCount, len(rulesCSVpath)), nil
 
 }
 
 func read_csv_to_struct(ctx context.Context, file *os.File) ([]rulesetManagement.RulesetBody, error) {
-	reader := csv.NewReader(file)
-	reader.Comma = ';'
-
-	entries, errread := reader.ReadAll()
+	csvFile, errread := csvHelper.Read(file, csvHelper.Options{DetectHeader: true})
 	if errread != nil {
-		FlowWatch.GetLogHelper().Fatal(ctx, errread)
-		// holy cancer
 		return []rulesetManagement.RulesetBody{}, errread
 	}
+	entries := csvFile.Records
 
 	var rulesets []rulesetManagement.RulesetBody
 
@@ -79,29 +85,29 @@ func read_csv_to_struct(ctx context.Context, file *os.File) ([]rulesetManagement
 		// Parse age values
 		FromAge, err := strconv.Atoi(entry[5])
 		if err != nil {
-			FlowWatch.GetLogHelper().Fatal(ctx, err)
+			return []rulesetManagement.RulesetBody{}, err
 		}
 
 		ToAge, err := strconv.Atoi(entry[6])
 
 		if err != nil {
-			FlowWatch.GetLogHelper().Fatal(ctx, err)
+			return []rulesetManagement.RulesetBody{}, err
 		}
 
 		// Parse goal values
 		Bronze, err := strconv.Atoi(entry[7])
 		if err != nil {
-			FlowWatch.GetLogHelper().Fatal(ctx, err)
+			return []rulesetManagement.RulesetBody{}, err
 		}
 
 		Silver, err := strconv.Atoi(entry[8])
 		if err != nil {
-			FlowWatch.GetLogHelper().Fatal(ctx, err)
+			return []rulesetManagement.RulesetBody{}, err
 		}
 
 		Gold, err := strconv.Atoi(entry[9])
 		if err != nil {
-			FlowWatch.GetLogHelper().Fatal(ctx, err)
+			return []rulesetManagement.RulesetBody{}, err
 		}
 
 		rulesets = append(rulesets, rulesetManagement.RulesetBody{
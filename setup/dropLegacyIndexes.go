@@ -0,0 +1,34 @@
+package setup
+
+import (
+	"context"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/pkg/errors"
+)
+
+// legacyAthleteIndex is the old uniqueness index of the athletes (email, birth date and first name),
+// which is replaced by a uniqueness per trainer
+const legacyAthleteIndex = "unique_combination_athletes"
+
+// DropLegacyIndexes removes indexes from the database which are no longer part of the models
+func DropLegacyIndexes(ctx context.Context) {
+	ctx, span := endpoints.Tracer.Start(ctx, "Drop legacy indexes")
+	defer span.End()
+
+	migrator := DatabaseFlow.GetDB(ctx).Migrator()
+	if !migrator.HasIndex(&databaseUtils.Athlete{}, legacyAthleteIndex) {
+		return
+	}
+
+	err1 := migrator.DropIndex(&databaseUtils.Athlete{}, legacyAthleteIndex)
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to drop the legacy athlete index")
+		FlowWatch.GetLogHelper().Error(ctx, err1)
+		return
+	}
+	FlowWatch.GetLogHelper().Info(ctx, "Dropped legacy index "+legacyAthleteIndex)
+}
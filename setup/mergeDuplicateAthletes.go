@@ -0,0 +1,63 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LucaSchmitz2003/DatabaseFlow"
+	"github.com/LucaSchmitz2003/FlowWatch"
+	"github.com/Team-Reissdorf/Backend/databaseUtils"
+	"github.com/Team-Reissdorf/Backend/endpoints"
+	"github.com/Team-Reissdorf/Backend/endpoints/athleteManagement"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// MergeDuplicateAthletes merges the athletes that only differ by their email address into the oldest one, since they would break
+// the uniqueness per trainer of the migrated athlete model. The legacy index allowed them, because the email was required and often invented.
+// It needs to be called before the models are migrated.
+func MergeDuplicateAthletes(ctx context.Context) {
+	ctx, span := endpoints.Tracer.Start(ctx, "Merge duplicate athletes")
+	defer span.End()
+
+	if !DatabaseFlow.GetDB(ctx).Migrator().HasTable(&databaseUtils.Athlete{}) {
+		return
+	}
+
+	var athletes []databaseUtils.Athlete
+	err1 := DatabaseFlow.TransactionHandler(ctx, func(tx *gorm.DB) error {
+		duplicatedCombinations := tx.Model(&databaseUtils.Athlete{}).
+			Select("first_name, last_name, birth_date, trainer_email").
+			Group("first_name, last_name, birth_date, trainer_email").
+			Having("COUNT(*) > 1")
+		return tx.Model(&databaseUtils.Athlete{}).
+			Select("id, first_name, last_name, birth_date, trainer_email").
+			Where("(first_name, last_name, birth_date, trainer_email) IN (?)", duplicatedCombinations).
+			Order("first_name, last_name, birth_date, trainer_email, id").
+			Find(&athletes).
+			Error
+	})
+	if err1 != nil {
+		err1 = errors.Wrap(err1, "Failed to get the duplicate athletes")
+		FlowWatch.GetLogHelper().Error(ctx, err1)
+		return
+	}
+
+	// The athletes are ordered by their combination, so the first athlete of a combination survives
+	var surviving databaseUtils.Athlete
+	for _, athlete := range athletes {
+		if surviving.ID == 0 || athlete.FirstName != surviving.FirstName || athlete.LastName != surviving.LastName ||
+			athlete.BirthDate != surviving.BirthDate || athlete.TrainerEmail != surviving.TrainerEmail {
+			surviving = athlete
+			continue
+		}
+
+		err2 := athleteManagement.MergeDuplicateAthlete(ctx, surviving.ID, athlete.ID, athlete.TrainerEmail)
+		if err2 != nil {
+			err2 = errors.Wrap(err2, fmt.Sprintf("Failed to merge the duplicate athlete %d into %d", athlete.ID, surviving.ID))
+			FlowWatch.GetLogHelper().Error(ctx, err2)
+			continue
+		}
+		FlowWatch.GetLogHelper().Info(ctx, fmt.Sprintf("Merged the duplicate athlete %d into %d", athlete.ID, surviving.ID))
+	}
+}
@@ -0,0 +1,155 @@
+package timingHelper
+
+import (
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Team-Reissdorf/Backend/csvHelper"
+	"github.com/pkg/errors"
+)
+
+const (
+	LifFormat = "lif" // Results of a single heat
+	EvtFormat = "evt" // Events with their entries, optionally with times
+)
+
+var (
+	UnsupportedFileTypeError = errors.New("Invalid file type, only .lif and .evt files are allowed")
+	InvalidResultFileError   = errors.New("The file is not a valid result file")
+	NoTimeError              = errors.New("The result has no valid time")
+	InvalidTimeError         = errors.New("Invalid time format")
+
+	// noTimeStatuses are the statuses the timing system writes instead of a place or a time
+	noTimeStatuses = []string{"", "DNF", "DNS", "DQ", "DSQ", "NT", "NP", "SCR", "DNC"}
+)
+
+// Event is a heat of the timing system with its results
+type Event struct {
+	Number  string
+	Round   string
+	Heat    string
+	Name    string
+	Wind    string
+	Results []Result
+}
+
+// Result is a line of an event. The Id is the competitor number (usually the bib number).
+type Result struct {
+	Place       string
+	Id          string
+	Lane        string
+	LastName    string
+	FirstName   string
+	Affiliation string
+	Time        string
+}
+
+// FormatOf returns the format of the result file by its extension
+// Throws: UnsupportedFileTypeError
+func FormatOf(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".lif":
+		return LifFormat, nil
+	case ".evt":
+		return EvtFormat, nil
+	default:
+		return "", UnsupportedFileTypeError
+	}
+}
+
+// Read parses a comma separated FinishLynx result file.
+// A .lif file starts with the event line (event, round, heat, name, wind) followed by the results
+// (place, id, lane, last name, first name, affiliation, time). A .evt file contains several event lines,
+// each followed by its entries, which start with an empty field instead of the place.
+// Throws: InvalidResultFileError, csvHelper.EmptyFileError and other
+func Read(reader io.Reader, format string) ([]Event, error) {
+	csvFile, err1 := csvHelper.Read(reader, csvHelper.Options{Delimiter: ','})
+	if err1 != nil {
+		return nil, err1
+	}
+	records := csvFile.Records
+
+	var events []Event
+	for idx, record := range records {
+		isEventLine := idx == 0 || (format == EvtFormat && field(record, 0) != "")
+		if isEventLine {
+			if field(record, 0) == "" {
+				return nil, errors.Wrap(InvalidResultFileError, "The file does not start with an event line")
+			}
+			events = append(events, Event{
+				Number: field(record, 0),
+				Round:  field(record, 1),
+				Heat:   field(record, 2),
+				Name:   field(record, 3),
+				Wind:   field(record, 4),
+			})
+			continue
+		}
+
+		if len(record) < 5 {
+			return nil, errors.Wrapf(InvalidResultFileError, "Line %d has too few columns", idx+1)
+		}
+		event := &events[len(events)-1]
+		event.Results = append(event.Results, Result{
+			Place:       field(record, 0),
+			Id:          field(record, 1),
+			Lane:        field(record, 2),
+			LastName:    field(record, 3),
+			FirstName:   field(record, 4),
+			Affiliation: field(record, 5),
+			Time:        field(record, 6),
+		})
+	}
+
+	return events, nil
+}
+
+// ParseTime converts a time of the timing system (e.g. 12.34, 1:05.2 or 1:02:03.456) to a duration.
+// A status like DNF or DNS instead of a time returns the NoTimeError.
+// Throws: NoTimeError, InvalidTimeError
+func ParseTime(value string) (time.Duration, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	for _, status := range noTimeStatuses {
+		if value == status {
+			return 0, errors.Wrap(NoTimeError, value)
+		}
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, errors.Wrap(InvalidTimeError, value)
+	}
+
+	seconds, err1 := strconv.ParseFloat(strings.Replace(parts[len(parts)-1], ",", ".", 1), 64)
+	if err1 != nil || seconds < 0 || (len(parts) > 1 && seconds >= 60) {
+		return 0, errors.Wrap(InvalidTimeError, value)
+	}
+
+	total := time.Duration(math.Round(seconds*1000)) * time.Millisecond
+	units := []time.Duration{time.Minute, time.Hour}
+	for idx := len(parts) - 2; idx >= 0; idx-- {
+		amount, err2 := strconv.Atoi(parts[idx])
+		unitIdx := len(parts) - 2 - idx
+		if err2 != nil || amount < 0 || (idx > 0 && amount >= 60) {
+			return 0, errors.Wrap(InvalidTimeError, value)
+		}
+		total += time.Duration(amount) * units[unitIdx]
+	}
+
+	if total <= 0 {
+		return 0, errors.Wrap(InvalidTimeError, value)
+	}
+	return total, nil
+}
+
+// field returns the trimmed value of the column or an empty string if the record is too short
+func field(record []string, idx int) string {
+	if idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
@@ -0,0 +1,345 @@
+package xlsxHelper
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxPartSize limits the uncompressed size of a single part of the workbook to protect against zip bombs
+const maxPartSize = 100 << 20
+
+var (
+	InvalidWorkbookError = errors.New("The file is not a valid xlsx workbook")
+	SheetNotFoundError   = errors.New("Sheet not found in the workbook")
+)
+
+type xmlWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		Id   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xmlRelationships struct {
+	Relationships []struct {
+		Id     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xmlRichText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (text xmlRichText) String() string {
+	if len(text.R) == 0 {
+		return text.T
+	}
+	var builder strings.Builder
+	for _, run := range text.R {
+		builder.WriteString(run.T)
+	}
+	return builder.String()
+}
+
+type xmlSharedStrings struct {
+	Items []xmlRichText `xml:"si"`
+}
+
+type xmlStyles struct {
+	NumFmts []struct {
+		Id         int    `xml:"numFmtId,attr"`
+		FormatCode string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtId int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xmlSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Style  int          `xml:"s,attr"`
+			Value  string       `xml:"v"`
+			Inline *xmlRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// numberFormatKind describes how numeric cells with a number format are converted to text
+type numberFormatKind int
+
+const (
+	numberFormat numberFormatKind = iota
+	dateFormat
+	timeFormat
+)
+
+// ReadSheet returns the rows of the sheet with the given name, or of the first sheet if the name is empty.
+// Dates are returned as YYYY-MM-DD and times as M:SS (or H:MM:SS) with the fraction of a second if there is one, empty rows are skipped.
+// Throws: InvalidWorkbookError, SheetNotFoundError
+func ReadSheet(reader io.ReaderAt, size int64, sheetName string) ([][]string, error) {
+	zipReader, err1 := zip.NewReader(reader, size)
+	if err1 != nil {
+		return nil, errors.Wrap(InvalidWorkbookError, err1.Error())
+	}
+	parts := make(map[string]*zip.File, len(zipReader.File))
+	for _, file := range zipReader.File {
+		parts[strings.TrimPrefix(file.Name, "/")] = file
+	}
+
+	sheetPath, err2 := findSheet(parts, sheetName)
+	if err2 != nil {
+		return nil, err2
+	}
+
+	// Shared strings and styles are optional
+	var sharedStrings xmlSharedStrings
+	if err := decodePart(parts, "xl/sharedStrings.xml", &sharedStrings, true); err != nil {
+		return nil, err
+	}
+	var styles xmlStyles
+	if err := decodePart(parts, "xl/styles.xml", &styles, true); err != nil {
+		return nil, err
+	}
+	formatKinds := getFormatKinds(styles)
+
+	var sheet xmlSheet
+	if err := decodePart(parts, sheetPath, &sheet, false); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for _, row := range sheet.Rows {
+		var record []string
+		empty := true
+		for idx, cell := range row.Cells {
+			column := idx
+			if cell.Ref != "" {
+				column = columnIndex(cell.Ref)
+			}
+			if column < len(record) {
+				continue
+			}
+			for len(record) < column {
+				record = append(record, "")
+			}
+
+			value := cellText(cell.Type, cell.Value, cell.Inline, cell.Style, sharedStrings, formatKinds)
+			if strings.TrimSpace(value) != "" {
+				empty = false
+			}
+			record = append(record, value)
+		}
+		if !empty {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// findSheet returns the path of the sheet with the given name or of the first sheet
+func findSheet(parts map[string]*zip.File, sheetName string) (string, error) {
+	var workbook xmlWorkbook
+	if err := decodePart(parts, "xl/workbook.xml", &workbook, false); err != nil {
+		return "", err
+	}
+	var relationships xmlRelationships
+	if err := decodePart(parts, "xl/_rels/workbook.xml.rels", &relationships, false); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.Wrap(InvalidWorkbookError, "The workbook has no sheets")
+	}
+
+	sheetId := workbook.Sheets[0].Id
+	if sheetName != "" {
+		sheetId = ""
+		for _, sheet := range workbook.Sheets {
+			if strings.EqualFold(strings.TrimSpace(sheet.Name), strings.TrimSpace(sheetName)) {
+				sheetId = sheet.Id
+				break
+			}
+		}
+		if sheetId == "" {
+			return "", errors.Wrap(SheetNotFoundError, sheetName)
+		}
+	}
+
+	for _, relationship := range relationships.Relationships {
+		if relationship.Id != sheetId {
+			continue
+		}
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+	return "", errors.Wrap(InvalidWorkbookError, "The sheet is not referenced in the workbook")
+}
+
+// decodePart unmarshals the xml part with the given path
+func decodePart(parts map[string]*zip.File, partPath string, target interface{}, optional bool) error {
+	file, exists := parts[partPath]
+	if !exists {
+		if optional {
+			return nil
+		}
+		return errors.Wrap(InvalidWorkbookError, "Missing part "+partPath)
+	}
+
+	content, err1 := file.Open()
+	if err1 != nil {
+		return errors.Wrap(InvalidWorkbookError, err1.Error())
+	}
+	defer content.Close()
+
+	if err2 := xml.NewDecoder(io.LimitReader(content, maxPartSize)).Decode(target); err2 != nil {
+		return errors.Wrap(InvalidWorkbookError, err2.Error())
+	}
+	return nil
+}
+
+// getFormatKinds returns the kind of number format of every cell style
+func getFormatKinds(styles xmlStyles) []numberFormatKind {
+	customFormats := make(map[int]string, len(styles.NumFmts))
+	for _, numFmt := range styles.NumFmts {
+		customFormats[numFmt.Id] = numFmt.FormatCode
+	}
+
+	kinds := make([]numberFormatKind, len(styles.CellXfs))
+	for idx, xf := range styles.CellXfs {
+		switch {
+		case (xf.NumFmtId >= 14 && xf.NumFmtId <= 17) || xf.NumFmtId == 22:
+			kinds[idx] = dateFormat
+		case (xf.NumFmtId >= 18 && xf.NumFmtId <= 21) || (xf.NumFmtId >= 45 && xf.NumFmtId <= 47):
+			kinds[idx] = timeFormat
+		default:
+			if formatCode, exists := customFormats[xf.NumFmtId]; exists {
+				kinds[idx] = getCustomFormatKind(formatCode)
+			}
+		}
+	}
+	return kinds
+}
+
+// getCustomFormatKind checks if a custom format code formats dates or times
+func getCustomFormatKind(formatCode string) numberFormatKind {
+	// Remove quoted text and escaped characters, which are printed literally
+	var builder strings.Builder
+	quoted, escaped := false, false
+	for _, char := range strings.ToLower(formatCode) {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '"':
+			quoted = !quoted
+		case !quoted:
+			builder.WriteRune(char)
+		}
+	}
+	code := builder.String()
+
+	if strings.ContainsAny(code, "dy") {
+		return dateFormat
+	}
+	if strings.ContainsAny(code, "hs") {
+		return timeFormat
+	}
+	return numberFormat
+}
+
+// cellText converts the value of a cell to text
+func cellText(cellType string, value string, inline *xmlRichText, style int, sharedStrings xmlSharedStrings, formatKinds []numberFormatKind) string {
+	switch cellType {
+	case "s":
+		idx, err := strconv.Atoi(value)
+		if err != nil || idx < 0 || idx >= len(sharedStrings.Items) {
+			return ""
+		}
+		return sharedStrings.Items[idx].String()
+	case "inlineStr":
+		if inline == nil {
+			return ""
+		}
+		return inline.String()
+	case "b":
+		if value == "1" {
+			return "true"
+		}
+		return "false"
+	case "str", "e":
+		return value
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	kind := numberFormat
+	if style >= 0 && style < len(formatKinds) {
+		kind = formatKinds[style]
+	}
+
+	switch kind {
+	case dateFormat:
+		return serialToTime(number).Format("2006-01-02")
+	case timeFormat:
+		// Keep the milliseconds, since sprint times are measured in hundredths
+		totalMilliseconds := int(math.Round(number * 24 * 60 * 60 * 1000))
+		totalSeconds, milliseconds := totalMilliseconds/1000, totalMilliseconds%1000
+		hours, minutes, seconds := totalSeconds/3600, totalSeconds%3600/60, totalSeconds%60
+		text := strconv.Itoa(minutes) + ":" + twoDigits(seconds)
+		if hours > 0 {
+			text = strconv.Itoa(hours) + ":" + twoDigits(minutes) + ":" + twoDigits(seconds)
+		}
+		if milliseconds > 0 {
+			text += "." + strings.TrimRight(strconv.Itoa(1000 + milliseconds)[1:], "0")
+		}
+		return text
+	default:
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+}
+
+// columnIndex converts the column letters of a cell reference (e.g. AB12) to a 0-based index
+func columnIndex(ref string) int {
+	index := 0
+	for _, char := range ref {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		index = index*26 + int(char-'A'+1)
+	}
+	return index - 1
+}
+
+// serialToTime converts an excel serial date to a time
+func serialToTime(serial float64) time.Time {
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	return excelEpoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+}
+
+func twoDigits(value int) string {
+	if value < 10 {
+		return "0" + strconv.Itoa(value)
+	}
+	return strconv.Itoa(value)
+}
@@ -0,0 +1,311 @@
+package xlsxHelper
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	maxSheetNameLength = 31
+	maxColumnWidth     = 60
+)
+
+// excelEpoch is the day before the serial date 1, shifted by the leap year bug of 1900
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+type CellType int
+
+const (
+	StringCell   CellType = iota
+	NumberCell            // Integer or decimal number without fixed decimals
+	DecimalCell           // Number with two decimals
+	DateCell              // Formatted as DD.MM.YYYY
+	DurationCell          // Formatted as M:SS.00
+)
+
+// Style indices of the cellXfs in the styles part
+const (
+	defaultStyle = iota
+	headerStyle
+	dateStyle
+	durationStyle
+	decimalStyle
+)
+
+// Cell is a typed cell of a worksheet
+type Cell struct {
+	Type     CellType
+	Text     string
+	Number   float64
+	Date     time.Time
+	Duration time.Duration
+}
+
+func String(text string) Cell {
+	return Cell{Type: StringCell, Text: text}
+}
+
+func Number(number float64) Cell {
+	return Cell{Type: NumberCell, Number: number}
+}
+
+func Decimal(number float64) Cell {
+	return Cell{Type: DecimalCell, Number: number}
+}
+
+func Date(date time.Time) Cell {
+	return Cell{Type: DateCell, Date: date}
+}
+
+func Duration(duration time.Duration) Cell {
+	return Cell{Type: DurationCell, Duration: duration}
+}
+
+// Write writes a workbook with a single sheet. The header row is bold, frozen and has an auto filter.
+// The columns are sized by their longest value.
+func Write(writer io.Writer, sheetName string, header []string, rows [][]Cell) error {
+	widths := make([]int, len(header))
+	for idx, name := range header {
+		widths[idx] = utf8.RuneCountInString(name) + 2
+	}
+	for _, row := range rows {
+		for idx, cell := range row {
+			if width := cellWidth(cell) + 2; idx < len(widths) && width > widths[idx] {
+				widths[idx] = min(width, maxColumnWidth)
+			}
+		}
+	}
+
+	streamWriter, err1 := NewStreamWriter(writer, sheetName, header, widths)
+	if err1 != nil {
+		return err1
+	}
+	for _, row := range rows {
+		if err2 := streamWriter.WriteRow(row); err2 != nil {
+			return err2
+		}
+	}
+	return streamWriter.Close()
+}
+
+// StreamWriter writes a workbook with a single sheet row by row without keeping the rows in memory.
+// As the columns are declared before the rows, their widths have to be known in advance.
+type StreamWriter struct {
+	zipWriter   *zip.Writer
+	sheetWriter *bufio.Writer
+	columns     int
+	rowNumber   int
+}
+
+// NewStreamWriter writes the parts of the workbook and the header row.
+// The widths are the widths of the columns in characters, missing ones are sized by the header.
+func NewStreamWriter(writer io.Writer, sheetName string, header []string, widths []int) (*StreamWriter, error) {
+	zipWriter := zip.NewWriter(writer)
+
+	// The worksheet is the last part, so that it can be streamed
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXml},
+		{"_rels/.rels", rootRelsXml},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXml, escape(sanitizeSheetName(sheetName)))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXml},
+		{"xl/styles.xml", stylesXml},
+	}
+	for _, part := range parts {
+		partWriter, err1 := zipWriter.Create(part.name)
+		if err1 != nil {
+			return nil, errors.Wrap(err1, "Failed to create "+part.name)
+		}
+		if _, err2 := io.WriteString(partWriter, part.content); err2 != nil {
+			return nil, errors.Wrap(err2, "Failed to write "+part.name)
+		}
+	}
+
+	partWriter, err3 := zipWriter.Create("xl/worksheets/sheet1.xml")
+	if err3 != nil {
+		return nil, errors.Wrap(err3, "Failed to create xl/worksheets/sheet1.xml")
+	}
+	sheetWriter := bufio.NewWriter(partWriter)
+
+	sheetWriter.WriteString(xml.Header)
+	sheetWriter.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sheetWriter.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if len(header) > 0 {
+		sheetWriter.WriteString(`<cols>`)
+		for idx, name := range header {
+			width := utf8.RuneCountInString(name) + 2
+			if idx < len(widths) && widths[idx] > 0 {
+				width = min(widths[idx], maxColumnWidth)
+			}
+			fmt.Fprintf(sheetWriter, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, idx+1, idx+1, width)
+		}
+		sheetWriter.WriteString(`</cols>`)
+	}
+	sheetWriter.WriteString(`<sheetData>`)
+
+	sheetWriter.WriteString(`<row r="1">`)
+	for idx, name := range header {
+		fmt.Fprintf(sheetWriter, `<c r="%s1" t="inlineStr" s="%d"><is><t>%s</t></is></c>`, columnName(idx), headerStyle, escape(name))
+	}
+	sheetWriter.WriteString(`</row>`)
+
+	return &StreamWriter{
+		zipWriter:   zipWriter,
+		sheetWriter: sheetWriter,
+		columns:     len(header),
+		rowNumber:   1,
+	}, nil
+}
+
+// WriteRow appends a row to the sheet
+func (streamWriter *StreamWriter) WriteRow(row []Cell) error {
+	streamWriter.rowNumber++
+	sheetWriter := streamWriter.sheetWriter
+
+	fmt.Fprintf(sheetWriter, `<row r="%d">`, streamWriter.rowNumber)
+	for idx, cell := range row {
+		ref := fmt.Sprintf("%s%d", columnName(idx), streamWriter.rowNumber)
+		switch cell.Type {
+		case NumberCell:
+			fmt.Fprintf(sheetWriter, `<c r="%s"><v>%v</v></c>`, ref, cell.Number)
+		case DecimalCell:
+			fmt.Fprintf(sheetWriter, `<c r="%s" s="%d"><v>%v</v></c>`, ref, decimalStyle, cell.Number)
+		case DateCell:
+			fmt.Fprintf(sheetWriter, `<c r="%s" s="%d"><v>%v</v></c>`, ref, dateStyle, timeToSerial(cell.Date))
+		case DurationCell:
+			fmt.Fprintf(sheetWriter, `<c r="%s" s="%d"><v>%v</v></c>`, ref, durationStyle, cell.Duration.Seconds()/(24*60*60))
+		default:
+			fmt.Fprintf(sheetWriter, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escape(cell.Text))
+		}
+	}
+	_, err := sheetWriter.WriteString(`</row>`)
+	if err != nil {
+		return errors.Wrap(err, "Failed to write the row")
+	}
+	return nil
+}
+
+// Close ends the sheet and writes the central directory of the workbook
+func (streamWriter *StreamWriter) Close() error {
+	sheetWriter := streamWriter.sheetWriter
+
+	sheetWriter.WriteString(`</sheetData>`)
+	if streamWriter.columns > 0 {
+		fmt.Fprintf(sheetWriter, `<autoFilter ref="A1:%s%d"/>`, columnName(streamWriter.columns-1), streamWriter.rowNumber)
+	}
+	sheetWriter.WriteString(`</worksheet>`)
+	if err1 := sheetWriter.Flush(); err1 != nil {
+		return errors.Wrap(err1, "Failed to write xl/worksheets/sheet1.xml")
+	}
+
+	if err2 := streamWriter.zipWriter.Close(); err2 != nil {
+		return errors.Wrap(err2, "Failed to close the workbook")
+	}
+	return nil
+}
+
+// cellWidth returns the number of characters of the displayed cell value
+func cellWidth(cell Cell) int {
+	switch cell.Type {
+	case NumberCell:
+		return len(fmt.Sprintf("%v", cell.Number))
+	case DecimalCell:
+		return len(fmt.Sprintf("%.2f", cell.Number))
+	case DateCell:
+		return len("DD.MM.YYYY")
+	case DurationCell:
+		return len("MM:SS.00")
+	default:
+		return utf8.RuneCountInString(cell.Text)
+	}
+}
+
+// columnName converts a 0-based column index to its letters (e.g. 27 -> AB)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// timeToSerial converts the date to an excel serial date
+func timeToSerial(date time.Time) float64 {
+	date = time.Date(date.Year(), date.Month(), date.Day(), date.Hour(), date.Minute(), date.Second(), 0, time.UTC)
+	return date.Sub(excelEpoch).Hours() / 24
+}
+
+// sanitizeSheetName removes the characters excel does not allow in sheet names
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(char rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, char) {
+			return -1
+		}
+		return char
+	}, name)
+	if name == "" {
+		name = "Sheet1"
+	}
+	if utf8.RuneCountInString(name) > maxSheetNameLength {
+		name = string([]rune(name)[:maxSheetNameLength])
+	}
+	return name
+}
+
+func escape(text string) string {
+	var buffer bytes.Buffer
+	_ = xml.EscapeText(&buffer, []byte(text))
+	return buffer.String()
+}
+
+const contentTypesXml = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const rootRelsXml = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookXml = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const workbookRelsXml = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// stylesXml defines the cell styles in the order of the style constants
+const stylesXml = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="dd.mm.yyyy"/><numFmt numFmtId="165" formatCode="[m]:ss.00"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`